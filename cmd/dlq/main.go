@@ -0,0 +1,101 @@
+// Command dlq lists, inspects and re-drives messages in Kafka dead-letter topics.
+//
+//	dlq list    -topic score_events [-partition 0] [-offset 0] [-limit 20]
+//	dlq inspect -topic score_events -partition 0 -offset 42
+//	dlq redrive -topic score_events -partition 0 -offset 42
+//
+// Brokers are read from KAFKA_BROKERS (default localhost:9092) or -brokers.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/emorenkov/scorehub/pkg/common/dlq"
+	"github.com/emorenkov/scorehub/pkg/common/models"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	if err := run(os.Args[1], os.Args[2:]); err != nil {
+		fmt.Fprintln(os.Stderr, "dlq:", err)
+		os.Exit(1)
+	}
+}
+
+func run(cmd string, args []string) error {
+	fs := flag.NewFlagSet(cmd, flag.ExitOnError)
+	brokers := fs.String("brokers", models.GetEnv("KAFKA_BROKERS", "localhost:9092"), "comma-separated Kafka brokers")
+	topic := fs.String("topic", "", "source topic (its dead-letter topic is <topic>.dlq)")
+	partition := fs.Int("partition", 0, "dead-letter topic partition")
+	offset := fs.Int64("offset", 0, "dead-letter topic offset")
+	limit := fs.Int("limit", 20, "maximum messages to list")
+	timeout := fs.Duration("timeout", 30*time.Second, "overall timeout")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *topic == "" {
+		return errors.New("-topic is required")
+	}
+
+	admin := dlq.NewAdmin(splitBrokers(*brokers))
+	defer admin.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	switch cmd {
+	case "list":
+		page, err := admin.List(ctx, *topic, *partition, *offset, *limit)
+		if err != nil {
+			return err
+		}
+		return printJSON(page)
+	case "inspect":
+		msg, err := admin.Get(ctx, *topic, *partition, *offset)
+		if err != nil {
+			return err
+		}
+		return printJSON(msg)
+	case "redrive":
+		msg, err := admin.Redrive(ctx, *topic, *partition, *offset)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "re-drove %s/%d/%d to %s\n", dlq.Topic(*topic), *partition, *offset, msg.OriginalTopic)
+		return nil
+	default:
+		usage()
+		return fmt.Errorf("unknown command %q", cmd)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: dlq <list|inspect|redrive> -topic <topic> [-partition n] [-offset n] [-limit n] [-brokers host:port]")
+}
+
+func printJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func splitBrokers(s string) []string {
+	parts := strings.Split(s, ",")
+	res := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if v := strings.TrimSpace(p); v != "" {
+			res = append(res, v)
+		}
+	}
+	return res
+}
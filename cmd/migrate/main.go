@@ -0,0 +1,127 @@
+// Command migrate applies and reverts the schema migrations embedded in
+// pkg/common/db/migrate.
+//
+//	migrate up     [-steps n]   apply pending migrations (all by default)
+//	migrate down   [-steps n]   revert the latest migrations (one by default)
+//	migrate status              list migrations and when they were applied
+//	migrate create -name add_x  write empty up/down files in -dir
+//
+// The database is read from POSTGRES_* like the services.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/emorenkov/scorehub/pkg/common/db"
+	"github.com/emorenkov/scorehub/pkg/common/db/migrate"
+	"github.com/emorenkov/scorehub/pkg/common/logger"
+	"github.com/emorenkov/scorehub/pkg/common/models"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	if err := run(os.Args[1], os.Args[2:]); err != nil {
+		fmt.Fprintln(os.Stderr, "migrate:", err)
+		os.Exit(1)
+	}
+}
+
+func run(cmd string, args []string) error {
+	fs := flag.NewFlagSet(cmd, flag.ExitOnError)
+	steps := fs.Int("steps", 0, "number of migrations to apply or revert")
+	name := fs.String("name", "", "name of the migration to create")
+	dir := fs.String("dir", "pkg/common/db/migrate/sql", "migrations directory, for create")
+	timeout := fs.Duration("timeout", 5*time.Minute, "overall timeout")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if cmd == "create" {
+		if *name == "" {
+			return errors.New("-name is required")
+		}
+		up, down, err := migrate.Create(*dir, *name)
+		if err != nil {
+			return err
+		}
+		fmt.Println(up)
+		fmt.Println(down)
+		return nil
+	}
+
+	if err := logger.Init("migrate"); err != nil {
+		return err
+	}
+	defer logger.Sync()
+
+	cfg := models.LoadPostgresConfig()
+	cfg.AutoMigrate = false
+	gormDB, err := db.NewPostgresDB(cfg)
+	if err != nil {
+		return err
+	}
+	sqlDB, err := gormDB.DB()
+	if err != nil {
+		return err
+	}
+	defer sqlDB.Close()
+
+	m, err := migrate.New(sqlDB)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	switch cmd {
+	case "up":
+		done, err := m.Up(ctx, *steps)
+		printMigrations("applied", done)
+		return err
+	case "down":
+		done, err := m.Down(ctx, *steps)
+		printMigrations("reverted", done)
+		return err
+	case "status":
+		statuses, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, s := range statuses {
+			applied := "pending"
+			if s.AppliedAt != nil {
+				applied = s.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%04d\t%s\t%s\n", s.Version, s.Name, applied)
+		}
+		return w.Flush()
+	default:
+		usage()
+		return fmt.Errorf("unknown command %q", cmd)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: migrate <up|down|status|create> [-steps n] [-name name] [-dir path]")
+}
+
+func printMigrations(verb string, migrations []migrate.Migration) {
+	if len(migrations) == 0 {
+		fmt.Printf("nothing %s\n", verb)
+		return
+	}
+	for _, m := range migrations {
+		fmt.Printf("%s %04d_%s\n", verb, m.Version, m.Name)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	logpkg "github.com/emorenkov/scorehub/pkg/common/logger"
+	"github.com/emorenkov/scorehub/pkg/common/tracing"
 	"github.com/emorenkov/scorehub/pkg/notification/app"
 	notifcfg "github.com/emorenkov/scorehub/pkg/notification/config"
 	"go.uber.org/zap"
@@ -22,6 +23,11 @@ func main() {
 	}
 	defer logpkg.Sync()
 
+	shutdownTracing, err := tracing.Init(context.Background(), cfg.ServiceName, cfg.Tracing)
+	if err != nil {
+		logpkg.Log.Fatal("failed to init tracing", zap.Error(err))
+	}
+
 	application, err := app.New(cfg)
 	if err != nil {
 		logpkg.Log.Fatal("failed to init app", zap.Error(err))
@@ -48,4 +54,7 @@ func main() {
 	if err := application.Shutdown(ctx); err != nil {
 		logpkg.Log.Error("graceful shutdown failed", zap.Error(err))
 	}
+	if err := shutdownTracing(ctx); err != nil {
+		logpkg.Log.Error("tracing shutdown failed", zap.Error(err))
+	}
 }
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/emorenkov/scorehub/pkg/common/logger"
+	"github.com/emorenkov/scorehub/pkg/common/tracing"
 	"github.com/emorenkov/scorehub/pkg/user/app"
 	"github.com/emorenkov/scorehub/pkg/user/config"
 	"go.uber.org/zap"
@@ -22,6 +23,11 @@ func main() {
 	}
 	defer logger.Sync()
 
+	shutdownTracing, err := tracing.Init(context.Background(), cfg.ServiceName, cfg.Tracing)
+	if err != nil {
+		logger.Log.Fatal("failed to init tracing", zap.Error(err))
+	}
+
 	application, err := app.New(cfg)
 	if err != nil {
 		logger.Log.Fatal("failed to init app", zap.Error(err))
@@ -46,4 +52,7 @@ func main() {
 	if err := application.Shutdown(ctx); err != nil {
 		logger.Log.Error("graceful shutdown failed", zap.Error(err))
 	}
+	if err := shutdownTracing(ctx); err != nil {
+		logger.Log.Error("tracing shutdown failed", zap.Error(err))
+	}
 }
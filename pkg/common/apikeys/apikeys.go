@@ -0,0 +1,230 @@
+// Package apikeys manages database-backed API keys for service accounts: creation,
+// revocation, rotation and cached lookup for authentication.
+package apikeys
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/emorenkov/scorehub/pkg/common/auth"
+	apperrors "github.com/emorenkov/scorehub/pkg/common/errors"
+	"github.com/emorenkov/scorehub/pkg/common/models"
+	"gorm.io/gorm"
+)
+
+// Keys look like "shk_<prefix>_<secret>". The prefix is stored in clear to find the row
+// and to identify the key in listings; only a SHA-256 hash of the whole key is stored.
+const (
+	keyScheme   = "shk"
+	prefixBytes = 6
+	secretBytes = 32
+)
+
+var knownScopes = map[string]struct{}{
+	auth.ScopeUsersRead:          {},
+	auth.ScopeUsersWrite:         {},
+	auth.ScopeEventsWrite:        {},
+	auth.ScopeNotificationsRead:  {},
+	auth.ScopeNotificationsWrite: {},
+	auth.ScopeAdmin:              {},
+}
+
+// APIKey is a row of the api_keys table.
+type APIKey struct {
+	ID         int64      `gorm:"primaryKey;autoIncrement" json:"id"`
+	Prefix     string     `gorm:"size:32;not null;uniqueIndex" json:"prefix"`
+	SecretHash string     `gorm:"size:64;not null" json:"-"`
+	Owner      string     `gorm:"size:255;not null" json:"owner"`
+	Scopes     []string   `gorm:"type:jsonb;serializer:json;not null" json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+// Active reports whether k may authenticate at now.
+func (k *APIKey) Active(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+// CreateRequest describes a new key.
+type CreateRequest struct {
+	Owner     string     `json:"owner"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// Issued is a freshly created key; Key is the plaintext and is never retrievable again.
+type Issued struct {
+	Key    string  `json:"key"`
+	APIKey *APIKey `json:"api_key"`
+}
+
+// Manager implements key administration on top of a Store.
+type Manager struct {
+	store *Store
+	// grace is how long a rotated key keeps working so callers can switch over.
+	grace time.Duration
+	cache *Cache
+}
+
+// NewManager returns a manager; cache, when set, forgets revoked keys immediately in this
+// process (other processes pick the change up after their cache TTL).
+func NewManager(store *Store, cache *Cache, rotationGrace time.Duration) *Manager {
+	return &Manager{store: store, cache: cache, grace: rotationGrace}
+}
+
+func (m *Manager) Create(ctx context.Context, req *CreateRequest) (*Issued, error) {
+	if req == nil {
+		return nil, apperrors.NewStatusError(http.StatusBadRequest, "request body is required")
+	}
+	owner := strings.TrimSpace(req.Owner)
+	if owner == "" {
+		return nil, apperrors.NewStatusError(http.StatusBadRequest, "owner is required")
+	}
+	if len(req.Scopes) == 0 {
+		return nil, apperrors.NewStatusError(http.StatusBadRequest, "at least one scope is required")
+	}
+	for _, s := range req.Scopes {
+		if _, ok := knownScopes[s]; !ok {
+			return nil, apperrors.NewStatusError(http.StatusBadRequest, "unknown scope "+s)
+		}
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, apperrors.NewStatusError(http.StatusBadRequest, "expires_at must be in the future")
+	}
+
+	key, k, err := newKey(owner, req.Scopes, req.ExpiresAt)
+	if err != nil {
+		return nil, apperrors.WrapStatus(err, http.StatusInternalServerError, "generate api key")
+	}
+	if err := m.store.Create(ctx, k); err != nil {
+		return nil, apperrors.WrapStatus(err, http.StatusInternalServerError, "create api key")
+	}
+	return &Issued{Key: key, APIKey: k}, nil
+}
+
+func (m *Manager) List(ctx context.Context, owner string) ([]APIKey, error) {
+	keys, err := m.store.List(ctx, owner)
+	if err != nil {
+		return nil, apperrors.WrapStatus(err, http.StatusInternalServerError, "list api keys")
+	}
+	return keys, nil
+}
+
+func (m *Manager) Revoke(ctx context.Context, id int64) (*APIKey, error) {
+	k, err := m.store.Revoke(ctx, id, time.Now())
+	if err != nil {
+		return nil, storeError(err, "revoke api key")
+	}
+	m.cache.Forget(k.SecretHash)
+	return k, nil
+}
+
+// Rotate issues a replacement for key id with the same owner, scopes and expiry. The old
+// key stays valid for the rotation grace period, or is revoked at once when it is zero.
+func (m *Manager) Rotate(ctx context.Context, id int64) (*Issued, error) {
+	var issued *Issued
+	var old *APIKey
+	err := m.store.Transaction(ctx, func(tx *Store) error {
+		var err error
+		old, err = tx.Get(ctx, id)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		if !old.Active(now) {
+			return apperrors.NewStatusError(http.StatusConflict, "api key is revoked or expired")
+		}
+		key, k, err := newKey(old.Owner, old.Scopes, old.ExpiresAt)
+		if err != nil {
+			return err
+		}
+		if err := tx.Create(ctx, k); err != nil {
+			return err
+		}
+		if m.grace <= 0 {
+			if _, err := tx.Revoke(ctx, id, now); err != nil {
+				return err
+			}
+		} else if err := tx.ExpireBy(ctx, id, now.Add(m.grace)); err != nil {
+			return err
+		}
+		issued = &Issued{Key: key, APIKey: k}
+		return nil
+	})
+	if err != nil {
+		return nil, storeError(err, "rotate api key")
+	}
+	m.cache.Forget(old.SecretHash)
+	return issued, nil
+}
+
+func storeError(err error, msg string) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return apperrors.NewStatusError(http.StatusNotFound, "api key not found")
+	}
+	if _, ok := apperrors.AsStatusError(err); ok {
+		return err
+	}
+	return apperrors.WrapStatus(err, http.StatusInternalServerError, msg)
+}
+
+func newKey(owner string, scopes []string, expiresAt *time.Time) (string, *APIKey, error) {
+	prefix, err := randomHex(prefixBytes)
+	if err != nil {
+		return "", nil, err
+	}
+	secret, err := randomHex(secretBytes)
+	if err != nil {
+		return "", nil, err
+	}
+	key := keyScheme + "_" + prefix + "_" + secret
+	return key, &APIKey{
+		Prefix:     prefix,
+		SecretHash: Hash(key),
+		Owner:      owner,
+		Scopes:     scopes,
+		ExpiresAt:  expiresAt,
+	}, nil
+}
+
+// Hash returns the stored form of key.
+func Hash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// parsePrefix extracts the prefix from a key in the shk_<prefix>_<secret> format.
+func parsePrefix(key string) (string, bool) {
+	parts := strings.SplitN(key, "_", 3)
+	if len(parts) != 3 || parts[0] != keyScheme || len(parts[1]) != 2*prefixBytes || len(parts[2]) != 2*secretBytes {
+		return "", false
+	}
+	return parts[1], true
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// New wires a store, lookup cache, manager and REST handler over db.
+func New(db *gorm.DB, cfg *models.APIKeysConfig) (*Cache, *Handler) {
+	store := NewStore(db)
+	cache := NewCache(store, cfg.CacheSize, cfg.CacheTTL)
+	return cache, NewHandler(NewManager(store, cache, cfg.RotationGrace))
+}
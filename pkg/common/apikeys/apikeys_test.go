@@ -0,0 +1,201 @@
+package apikeys
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/emorenkov/scorehub/pkg/common/auth"
+	"github.com/emorenkov/scorehub/pkg/common/db/migrate"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestNewKeyStoresOnlyTheHash(t *testing.T) {
+	key, k, err := newKey("svc", []string{auth.ScopeEventsWrite}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if k.SecretHash != Hash(key) || len(k.SecretHash) != 64 {
+		t.Fatalf("SecretHash = %q, want the SHA-256 of the key", k.SecretHash)
+	}
+	if strings.Contains(k.SecretHash, key) || strings.Contains(key, k.SecretHash) {
+		t.Fatal("stored hash contains the plaintext key")
+	}
+	prefix, ok := parsePrefix(key)
+	if !ok || prefix != k.Prefix {
+		t.Fatalf("parsePrefix(%q) = %q, %v; want %q", key, prefix, ok, k.Prefix)
+	}
+	if Hash(key+"x") == k.SecretHash {
+		t.Fatal("different keys hash alike")
+	}
+}
+
+func TestParsePrefixRejectsMalformedKeys(t *testing.T) {
+	key, _, err := newKey("svc", []string{auth.ScopeEventsWrite}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, bad := range []string{
+		"",
+		"shk_abc",
+		strings.Replace(key, "shk_", "xyz_", 1),
+		key[:len(key)-1],
+		key + "0",
+	} {
+		if _, ok := parsePrefix(bad); ok {
+			t.Errorf("parsePrefix(%q) accepted a malformed key", bad)
+		}
+	}
+}
+
+func TestActive(t *testing.T) {
+	now := time.Now()
+	past, future := now.Add(-time.Second), now.Add(time.Hour)
+	tests := []struct {
+		name string
+		key  APIKey
+		want bool
+	}{
+		{"no expiry", APIKey{}, true},
+		{"expires later", APIKey{ExpiresAt: &future}, true},
+		{"expired", APIKey{ExpiresAt: &past}, false},
+		{"revoked", APIKey{RevokedAt: &past}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.key.Active(now); got != tt.want {
+			t.Errorf("%s: Active = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCacheRejectsInactiveKeys(t *testing.T) {
+	key, k, err := newKey("svc", []string{auth.ScopeEventsWrite}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := NewCache(nil, 0, 0)
+	c.entries.Add(k.SecretHash, k)
+	p, err := c.LookupAPIKey(context.Background(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Subject != "api-key:"+k.Prefix || !p.APIKey || len(p.Scopes) != 1 {
+		t.Fatalf("principal = %+v", p)
+	}
+
+	revoked := *k
+	at := time.Now()
+	revoked.RevokedAt = &at
+	c.entries.Add(k.SecretHash, &revoked)
+	if _, err := c.LookupAPIKey(context.Background(), key); !errors.Is(err, ErrInactive) {
+		t.Fatalf("err = %v, want ErrInactive", err)
+	}
+
+	c.entries.Add(Hash("unknown"), nil)
+	if _, err := c.LookupAPIKey(context.Background(), "unknown"); !errors.Is(err, auth.ErrInvalidAPIKey) {
+		t.Fatalf("err = %v, want ErrInvalidAPIKey", err)
+	}
+}
+
+// testDB connects to SCOREHUB_TEST_POSTGRES_DSN and applies the migrations, skipping the
+// test when it is unset.
+func testDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := os.Getenv("SCOREHUB_TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("SCOREHUB_TEST_POSTGRES_DSN is not set")
+	}
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: dsn, PreferSimpleProtocol: true}), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	m, err := migrate.New(sqlDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Up(context.Background(), 0); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func newTestManager(t *testing.T, grace time.Duration) (*Manager, *Cache) {
+	t.Helper()
+	store := NewStore(testDB(t))
+	cache := NewCache(store, 0, time.Hour)
+	return NewManager(store, cache, grace), cache
+}
+
+func issue(t *testing.T, m *Manager) *Issued {
+	t.Helper()
+	issued, err := m.Create(context.Background(), &CreateRequest{Owner: t.Name(), Scopes: []string{auth.ScopeEventsWrite}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return issued
+}
+
+func TestRevokeTakesEffectImmediately(t *testing.T) {
+	m, cache := newTestManager(t, time.Hour)
+	ctx := context.Background()
+	issued := issue(t, m)
+	if _, err := cache.LookupAPIKey(ctx, issued.Key); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Revoke(ctx, issued.APIKey.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cache.LookupAPIKey(ctx, issued.Key); !errors.Is(err, ErrInactive) {
+		t.Fatalf("err = %v, want ErrInactive after revocation", err)
+	}
+	if _, err := m.Rotate(ctx, issued.APIKey.ID); err == nil {
+		t.Fatal("rotated a revoked key")
+	}
+}
+
+func TestRotateKeepsOldKeyForGrace(t *testing.T) {
+	m, cache := newTestManager(t, time.Hour)
+	ctx := context.Background()
+	old := issue(t, m)
+	replacement, err := m.Rotate(ctx, old.APIKey.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{old.Key, replacement.Key} {
+		if _, err := cache.LookupAPIKey(ctx, key); err != nil {
+			t.Fatalf("key unusable during the grace period: %v", err)
+		}
+	}
+	stored, err := m.store.Get(ctx, old.APIKey.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.ExpiresAt == nil || time.Until(*stored.ExpiresAt) > time.Hour || time.Until(*stored.ExpiresAt) < 59*time.Minute {
+		t.Fatalf("old key expires at %v, want in about an hour", stored.ExpiresAt)
+	}
+}
+
+func TestRotateWithoutGraceRevokesOldKey(t *testing.T) {
+	m, cache := newTestManager(t, 0)
+	ctx := context.Background()
+	old := issue(t, m)
+	replacement, err := m.Rotate(ctx, old.APIKey.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cache.LookupAPIKey(ctx, old.Key); !errors.Is(err, ErrInactive) {
+		t.Fatalf("err = %v, want ErrInactive for the rotated key", err)
+	}
+	if _, err := cache.LookupAPIKey(ctx, replacement.Key); err != nil {
+		t.Fatal(err)
+	}
+}
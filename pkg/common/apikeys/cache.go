@@ -0,0 +1,95 @@
+package apikeys
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"time"
+
+	"github.com/emorenkov/scorehub/pkg/common/auth"
+	logpkg "github.com/emorenkov/scorehub/pkg/common/logger"
+	"github.com/hashicorp/golang-lru/v2/expirable"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultCacheSize = 10000
+	defaultCacheTTL  = time.Minute
+)
+
+// ErrInactive is returned for a key that was revoked or has expired.
+var ErrInactive = errors.New("api key revoked or expired")
+
+// Cache resolves presented keys to principals, remembering hits and misses for a TTL so
+// most requests do not reach the database. It implements auth.KeyLookup.
+type Cache struct {
+	store *Store
+	// entries is keyed by Hash(key); a nil value records an unknown key.
+	entries *expirable.LRU[string, *APIKey]
+}
+
+// NewCache returns a cache over store; revocations made by other processes take effect
+// within ttl. Non-positive size and ttl select defaults.
+func NewCache(store *Store, size int, ttl time.Duration) *Cache {
+	if size <= 0 {
+		size = defaultCacheSize
+	}
+	if ttl <= 0 {
+		ttl = defaultCacheTTL
+	}
+	return &Cache{store: store, entries: expirable.NewLRU[string, *APIKey](size, nil, ttl)}
+}
+
+// LookupAPIKey returns the principal for key with the scopes stored for it.
+func (c *Cache) LookupAPIKey(ctx context.Context, key string) (*auth.Principal, error) {
+	hash := Hash(key)
+	k, ok := c.entries.Get(hash)
+	if !ok {
+		var err error
+		if k, err = c.load(ctx, key, hash); err != nil {
+			return nil, err
+		}
+		c.entries.Add(hash, k)
+	}
+	if k == nil {
+		return nil, auth.ErrInvalidAPIKey
+	}
+	if !k.Active(time.Now()) {
+		return nil, ErrInactive
+	}
+	return &auth.Principal{Subject: "api-key:" + k.Prefix, Scopes: k.Scopes, APIKey: true}, nil
+}
+
+// load fetches the row for key, returning nil for unknown keys. last_used_at is updated
+// here, so it is accurate to within the cache TTL.
+func (c *Cache) load(ctx context.Context, key, hash string) (*APIKey, error) {
+	prefix, ok := parsePrefix(key)
+	if !ok {
+		return nil, nil
+	}
+	k, err := c.store.GetByPrefix(ctx, prefix)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(hash), []byte(k.SecretHash)) != 1 {
+		return nil, nil
+	}
+	if k.Active(time.Now()) {
+		if err := c.store.Touch(ctx, k.ID, time.Now()); err != nil {
+			logpkg.FromContext(ctx).Warn("update api key last_used_at failed", zap.Error(err), zap.String("prefix", k.Prefix))
+		}
+	}
+	return k, nil
+}
+
+// Forget drops the cached entry for the key stored with hash.
+func (c *Cache) Forget(hash string) {
+	if c == nil {
+		return
+	}
+	c.entries.Remove(hash)
+}
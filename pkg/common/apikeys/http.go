@@ -0,0 +1,96 @@
+package apikeys
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	apperrors "github.com/emorenkov/scorehub/pkg/common/errors"
+	logpkg "github.com/emorenkov/scorehub/pkg/common/logger"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// Handler exposes Manager over REST.
+type Handler struct {
+	manager *Manager
+}
+
+func NewHandler(manager *Manager) *Handler {
+	return &Handler{manager: manager}
+}
+
+// Register mounts the admin routes on g; callers are expected to protect g.
+func (h *Handler) Register(g *echo.Group) {
+	g.POST("/admin/api-keys", h.create)
+	g.GET("/admin/api-keys", h.list)
+	g.DELETE("/admin/api-keys/:id", h.revoke)
+	g.POST("/admin/api-keys/:id/rotate", h.rotate)
+}
+
+func (h *Handler) create(c echo.Context) error {
+	log := logpkg.FromContext(c.Request().Context())
+	var req CreateRequest
+	if err := json.NewDecoder(c.Request().Body).Decode(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid json"})
+	}
+	issued, err := h.manager.Create(c.Request().Context(), &req)
+	if err != nil {
+		log.Error("createAPIKey failed", zap.Error(err), zap.String("owner", req.Owner))
+		return writeError(c, err)
+	}
+	log.Info("createAPIKey succeeded", zap.Int64("api_key_id", issued.APIKey.ID), zap.String("owner", issued.APIKey.Owner))
+	return c.JSON(http.StatusCreated, issued)
+}
+
+func (h *Handler) list(c echo.Context) error {
+	log := logpkg.FromContext(c.Request().Context())
+	keys, err := h.manager.List(c.Request().Context(), c.QueryParam("owner"))
+	if err != nil {
+		log.Error("listAPIKeys failed", zap.Error(err))
+		return writeError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string][]APIKey{"api_keys": keys})
+}
+
+func (h *Handler) revoke(c echo.Context) error {
+	log := logpkg.FromContext(c.Request().Context())
+	id, ok := parseID(c)
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	k, err := h.manager.Revoke(c.Request().Context(), id)
+	if err != nil {
+		log.Error("revokeAPIKey failed", zap.Error(err), zap.Int64("api_key_id", id))
+		return writeError(c, err)
+	}
+	log.Info("revokeAPIKey succeeded", zap.Int64("api_key_id", id), zap.String("owner", k.Owner))
+	return c.JSON(http.StatusOK, k)
+}
+
+func (h *Handler) rotate(c echo.Context) error {
+	log := logpkg.FromContext(c.Request().Context())
+	id, ok := parseID(c)
+	if !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	issued, err := h.manager.Rotate(c.Request().Context(), id)
+	if err != nil {
+		log.Error("rotateAPIKey failed", zap.Error(err), zap.Int64("api_key_id", id))
+		return writeError(c, err)
+	}
+	log.Info("rotateAPIKey succeeded", zap.Int64("api_key_id", id), zap.Int64("new_api_key_id", issued.APIKey.ID))
+	return c.JSON(http.StatusCreated, issued)
+}
+
+func parseID(c echo.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	return id, err == nil && id > 0
+}
+
+func writeError(c echo.Context, err error) error {
+	if se, ok := apperrors.AsStatusError(err); ok {
+		return c.JSON(se.Status, map[string]string{"error": se.Message})
+	}
+	return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,85 @@
+package apikeys
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Store persists API keys in the api_keys table.
+type Store struct {
+	db *gorm.DB
+}
+
+func NewStore(db *gorm.DB) *Store {
+	return &Store{db: db}
+}
+
+// Transaction runs fn with a Store bound to a single database transaction.
+func (s *Store) Transaction(ctx context.Context, fn func(tx *Store) error) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&Store{db: tx})
+	})
+}
+
+func (s *Store) Create(ctx context.Context, k *APIKey) error {
+	return s.db.WithContext(ctx).Create(k).Error
+}
+
+func (s *Store) Get(ctx context.Context, id int64) (*APIKey, error) {
+	var k APIKey
+	if err := s.db.WithContext(ctx).First(&k, id).Error; err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
+
+func (s *Store) GetByPrefix(ctx context.Context, prefix string) (*APIKey, error) {
+	var k APIKey
+	if err := s.db.WithContext(ctx).Where("prefix = ?", prefix).First(&k).Error; err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
+
+// List returns keys newest first, restricted to owner when it is not empty.
+func (s *Store) List(ctx context.Context, owner string) ([]APIKey, error) {
+	var keys []APIKey
+	query := s.db.WithContext(ctx)
+	if owner != "" {
+		query = query.Where("owner = ?", owner)
+	}
+	if err := query.Order("id DESC").Find(&keys).Error; err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// Revoke marks key id revoked at at; revoking an already revoked key keeps the first time.
+func (s *Store) Revoke(ctx context.Context, id int64, at time.Time) (*APIKey, error) {
+	k, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if k.RevokedAt != nil {
+		return k, nil
+	}
+	if err := s.db.WithContext(ctx).Model(k).Update("revoked_at", at).Error; err != nil {
+		return nil, err
+	}
+	k.RevokedAt = &at
+	return k, nil
+}
+
+// ExpireBy moves key id's expiry to at unless it already expires earlier.
+func (s *Store) ExpireBy(ctx context.Context, id int64, at time.Time) error {
+	return s.db.WithContext(ctx).Model(&APIKey{}).
+		Where("id = ? AND (expires_at IS NULL OR expires_at > ?)", id, at).
+		Update("expires_at", at).Error
+}
+
+// Touch records that key id authenticated a request at at.
+func (s *Store) Touch(ctx context.Context, id int64, at time.Time) error {
+	return s.db.WithContext(ctx).Model(&APIKey{}).Where("id = ?", id).Update("last_used_at", at).Error
+}
@@ -0,0 +1,100 @@
+// Package auth authenticates callers by JWT bearer token or service API key and
+// authorizes them by scope and, for end users, by the sub claim.
+package auth
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	apperrors "github.com/emorenkov/scorehub/pkg/common/errors"
+)
+
+// Scopes granted to tokens and required by routes and RPCs.
+const (
+	ScopeUsersRead          = "users:read"
+	ScopeUsersWrite         = "users:write"
+	ScopeEventsWrite        = "events:write"
+	ScopeNotificationsRead  = "notifications:read"
+	ScopeNotificationsWrite = "notifications:write"
+	// ScopeAdmin covers operator endpoints (rules, dead letters) and reading any user's data.
+	ScopeAdmin = "admin"
+)
+
+// Principal is the authenticated caller.
+type Principal struct {
+	// Subject is the JWT sub claim; for end users it is their user ID.
+	Subject string
+	Scopes  []string
+	// Service marks API-key callers, which act on behalf of the platform and hold every scope.
+	Service bool
+	// APIKey marks callers that presented an API key, static or issued, rather than a token.
+	APIKey bool
+}
+
+// HasScope reports whether p was granted scope.
+func (p *Principal) HasScope(scope string) bool {
+	if p.Service {
+		return true
+	}
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Privileged reports whether p may act on any user's data.
+func (p *Principal) Privileged() bool {
+	return p.Service || p.HasScope(ScopeAdmin)
+}
+
+type ctxKey struct{}
+
+// NewContext returns ctx carrying p.
+func NewContext(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, ctxKey{}, p)
+}
+
+// FromContext returns the caller in ctx, or nil when authentication is disabled or the
+// call is internal (e.g. a Kafka consumer).
+func FromContext(ctx context.Context) *Principal {
+	p, _ := ctx.Value(ctxKey{}).(*Principal)
+	return p
+}
+
+// RequireScopes returns a 403 StatusError unless the caller in ctx holds every scope.
+// Calls without a caller are allowed.
+func RequireScopes(ctx context.Context, scopes ...string) error {
+	p := FromContext(ctx)
+	if p == nil {
+		return nil
+	}
+	for _, s := range scopes {
+		if !p.HasScope(s) {
+			return apperrors.NewStatusError(http.StatusForbidden, "missing scope "+s)
+		}
+	}
+	return nil
+}
+
+// AuthorizeUser returns a 403 StatusError unless the caller in ctx is privileged or is
+// the end user userID. Calls without a caller are allowed.
+func AuthorizeUser(ctx context.Context, userID int64) error {
+	p := FromContext(ctx)
+	if p == nil || p.Privileged() || p.Subject == strconv.FormatInt(userID, 10) {
+		return nil
+	}
+	return apperrors.NewStatusError(http.StatusForbidden, "access to another user's data is not allowed")
+}
+
+// AuthorizeAllUsers returns a 403 StatusError unless the caller in ctx may read data
+// across users. Calls without a caller are allowed.
+func AuthorizeAllUsers(ctx context.Context) error {
+	p := FromContext(ctx)
+	if p == nil || p.Privileged() {
+		return nil
+	}
+	return apperrors.NewStatusError(http.StatusForbidden, "listing other users' data is not allowed")
+}
@@ -0,0 +1,87 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"strings"
+
+	"github.com/emorenkov/scorehub/pkg/common/models"
+)
+
+// Credential header names; gRPC metadata uses the lower-case forms.
+const (
+	APIKeyHeader        = "X-API-Key"
+	AuthorizationHeader = "Authorization"
+)
+
+var (
+	// ErrNoCredentials is returned when a request carries neither an API key nor a bearer token.
+	ErrNoCredentials = errors.New("missing credentials")
+	// ErrInvalidAPIKey is returned for an API key that matches no configured or issued key.
+	ErrInvalidAPIKey = errors.New("invalid api key")
+)
+
+// KeyLookup resolves API keys issued to service accounts, e.g. from a database.
+type KeyLookup interface {
+	LookupAPIKey(ctx context.Context, key string) (*Principal, error)
+}
+
+// Authenticator accepts the static service API key, issued API keys and JWT bearer
+// tokens. Each may be disabled.
+type Authenticator struct {
+	apiKey   string
+	keys     KeyLookup
+	verifier *Verifier
+}
+
+// NewAuthenticator returns nil when no credential source is configured, which leaves the
+// service open; every method tolerates a nil receiver.
+func NewAuthenticator(apiKey string, keys KeyLookup, verifier *Verifier) *Authenticator {
+	if apiKey == "" && keys == nil && verifier == nil {
+		return nil
+	}
+	return &Authenticator{apiKey: apiKey, keys: keys, verifier: verifier}
+}
+
+// Enabled reports whether requests are authenticated at all.
+func (a *Authenticator) Enabled() bool {
+	return a != nil
+}
+
+// Authenticate resolves the caller from an X-API-Key value and/or an Authorization
+// header. A bearer token takes precedence over the API key, and the static key is
+// checked before issued keys.
+func (a *Authenticator) Authenticate(ctx context.Context, apiKey, authorization string) (*Principal, error) {
+	if token, ok := bearer(authorization); ok && a.verifier != nil {
+		return a.verifier.Verify(ctx, token)
+	}
+	if apiKey == "" {
+		return nil, ErrNoCredentials
+	}
+	if a.apiKey != "" && subtle.ConstantTimeCompare([]byte(apiKey), []byte(a.apiKey)) == 1 {
+		return &Principal{Subject: "api-key", Service: true, APIKey: true}, nil
+	}
+	if a.keys != nil {
+		return a.keys.LookupAPIKey(ctx, apiKey)
+	}
+	return nil, ErrInvalidAPIKey
+}
+
+func bearer(header string) (string, bool) {
+	const prefix = "bearer "
+	if len(header) <= len(prefix) || !strings.EqualFold(header[:len(prefix)], prefix) {
+		return "", false
+	}
+	return strings.TrimSpace(header[len(prefix):]), true
+}
+
+// FromConfig builds the JWT verifier described by cfg and combines it with apiKey and
+// keys, which may be nil.
+func FromConfig(ctx context.Context, apiKey string, keys KeyLookup, cfg *models.AuthConfig) (*Authenticator, error) {
+	verifier, err := NewVerifier(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return NewAuthenticator(apiKey, keys, verifier), nil
+}
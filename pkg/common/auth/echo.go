@@ -0,0 +1,43 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// EchoMiddleware authenticates every request on the group it is applied to and stores
+// the caller in the request context. A nil Authenticator lets requests through.
+func (a *Authenticator) EchoMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		if a == nil {
+			return next
+		}
+		return func(c echo.Context) error {
+			req := c.Request()
+			p, err := a.Authenticate(req.Context(), req.Header.Get(APIKeyHeader), req.Header.Get(AuthorizationHeader))
+			if err != nil {
+				if errors.Is(err, ErrNoCredentials) {
+					c.Response().Header().Set("WWW-Authenticate", "Bearer")
+				}
+				return c.JSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+			}
+			c.SetRequest(req.WithContext(NewContext(req.Context(), p)))
+			return next(c)
+		}
+	}
+}
+
+// Scopes rejects requests whose caller lacks any of scopes with 403. Mount it after
+// EchoMiddleware.
+func Scopes(scopes ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if err := RequireScopes(c.Request().Context(), scopes...); err != nil {
+				return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+			}
+			return next(c)
+		}
+	}
+}
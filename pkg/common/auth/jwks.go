@@ -0,0 +1,193 @@
+package auth
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultJWKSRefresh = 5 * time.Minute
+	// minJWKSRefetch limits how often the document is reloaded, whether for stale keys
+	// or for tokens with an unknown kid.
+	minJWKSRefetch = 30 * time.Second
+	maxJWKSSize    = 1 << 20
+)
+
+// keySet caches the RSA keys of a JWKS document read from a file or URL and reloads
+// it periodically so rotated keys are picked up without a restart.
+type keySet struct {
+	file    string
+	url     string
+	refresh time.Duration
+	client  *http.Client
+
+	// fetchMu serializes reloads. It is held during the fetch, which mu never is, so
+	// verifications with cached keys do not wait on the JWKS source.
+	fetchMu sync.Mutex
+	// refreshing is set while a background refresh of stale keys runs.
+	refreshing atomic.Bool
+
+	mu      sync.Mutex
+	keys    map[string]*rsa.PublicKey
+	fetched time.Time
+	// attempted is when the last reload started, successful or not.
+	attempted time.Time
+}
+
+func newKeySet(file, url string, refresh time.Duration) *keySet {
+	if refresh <= 0 {
+		refresh = defaultJWKSRefresh
+	}
+	return &keySet{
+		file:    file,
+		url:     url,
+		refresh: refresh,
+		client:  &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// get returns the key for kid. A token without kid matches a set holding a single key.
+func (k *keySet) get(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	key, stale := k.cached(kid)
+	if key != nil {
+		if stale && k.refreshing.CompareAndSwap(false, true) {
+			// Keep serving the cached keys while they are refreshed, and if the source
+			// is briefly unavailable.
+			go func() {
+				defer k.refreshing.Store(false)
+				_ = k.reload(context.WithoutCancel(ctx), minJWKSRefetch)
+			}()
+		}
+		return key, nil
+	}
+	// The key may have been rotated in. Reloads start at most once per minJWKSRefetch,
+	// so tokens with made-up kids cannot hammer the source.
+	err := k.reload(ctx, minJWKSRefetch)
+	if key, _ := k.cached(kid); key != nil {
+		return key, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// cached returns the cached key for kid, if any, and whether the keys are due a refresh.
+func (k *keySet) cached(kid string) (*rsa.PublicKey, bool) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	stale := time.Since(k.fetched) > k.refresh
+	if kid == "" && len(k.keys) == 1 {
+		for _, key := range k.keys {
+			return key, stale
+		}
+	}
+	return k.keys[kid], stale
+}
+
+// reload fetches the document and replaces the keys, unless a reload started less than
+// minAge ago; callers that waited for that reload use its result.
+func (k *keySet) reload(ctx context.Context, minAge time.Duration) error {
+	k.fetchMu.Lock()
+	defer k.fetchMu.Unlock()
+
+	k.mu.Lock()
+	if minAge > 0 && time.Since(k.attempted) < minAge {
+		k.mu.Unlock()
+		return nil
+	}
+	k.attempted = time.Now()
+	k.mu.Unlock()
+
+	data, err := k.read(ctx)
+	if err != nil {
+		return err
+	}
+	keys, err := parseJWKS(data)
+	if err != nil {
+		return err
+	}
+	k.mu.Lock()
+	k.keys = keys
+	k.fetched = time.Now()
+	k.mu.Unlock()
+	return nil
+}
+
+func (k *keySet) read(ctx context.Context) ([]byte, error) {
+	if k.file != "" {
+		return os.ReadFile(k.file)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, k.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := k.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch %s: status %d", k.url, resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxJWKSSize))
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+func parseJWKS(data []byte) (map[string]*rsa.PublicKey, error) {
+	var doc struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("decode jwks: %w", err)
+	}
+	keys := make(map[string]*rsa.PublicKey, len(doc.Keys))
+	for _, key := range doc.Keys {
+		if key.Kty != "RSA" || (key.Use != "" && key.Use != "sig") {
+			continue
+		}
+		pub, err := rsaKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", key.Kid, err)
+		}
+		keys[key.Kid] = pub
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("jwks has no RSA signing keys")
+	}
+	return keys, nil
+}
+
+func rsaKey(key jwk) (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(key.N)
+	if err != nil {
+		return nil, fmt.Errorf("decode modulus: %w", err)
+	}
+	e, err := base64.RawURLEncoding.DecodeString(key.E)
+	if err != nil {
+		return nil, fmt.Errorf("decode exponent: %w", err)
+	}
+	exp := new(big.Int).SetBytes(e)
+	if !exp.IsInt64() || exp.Int64() < 3 || exp.Int64() > 1<<31-1 {
+		return nil, errors.New("invalid exponent")
+	}
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exp.Int64())}, nil
+}
@@ -0,0 +1,158 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// jwksServer serves the public halves of its current keys and counts fetches.
+type jwksServer struct {
+	*httptest.Server
+	mu      sync.Mutex
+	keys    map[string]*rsa.PrivateKey
+	fetches atomic.Int32
+	// block, when set, holds fetches until it is closed.
+	block chan struct{}
+}
+
+func newJWKSServer(t *testing.T, kids ...string) *jwksServer {
+	t.Helper()
+	s := &jwksServer{keys: map[string]*rsa.PrivateKey{}}
+	for _, kid := range kids {
+		s.add(t, kid)
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		s.fetches.Add(1)
+		s.mu.Lock()
+		block := s.block
+		var doc struct {
+			Keys []jwk `json:"keys"`
+		}
+		for kid, key := range s.keys {
+			doc.Keys = append(doc.Keys, jwk{
+				Kty: "RSA",
+				Kid: kid,
+				Use: "sig",
+				N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			})
+		}
+		s.mu.Unlock()
+		if block != nil {
+			<-block
+		}
+		_ = json.NewEncoder(w).Encode(doc)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *jwksServer) add(t *testing.T, kid string) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys[kid] = key
+	return key
+}
+
+func (s *jwksServer) key(kid string) *rsa.PrivateKey {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.keys[kid]
+}
+
+// allowRefetch makes the rate limit on reloads pass, as if minJWKSRefetch had elapsed.
+func allowRefetch(k *keySet) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.attempted = time.Now().Add(-2 * minJWKSRefetch)
+}
+
+func loadedKeySet(t *testing.T, url string) *keySet {
+	t.Helper()
+	k := newKeySet("", url, time.Hour)
+	if err := k.reload(context.Background(), 0); err != nil {
+		t.Fatal(err)
+	}
+	return k
+}
+
+func TestKeySetPicksUpRotatedKey(t *testing.T) {
+	srv := newJWKSServer(t, "old")
+	k := loadedKeySet(t, srv.URL)
+
+	srv.add(t, "new")
+	allowRefetch(k)
+	key, err := k.get(context.Background(), "new")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key.N.Cmp(srv.key("new").N) != 0 {
+		t.Fatal("got the wrong key for the rotated kid")
+	}
+}
+
+func TestKeySetLimitsUnknownKidFetches(t *testing.T) {
+	srv := newJWKSServer(t, "k1")
+	k := loadedKeySet(t, srv.URL)
+	allowRefetch(k)
+
+	for i := 0; i < 5; i++ {
+		if _, err := k.get(context.Background(), "bogus"); err == nil {
+			t.Fatal("unknown kid was accepted")
+		}
+	}
+	// One fetch at startup and one for the first unknown kid.
+	if n := srv.fetches.Load(); n != 2 {
+		t.Fatalf("fetched %d times, want 2", n)
+	}
+}
+
+func TestKeySetServesCachedKeysDuringFetch(t *testing.T) {
+	srv := newJWKSServer(t, "k1")
+	k := loadedKeySet(t, srv.URL)
+	allowRefetch(k)
+
+	block := make(chan struct{})
+	srv.mu.Lock()
+	srv.block = block
+	srv.mu.Unlock()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = k.get(context.Background(), "bogus")
+	}()
+	for srv.fetches.Load() < 2 {
+		time.Sleep(time.Millisecond)
+	}
+
+	got := make(chan error, 1)
+	go func() {
+		_, err := k.get(context.Background(), "k1")
+		got <- err
+	}()
+	select {
+	case err := <-got:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("a cached key waited for the fetch")
+	}
+	close(block)
+	<-done
+}
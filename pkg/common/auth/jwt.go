@@ -0,0 +1,89 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/emorenkov/scorehub/pkg/common/models"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Verifier validates HS256 tokens signed with a shared secret and RS256 tokens signed by
+// a key in a JWKS document.
+type Verifier struct {
+	secret []byte
+	keys   *keySet
+	parser *jwt.Parser
+}
+
+type claims struct {
+	jwt.RegisteredClaims
+	// Scope is the space-delimited OAuth 2.0 form; Scp is the array form some issuers use.
+	Scope string   `json:"scope"`
+	Scp   []string `json:"scp"`
+}
+
+// NewVerifier builds a verifier from cfg and loads the JWKS once so misconfiguration
+// fails at startup. It returns nil when no signing key source is configured.
+func NewVerifier(ctx context.Context, cfg *models.AuthConfig) (*Verifier, error) {
+	if cfg == nil || (cfg.JWTSecret == "" && cfg.JWKSFile == "" && cfg.JWKSURL == "") {
+		return nil, nil
+	}
+
+	v := &Verifier{}
+	var methods []string
+	if cfg.JWTSecret != "" {
+		v.secret = []byte(cfg.JWTSecret)
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+	if cfg.JWKSFile != "" || cfg.JWKSURL != "" {
+		v.keys = newKeySet(cfg.JWKSFile, cfg.JWKSURL, cfg.JWKSRefresh)
+		if err := v.keys.reload(ctx, 0); err != nil {
+			return nil, fmt.Errorf("load jwks: %w", err)
+		}
+		methods = append(methods, jwt.SigningMethodRS256.Alg())
+	}
+
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods(methods),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(30 * time.Second),
+	}
+	if cfg.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		opts = append(opts, jwt.WithAudience(cfg.Audience))
+	}
+	v.parser = jwt.NewParser(opts...)
+	return v, nil
+}
+
+// Verify checks token's signature and registered claims and returns its principal.
+func (v *Verifier) Verify(ctx context.Context, token string) (*Principal, error) {
+	var c claims
+	_, err := v.parser.ParseWithClaims(token, &c, func(t *jwt.Token) (any, error) {
+		switch t.Method.Alg() {
+		case jwt.SigningMethodHS256.Alg():
+			return v.secret, nil
+		case jwt.SigningMethodRS256.Alg():
+			kid, _ := t.Header["kid"].(string)
+			return v.keys.get(ctx, kid)
+		default:
+			return nil, fmt.Errorf("unexpected signing method %s", t.Method.Alg())
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	if c.Subject == "" {
+		return nil, errors.New("token has no sub claim")
+	}
+
+	scopes := strings.Fields(c.Scope)
+	scopes = append(scopes, c.Scp...)
+	return &Principal{Subject: c.Subject, Scopes: scopes}, nil
+}
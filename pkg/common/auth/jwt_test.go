@@ -0,0 +1,112 @@
+package auth
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/emorenkov/scorehub/pkg/common/models"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const testSecret = "test-secret"
+
+func newTestVerifier(t *testing.T, cfg *models.AuthConfig) *Verifier {
+	t.Helper()
+	v, err := NewVerifier(context.Background(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func signHS256(t *testing.T, c jwt.MapClaims) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, c).SignedString([]byte(testSecret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestVerifyExpiry(t *testing.T) {
+	v := newTestVerifier(t, &models.AuthConfig{JWTSecret: testSecret})
+	tests := []struct {
+		name   string
+		claims jwt.MapClaims
+		ok     bool
+	}{
+		{"valid", jwt.MapClaims{"sub": "1", "exp": time.Now().Add(time.Minute).Unix()}, true},
+		{"within leeway", jwt.MapClaims{"sub": "1", "exp": time.Now().Add(-10 * time.Second).Unix()}, true},
+		{"expired", jwt.MapClaims{"sub": "1", "exp": time.Now().Add(-time.Minute).Unix()}, false},
+		{"no exp", jwt.MapClaims{"sub": "1"}, false},
+		{"not yet valid", jwt.MapClaims{"sub": "1", "exp": time.Now().Add(time.Hour).Unix(), "nbf": time.Now().Add(time.Minute).Unix()}, false},
+		{"no sub", jwt.MapClaims{"exp": time.Now().Add(time.Minute).Unix()}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := v.Verify(context.Background(), signHS256(t, tt.claims))
+			if (err == nil) != tt.ok {
+				t.Fatalf("Verify error = %v, want ok %v", err, tt.ok)
+			}
+		})
+	}
+}
+
+func TestVerifyAudienceAndIssuer(t *testing.T) {
+	v := newTestVerifier(t, &models.AuthConfig{JWTSecret: testSecret, Audience: "scorehub", Issuer: "https://issuer"})
+	exp := time.Now().Add(time.Minute).Unix()
+	tests := []struct {
+		name   string
+		claims jwt.MapClaims
+		ok     bool
+	}{
+		{"matching", jwt.MapClaims{"sub": "1", "exp": exp, "aud": "scorehub", "iss": "https://issuer"}, true},
+		{"one of several audiences", jwt.MapClaims{"sub": "1", "exp": exp, "aud": []string{"other", "scorehub"}, "iss": "https://issuer"}, true},
+		{"other audience", jwt.MapClaims{"sub": "1", "exp": exp, "aud": "other", "iss": "https://issuer"}, false},
+		{"no audience", jwt.MapClaims{"sub": "1", "exp": exp, "iss": "https://issuer"}, false},
+		{"other issuer", jwt.MapClaims{"sub": "1", "exp": exp, "aud": "scorehub", "iss": "https://evil"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := v.Verify(context.Background(), signHS256(t, tt.claims))
+			if (err == nil) != tt.ok {
+				t.Fatalf("Verify error = %v, want ok %v", err, tt.ok)
+			}
+		})
+	}
+}
+
+func TestVerifyRS256FromJWKS(t *testing.T) {
+	srv := newJWKSServer(t, "k1")
+	v := newTestVerifier(t, &models.AuthConfig{JWKSURL: srv.URL, JWKSRefresh: time.Hour, Audience: "scorehub"})
+	sign := func(c jwt.MapClaims) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, c)
+		token.Header["kid"] = "k1"
+		s, err := token.SignedString(srv.key("k1"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	exp := time.Now().Add(time.Minute).Unix()
+
+	p, err := v.Verify(context.Background(), sign(jwt.MapClaims{"sub": "7", "exp": exp, "aud": "scorehub", "scope": "a b", "scp": []string{"c"}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Subject != "7" || !slices.Equal(p.Scopes, []string{"a", "b", "c"}) {
+		t.Fatalf("principal = %+v", p)
+	}
+	if _, err := v.Verify(context.Background(), sign(jwt.MapClaims{"sub": "7", "exp": exp, "aud": "other"})); err == nil {
+		t.Fatal("accepted a token for another audience")
+	}
+	if _, err := v.Verify(context.Background(), sign(jwt.MapClaims{"sub": "7", "exp": time.Now().Add(-time.Hour).Unix(), "aud": "scorehub"})); err == nil {
+		t.Fatal("accepted an expired token")
+	}
+	// Without a secret, HS256 tokens must not be accepted.
+	if _, err := v.Verify(context.Background(), signHS256(t, jwt.MapClaims{"sub": "7", "exp": exp, "aud": "scorehub"})); err == nil {
+		t.Fatal("accepted an HS256 token without a configured secret")
+	}
+}
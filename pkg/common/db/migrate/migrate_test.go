@@ -0,0 +1,277 @@
+package migrate
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+var testMigrations = fstest.MapFS{
+	"0001_widgets.up.sql":      {Data: []byte(`CREATE TABLE widgets (id BIGINT PRIMARY KEY)`)},
+	"0001_widgets.down.sql":    {Data: []byte(`DROP TABLE widgets`)},
+	"0002_gadgets.up.sql":      {Data: []byte(`CREATE TABLE gadgets (id BIGINT PRIMARY KEY); INSERT INTO runs DEFAULT VALUES`)},
+	"0002_gadgets.down.sql":    {Data: []byte(`DROP TABLE gadgets`)},
+	"0003_gadget_idx.up.sql":   {Data: []byte(noTransaction + "\nCREATE INDEX CONCURRENTLY gadgets_id ON gadgets (id)")},
+	"0003_gadget_idx.down.sql": {Data: []byte(`DROP INDEX gadgets_id`)},
+	"README.md":                {Data: []byte("not a migration")},
+}
+
+func TestLoad(t *testing.T) {
+	migrations, err := Load(testMigrations)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) != 3 {
+		t.Fatalf("loaded %d migrations, want 3", len(migrations))
+	}
+	for i, want := range []string{"widgets", "gadgets", "gadget_idx"} {
+		if m := migrations[i]; m.Version != int64(i+1) || m.Name != want || m.Up == "" || m.Down == "" {
+			t.Fatalf("migration %d = %+v", i, m)
+		}
+	}
+}
+
+func TestLoadRejectsIncompleteMigrations(t *testing.T) {
+	for name, fsys := range map[string]fstest.MapFS{
+		"missing down": {"0001_a.up.sql": {Data: []byte("SELECT 1")}},
+		"two names": {
+			"0001_a.up.sql":   {Data: []byte("SELECT 1")},
+			"0001_b.down.sql": {Data: []byte("SELECT 1")},
+		},
+	} {
+		if _, err := Load(fsys); err == nil {
+			t.Errorf("%s: Load succeeded", name)
+		}
+	}
+}
+
+func TestEmbedded(t *testing.T) {
+	migrations, err := Embedded()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, m := range migrations {
+		if m.Version != int64(i+1) {
+			t.Fatalf("migration %d_%s breaks the version sequence", m.Version, m.Name)
+		}
+	}
+}
+
+func TestCreate(t *testing.T) {
+	dir := t.TempDir()
+	for name, f := range testMigrations {
+		if err := os.WriteFile(filepath.Join(dir, name), f.Data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	up, down, err := Create(dir, "Add Things")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(up) != "0004_add_things.up.sql" || filepath.Base(down) != "0004_add_things.down.sql" {
+		t.Fatalf("created %s and %s", up, down)
+	}
+	if _, _, err := Create(dir, "drop; table"); err == nil {
+		t.Fatal("Create accepted an invalid name")
+	}
+}
+
+// testDB connects to SCOREHUB_TEST_POSTGRES_DSN with a fresh schema first on the search
+// path, skipping the test when it is unset.
+func testDB(t *testing.T) *sql.DB {
+	t.Helper()
+	dsn := os.Getenv("SCOREHUB_TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("SCOREHUB_TEST_POSTGRES_DSN is not set")
+	}
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		t.Fatal(err)
+	}
+	schema := "migrate_test_" + hex.EncodeToString(b)
+
+	cfg, err := pgx.ParseConfig(dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.RuntimeParams["search_path"] = schema
+	db := stdlib.OpenDB(*cfg)
+	t.Cleanup(func() {
+		_, _ = db.Exec(`DROP SCHEMA ` + schema + ` CASCADE`)
+		db.Close()
+	})
+	if _, err := db.Exec(`CREATE SCHEMA ` + schema); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`CREATE TABLE runs (id BIGSERIAL PRIMARY KEY)`); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func testMigrator(t *testing.T, db *sql.DB) *Migrator {
+	t.Helper()
+	migrations, err := Load(testMigrations)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Migrator{db: db, migrations: migrations}
+}
+
+func applied(t *testing.T, m *Migrator) []int64 {
+	t.Helper()
+	statuses, err := m.Status(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var versions []int64
+	for _, s := range statuses {
+		if s.AppliedAt != nil {
+			versions = append(versions, s.Version)
+		}
+	}
+	return versions
+}
+
+func tableExists(t *testing.T, db *sql.DB, name string) bool {
+	t.Helper()
+	var exists bool
+	if err := db.QueryRow(`SELECT to_regclass($1) IS NOT NULL`, name).Scan(&exists); err != nil {
+		t.Fatal(err)
+	}
+	return exists
+}
+
+func TestUpDownStatus(t *testing.T) {
+	db := testDB(t)
+	m := testMigrator(t, db)
+	ctx := context.Background()
+
+	if got := applied(t, m); len(got) != 0 {
+		t.Fatalf("applied before Up: %v", got)
+	}
+	done, err := m.Up(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(done) != 1 || done[0].Version != 1 || !tableExists(t, db, "widgets") || tableExists(t, db, "gadgets") {
+		t.Fatalf("Up(1) applied %v", done)
+	}
+	if done, err = m.Up(ctx, 0); err != nil {
+		t.Fatal(err)
+	}
+	if len(done) != 2 || !tableExists(t, db, "gadgets") {
+		t.Fatalf("Up(0) applied %v", done)
+	}
+	if got := applied(t, m); len(got) != 3 {
+		t.Fatalf("Status reports %v applied, want all 3", got)
+	}
+	if done, err = m.Up(ctx, 0); err != nil || len(done) != 0 {
+		t.Fatalf("second Up applied %v, %v", done, err)
+	}
+
+	if done, err = m.Down(ctx, 2); err != nil {
+		t.Fatal(err)
+	}
+	if len(done) != 2 || done[0].Version != 3 || done[1].Version != 2 || tableExists(t, db, "gadgets") {
+		t.Fatalf("Down(2) reverted %v", done)
+	}
+	if got := applied(t, m); len(got) != 1 || got[0] != 1 {
+		t.Fatalf("Status after Down reports %v applied", got)
+	}
+	if done, err = m.Down(ctx, 0); err != nil || len(done) != 1 || tableExists(t, db, "widgets") {
+		t.Fatalf("Down(0) reverted %v, %v", done, err)
+	}
+}
+
+func TestFailedMigrationIsRolledBack(t *testing.T) {
+	db := testDB(t)
+	m := testMigrator(t, db)
+	m.migrations = append(m.migrations[:1:1], Migration{
+		Version: 2, Name: "broken",
+		Up:   `CREATE TABLE half (id BIGINT); SELECT no_such_function()`,
+		Down: `DROP TABLE half`,
+	})
+	if _, err := m.Up(context.Background(), 0); err == nil {
+		t.Fatal("Up succeeded with a broken migration")
+	}
+	if tableExists(t, db, "half") {
+		t.Fatal("the broken migration was partly applied")
+	}
+	if got := applied(t, m); len(got) != 1 {
+		t.Fatalf("Status reports %v applied, want only the first", got)
+	}
+}
+
+func TestConcurrentUpAppliesOnce(t *testing.T) {
+	db := testDB(t)
+	const runners = 5
+	var wg sync.WaitGroup
+	errs := make([]error, runners)
+	counts := make([]int, runners)
+	for i := range runners {
+		m := testMigrator(t, db)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			done, err := m.Up(context.Background(), 0)
+			errs[i], counts[i] = err, len(done)
+		}()
+	}
+	wg.Wait()
+
+	total := 0
+	for i := range runners {
+		if errs[i] != nil {
+			t.Fatalf("runner %d: %v", i, errs[i])
+		}
+		total += counts[i]
+	}
+	if total != 3 {
+		t.Fatalf("runners applied %d migrations in total, want 3", total)
+	}
+	var runs int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM runs`).Scan(&runs); err != nil {
+		t.Fatal(err)
+	}
+	if runs != 1 {
+		t.Fatalf("migration 2 ran %d times", runs)
+	}
+}
+
+func TestUpWaitsForLock(t *testing.T) {
+	db := testDB(t)
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(context.Background(), `SELECT pg_advisory_lock($1)`, lockID); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	_, err = testMigrator(t, db).Up(ctx, 0)
+	if !errors.Is(err, context.DeadlineExceeded) && (err == nil || !strings.Contains(err.Error(), "migration lock")) {
+		t.Fatalf("Up while the lock is held: %v, want it to block until the deadline", err)
+	}
+	if _, err := conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, lockID); err != nil {
+		t.Fatal(err)
+	}
+	if tableExists(t, db, "widgets") {
+		t.Fatal("Up applied migrations without the lock")
+	}
+}
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS public.api_keys;
+DROP TABLE IF EXISTS public.processed_events;
+DROP TABLE IF EXISTS public.notification_rules;
+DROP TABLE IF EXISTS public.outbox;
+DROP TABLE IF EXISTS public.score_history;
+DROP TABLE IF EXISTS public.notifications;
+DROP TABLE IF EXISTS public.users;
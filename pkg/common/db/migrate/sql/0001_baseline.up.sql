@@ -0,0 +1,155 @@
+-- Baseline schema. Every statement is idempotent so databases created by the former
+-- db/initial.sql are adopted as they are.
+
+-- Users table
+CREATE TABLE IF NOT EXISTS public.users
+(
+    id         BIGSERIAL PRIMARY KEY,
+    name       VARCHAR(255) NOT NULL,
+    email      VARCHAR(255) NOT NULL,
+    score      BIGINT       NOT NULL DEFAULT 0,
+    deleted    BOOLEAN      NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+-- Helpful index if querying by score (e.g., leaderboards)
+CREATE INDEX IF NOT EXISTS idx_users_score ON public.users (score);
+
+-- Enforce unique emails only for non-deleted users
+ALTER TABLE public.users DROP CONSTRAINT IF EXISTS users_email_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email_active ON public.users (email) WHERE deleted = FALSE;
+
+-- Notifications table
+CREATE TABLE IF NOT EXISTS public.notifications
+(
+    id         BIGSERIAL PRIMARY KEY,
+    user_id    BIGINT      NOT NULL,
+    message    TEXT        NOT NULL,
+    channel    VARCHAR(32) NOT NULL DEFAULT 'in_app',
+    priority   VARCHAR(16) NOT NULL DEFAULT 'normal',
+    rule_id    BIGINT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_notifications_user
+        FOREIGN KEY (user_id)
+            REFERENCES public.users (id)
+            ON DELETE CASCADE
+);
+
+-- Indexes to accelerate common queries
+CREATE INDEX IF NOT EXISTS idx_notifications_user_id ON public.notifications (user_id);
+CREATE INDEX IF NOT EXISTS idx_notifications_created_at ON public.notifications (created_at);
+-- Efficient retrieval of latest notifications per user
+CREATE INDEX IF NOT EXISTS idx_notifications_user_created_at ON public.notifications (user_id, created_at DESC);
+
+-- Score history table (one row per applied score event)
+CREATE TABLE IF NOT EXISTS public.score_history
+(
+    id         BIGSERIAL PRIMARY KEY,
+    user_id    BIGINT      NOT NULL,
+    old_score  BIGINT      NOT NULL,
+    new_score  BIGINT      NOT NULL,
+    change     INT         NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_score_history_user
+        FOREIGN KEY (user_id)
+            REFERENCES public.users (id)
+            ON DELETE CASCADE
+);
+
+-- Time-range lookups of a user's score history
+CREATE INDEX IF NOT EXISTS idx_score_history_user_created_at ON public.score_history (user_id, created_at DESC);
+
+-- Transactional outbox for messages published by notification-service
+CREATE TABLE IF NOT EXISTS public.outbox
+(
+    id              BIGSERIAL PRIMARY KEY,
+    topic           VARCHAR(255) NOT NULL,
+    message_key     VARCHAR(255) NOT NULL,
+    payload         JSONB        NOT NULL,
+    status          VARCHAR(16)  NOT NULL DEFAULT 'pending',
+    attempts        INT          NOT NULL DEFAULT 0,
+    last_error      TEXT,
+    next_attempt_at TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    trace_parent    VARCHAR(64),
+    request_id      VARCHAR(128),
+    created_at      TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    sent_at         TIMESTAMPTZ
+);
+
+-- Relay polls pending rows that are due
+CREATE INDEX IF NOT EXISTS idx_outbox_pending ON public.outbox (topic, next_attempt_at) WHERE status = 'pending';
+
+-- Columns added to notifications after the initial release
+ALTER TABLE public.notifications ADD COLUMN IF NOT EXISTS channel VARCHAR(32) NOT NULL DEFAULT 'in_app';
+ALTER TABLE public.notifications ADD COLUMN IF NOT EXISTS priority VARCHAR(16) NOT NULL DEFAULT 'normal';
+ALTER TABLE public.notifications ADD COLUMN IF NOT EXISTS rule_id BIGINT;
+ALTER TABLE public.outbox ADD COLUMN IF NOT EXISTS trace_parent VARCHAR(64);
+ALTER TABLE public.outbox ADD COLUMN IF NOT EXISTS request_id VARCHAR(128);
+
+-- Notification rules evaluated against score events by notification-service
+CREATE TABLE IF NOT EXISTS public.notification_rules
+(
+    id          BIGSERIAL PRIMARY KEY,
+    name        VARCHAR(255) NOT NULL,
+    description TEXT,
+    enabled     BOOLEAN      NOT NULL DEFAULT TRUE,
+    position    INT          NOT NULL DEFAULT 0,
+    conditions  JSONB        NOT NULL DEFAULT '{}',
+    action      JSONB        NOT NULL,
+    created_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_rules_enabled_position ON public.notification_rules (position, id) WHERE enabled;
+
+-- Default rule set, seeded only into an empty table
+INSERT INTO public.notification_rules (name, description, position, conditions, action)
+SELECT v.name, v.description, v.position, v.conditions::jsonb, v.action::jsonb
+FROM (VALUES
+    ('score-increase', 'Score increased by more than 10 points', 10,
+     '{"min_change": 11}',
+     '{"template": "Congrats! Your score increased to {{.NewScore}} (+{{.Change}})", "channel": "email", "priority": "normal"}'),
+    ('score-drop', 'Score dropped by more than 10 points', 20,
+     '{"max_change": -11}',
+     '{"template": "Heads up: your score dropped to {{.NewScore}} ({{.Change}})", "channel": "email", "priority": "high"}'),
+    ('band-up', 'Score moved into a higher band', 30,
+     '{"band_direction": "up"}',
+     '{"template": "Your score moved from {{.OldBand}} to {{.NewBand}}", "channel": "in_app", "priority": "normal"}'),
+    ('band-down', 'Score moved into a lower band', 40,
+     '{"band_direction": "down"}',
+     '{"template": "Your score fell from {{.OldBand}} to {{.NewBand}}", "channel": "in_app", "priority": "high"}'),
+    ('reached-800', 'Score reached 800', 50,
+     '{"to_bands": ["exceptional"], "band_direction": "up"}',
+     '{"template": "Amazing! You reached an exceptional score of {{.NewScore}}", "channel": "email", "priority": "high"}')
+) AS v(name, description, position, conditions, action)
+WHERE NOT EXISTS (SELECT 1 FROM public.notification_rules);
+
+-- Idempotent score events: the client-supplied event_id applied by user-service
+ALTER TABLE public.score_history ADD COLUMN IF NOT EXISTS event_id VARCHAR(128);
+CREATE UNIQUE INDEX IF NOT EXISTS uq_score_history_user_event_id ON public.score_history (user_id, event_id) WHERE event_id IS NOT NULL AND event_id <> '';
+
+-- Score events already turned into notifications by notification-service
+CREATE TABLE IF NOT EXISTS public.processed_events
+(
+    event_id     VARCHAR(128) PRIMARY KEY,
+    processed_at TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_processed_events_processed_at ON public.processed_events (processed_at);
+
+-- API keys issued to service accounts; only a SHA-256 hash of each key is stored
+CREATE TABLE IF NOT EXISTS public.api_keys
+(
+    id           BIGSERIAL PRIMARY KEY,
+    prefix       VARCHAR(32)  NOT NULL UNIQUE,
+    secret_hash  VARCHAR(64)  NOT NULL,
+    owner        VARCHAR(255) NOT NULL,
+    scopes       JSONB        NOT NULL DEFAULT '[]',
+    expires_at   TIMESTAMPTZ,
+    last_used_at TIMESTAMPTZ,
+    revoked_at   TIMESTAMPTZ,
+    created_at   TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_owner ON public.api_keys (owner);
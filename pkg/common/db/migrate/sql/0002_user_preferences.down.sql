@@ -0,0 +1 @@
+ALTER TABLE public.users DROP COLUMN IF EXISTS preferences;
@@ -0,0 +1,2 @@
+-- Notification settings controlled by each user
+ALTER TABLE public.users ADD COLUMN IF NOT EXISTS preferences JSONB NOT NULL DEFAULT '{}';
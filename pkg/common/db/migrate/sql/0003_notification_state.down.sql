@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS public.idx_notifications_user_unread;
+ALTER TABLE public.notifications DROP COLUMN IF EXISTS archived_at;
+ALTER TABLE public.notifications DROP COLUMN IF EXISTS read_at;
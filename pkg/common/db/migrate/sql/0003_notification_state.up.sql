@@ -0,0 +1,7 @@
+-- Read and archive state for the notification inbox
+ALTER TABLE public.notifications ADD COLUMN IF NOT EXISTS read_at TIMESTAMPTZ;
+ALTER TABLE public.notifications ADD COLUMN IF NOT EXISTS archived_at TIMESTAMPTZ;
+
+-- Unread counts and mark-all-read only touch unread rows
+CREATE INDEX IF NOT EXISTS idx_notifications_user_unread ON public.notifications (user_id)
+    WHERE read_at IS NULL AND archived_at IS NULL;
@@ -0,0 +1 @@
+ALTER TABLE public.notifications DROP COLUMN IF EXISTS type;
@@ -0,0 +1,4 @@
+-- Score event type that triggered each notification, or 'manual' for ones created through the API
+ALTER TABLE public.notifications ADD COLUMN IF NOT EXISTS type TEXT NOT NULL DEFAULT 'manual';
+-- Rule-created notifications predate event types other than the default
+UPDATE public.notifications SET type = 'score_update' WHERE rule_id IS NOT NULL AND type = 'manual';
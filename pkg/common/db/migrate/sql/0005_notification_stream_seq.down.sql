@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS public.uq_notifications_user_stream_seq;
+ALTER TABLE public.notifications DROP COLUMN IF EXISTS stream_seq;
+DROP TABLE IF EXISTS public.notification_streams;
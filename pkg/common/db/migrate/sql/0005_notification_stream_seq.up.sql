@@ -0,0 +1,26 @@
+-- Per-user position of each notification in commit order, for streaming. Transactions
+-- creating notifications for a user take the next positions from notification_streams,
+-- whose row lock they hold until commit, so a lower position never commits later.
+CREATE TABLE IF NOT EXISTS public.notification_streams
+(
+    user_id BIGINT PRIMARY KEY,
+    seq     BIGINT NOT NULL DEFAULT 0
+);
+
+-- Left NULL by replicas that predate this migration; such rows are listed but not streamed
+ALTER TABLE public.notifications ADD COLUMN IF NOT EXISTS stream_seq BIGINT;
+
+UPDATE public.notifications n
+SET stream_seq = s.seq
+FROM (SELECT id, ROW_NUMBER() OVER (PARTITION BY user_id ORDER BY id) AS seq
+      FROM public.notifications) s
+WHERE n.id = s.id
+  AND n.stream_seq IS NULL;
+
+INSERT INTO public.notification_streams (user_id, seq)
+SELECT user_id, MAX(stream_seq)
+FROM public.notifications
+GROUP BY user_id
+ON CONFLICT (user_id) DO UPDATE SET seq = GREATEST(notification_streams.seq, EXCLUDED.seq);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_notifications_user_stream_seq ON public.notifications (user_id, stream_seq);
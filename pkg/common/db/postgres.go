@@ -1,24 +1,28 @@
 package db
 
 import (
+	"context"
+	"crypto/tls"
 	"fmt"
+	"strings"
 
+	"github.com/emorenkov/scorehub/pkg/common/db/migrate"
+	"github.com/emorenkov/scorehub/pkg/common/metrics"
 	"github.com/emorenkov/scorehub/pkg/common/models"
+	"github.com/emorenkov/scorehub/pkg/common/tlsx"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
 
 func NewPostgresDB(cfg *models.PostgresConfig) (*gorm.DB, error) {
-	dsn := fmt.Sprintf(
-		"host=%s port=%d user=%s password=%s dbname=%s sslmode=disable",
-		cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.DB,
-	)
-
-	dialector := postgres.New(postgres.Config{
-		DSN:                  dsn,
-		PreferSimpleProtocol: true, // avoid cached prepared statements when schema changes at runtime
-	})
+	connCfg, err := connConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	dialector := postgres.New(postgres.Config{Conn: stdlib.OpenDB(*connCfg)})
 
 	db, err := gorm.Open(dialector, &gorm.Config{
 		Logger: logger.Default.LogMode(logger.Info),
@@ -26,6 +30,71 @@ func NewPostgresDB(cfg *models.PostgresConfig) (*gorm.DB, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
+	if err := db.Use(metrics.GormPlugin{}); err != nil {
+		return nil, fmt.Errorf("register db metrics: %w", err)
+	}
+	if cfg.AutoMigrate {
+		if err := autoMigrate(db); err != nil {
+			return nil, fmt.Errorf("migrate database: %w", err)
+		}
+	}
 
 	return db, nil
 }
+
+// connConfig parses cfg into a pgx configuration. The client certificate is left out of
+// the DSN, which pgx would read only once, and is served from a tlsx reloader instead.
+func connConfig(cfg *models.PostgresConfig) (*pgx.ConnConfig, error) {
+	dsn := fmt.Sprintf("port=%d", cfg.Port)
+	for _, opt := range [][2]string{
+		{"host", cfg.Host}, {"user", cfg.User}, {"password", cfg.Password}, {"dbname", cfg.DB},
+		{"sslmode", cfg.SSLMode}, {"sslrootcert", cfg.SSLRootCert},
+	} {
+		if opt[1] != "" {
+			dsn += fmt.Sprintf(" %s=%s", opt[0], quoteDSN(opt[1]))
+		}
+	}
+	connCfg, err := pgx.ParseConfig(dsn)
+	if err != nil {
+		return nil, err
+	}
+	// avoid cached prepared statements when schema changes at runtime
+	connCfg.DefaultQueryExecMode = pgx.QueryExecModeSimpleProtocol
+
+	if cfg.SSLCert == "" {
+		return connCfg, nil
+	}
+	clientTLS, err := tlsx.Client(&models.TLSConfig{CertFile: cfg.SSLCert, KeyFile: cfg.SSLKey})
+	if err != nil {
+		return nil, fmt.Errorf("postgres client certificate: %w", err)
+	}
+	configs := []*tls.Config{connCfg.TLSConfig}
+	for _, fb := range connCfg.Fallbacks {
+		configs = append(configs, fb.TLSConfig)
+	}
+	for _, c := range configs {
+		if c != nil {
+			c.GetClientCertificate = clientTLS.GetClientCertificate
+		}
+	}
+	return connCfg, nil
+}
+
+// quoteDSN quotes v as a keyword/value connection string value, escaping backslashes
+// and single quotes as libpq does.
+func quoteDSN(v string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(v) + "'"
+}
+
+func autoMigrate(db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	m, err := migrate.New(sqlDB)
+	if err != nil {
+		return err
+	}
+	_, err = m.Up(context.Background(), 0)
+	return err
+}
@@ -0,0 +1,34 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/emorenkov/scorehub/pkg/common/models"
+)
+
+func TestConnConfigQuotesValues(t *testing.T) {
+	cfg := &models.PostgresConfig{
+		Host: "db", Port: 5432, User: "app", DB: "score hub", SSLMode: "disable",
+		Password: `p'a\ss w\'ord\`,
+	}
+	connCfg, err := connConfig(cfg)
+	if err != nil {
+		t.Fatalf("connConfig: %v", err)
+	}
+	if connCfg.Password != cfg.Password {
+		t.Fatalf("password = %q, want %q", connCfg.Password, cfg.Password)
+	}
+	if connCfg.Database != cfg.DB || connCfg.User != cfg.User || connCfg.Host != cfg.Host {
+		t.Fatalf("unexpected config %+v", connCfg.Config)
+	}
+	if connCfg.TLSConfig != nil {
+		t.Fatal("TLS configured with sslmode=disable")
+	}
+}
+
+func TestConnConfigRequiresClientKey(t *testing.T) {
+	cfg := &models.PostgresConfig{Host: "db", Port: 5432, SSLMode: "require", SSLCert: "/missing/client.crt"}
+	if _, err := connConfig(cfg); err == nil {
+		t.Fatal("expected an error for a certificate without a key")
+	}
+}
@@ -0,0 +1,254 @@
+package dlq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	ckafka "github.com/emorenkov/scorehub/pkg/common/kafka"
+	"github.com/emorenkov/scorehub/pkg/common/metrics"
+	"github.com/segmentio/kafka-go"
+)
+
+// ErrNotFound is returned when no dead-lettered message exists at the requested position.
+var ErrNotFound = errors.New("dead-letter message not found")
+
+// HeaderRedrivenFrom marks a re-driven message with its "<dlq topic>/<partition>/<offset>" origin.
+const HeaderRedrivenFrom = "x-dlq-redriven-from"
+
+// Message is a dead-lettered message together with its decoded failure metadata.
+type Message struct {
+	Topic             string            `json:"topic"`
+	Partition         int               `json:"partition"`
+	Offset            int64             `json:"offset"`
+	Key               string            `json:"key,omitempty"`
+	Value             string            `json:"value"`
+	Time              time.Time         `json:"time"`
+	OriginalTopic     string            `json:"original_topic"`
+	OriginalPartition int               `json:"original_partition"`
+	OriginalOffset    int64             `json:"original_offset"`
+	Error             string            `json:"error"`
+	Attempts          int               `json:"attempts"`
+	FailedAt          string            `json:"failed_at,omitempty"`
+	ConsumerGroup     string            `json:"consumer_group,omitempty"`
+	Headers           map[string]string `json:"headers,omitempty"`
+}
+
+// Page is a slice of a dead-letter partition.
+type Page struct {
+	Messages   []Message `json:"messages"`
+	NextOffset int64     `json:"next_offset"`
+	HighOffset int64     `json:"high_offset"`
+}
+
+// Admin reads dead-letter topics and re-drives their messages. Reads use
+// partition readers without a consumer group, so they never move offsets.
+type Admin struct {
+	brokers     []string
+	writer      *kafka.Writer
+	readTimeout time.Duration
+}
+
+func NewAdmin(brokers []string) *Admin {
+	return &Admin{
+		brokers: brokers,
+		writer: &kafka.Writer{
+			Addr:     kafka.TCP(brokers...),
+			Balancer: &kafka.Hash{},
+		},
+		readTimeout: 5 * time.Second,
+	}
+}
+
+// List returns up to limit messages from the dead-letter topic of topic,
+// starting at offset in the given partition.
+func (a *Admin) List(ctx context.Context, topic string, partition int, offset int64, limit int) (*Page, error) {
+	dlqTopic := Topic(topic)
+	first, high, err := a.offsets(ctx, dlqTopic, partition)
+	if err != nil {
+		return nil, err
+	}
+	if offset < first {
+		offset = first
+	}
+	page := &Page{Messages: []Message{}, NextOffset: offset, HighOffset: high}
+	if offset >= high || limit <= 0 {
+		return page, nil
+	}
+
+	r := a.reader(dlqTopic, partition)
+	defer r.Close()
+	if err := r.SetOffset(offset); err != nil {
+		return nil, fmt.Errorf("seek %s/%d to %d: %w", dlqTopic, partition, offset, err)
+	}
+
+	for len(page.Messages) < limit && page.NextOffset < high {
+		msg, err := a.read(ctx, r)
+		if err != nil {
+			return nil, fmt.Errorf("read %s/%d: %w", dlqTopic, partition, err)
+		}
+		page.Messages = append(page.Messages, decode(msg))
+		page.NextOffset = msg.Offset + 1
+	}
+	return page, nil
+}
+
+// Get returns the dead-lettered message at partition/offset of topic's dead-letter topic.
+func (a *Admin) Get(ctx context.Context, topic string, partition int, offset int64) (*Message, error) {
+	msg, err := a.fetch(ctx, Topic(topic), partition, offset)
+	if err != nil {
+		return nil, err
+	}
+	m := decode(msg)
+	return &m, nil
+}
+
+// Redrive publishes the dead-lettered message at partition/offset back onto its
+// original topic with its original key, value and headers. It is targeted at the
+// consumer group that dead-lettered it (kafka.HeaderTargetGroup), so other groups
+// consuming the topic, which handled it already, skip it. The DLQ copy stays in place;
+// Kafka topics are append-only.
+func (a *Admin) Redrive(ctx context.Context, topic string, partition int, offset int64) (*Message, error) {
+	dlqTopic := Topic(topic)
+	msg, err := a.fetch(ctx, dlqTopic, partition, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	target := headerValue(msg.Headers, HeaderOriginalTopic)
+	if target == "" {
+		target = topic
+	}
+	err = a.writer.WriteMessages(ctx, kafka.Message{
+		Topic:   target,
+		Key:     msg.Key,
+		Value:   msg.Value,
+		Headers: redriveHeaders(msg, partition, offset),
+	})
+	metrics.ObserveProduced(target, 1, err)
+	if err != nil {
+		return nil, fmt.Errorf("redrive to %s: %w", target, err)
+	}
+	m := decode(msg)
+	return &m, nil
+}
+
+// redriveHeaders returns the headers for re-driving msg, read from partition/offset of
+// its dead-letter topic: the original headers, its origin and its target group.
+func redriveHeaders(msg kafka.Message, partition int, offset int64) []kafka.Header {
+	headers := make([]kafka.Header, 0, len(msg.Headers)+2)
+	for _, h := range msg.Headers {
+		if !isDLQHeader(h.Key) && h.Key != HeaderRedrivenFrom && h.Key != ckafka.HeaderTargetGroup {
+			headers = append(headers, h)
+		}
+	}
+	headers = append(headers, kafka.Header{
+		Key:   HeaderRedrivenFrom,
+		Value: []byte(msg.Topic + "/" + strconv.Itoa(partition) + "/" + strconv.FormatInt(offset, 10)),
+	})
+	// Messages dead-lettered before the group was recorded go to every group.
+	if group := headerValue(msg.Headers, HeaderConsumerGroup); group != "" {
+		headers = append(headers, kafka.Header{Key: ckafka.HeaderTargetGroup, Value: []byte(group)})
+	}
+	return headers
+}
+
+func (a *Admin) Close() error {
+	return a.writer.Close()
+}
+
+func (a *Admin) fetch(ctx context.Context, dlqTopic string, partition int, offset int64) (kafka.Message, error) {
+	first, high, err := a.offsets(ctx, dlqTopic, partition)
+	if err != nil {
+		return kafka.Message{}, err
+	}
+	if offset < first || offset >= high {
+		return kafka.Message{}, ErrNotFound
+	}
+
+	r := a.reader(dlqTopic, partition)
+	defer r.Close()
+	if err := r.SetOffset(offset); err != nil {
+		return kafka.Message{}, fmt.Errorf("seek %s/%d to %d: %w", dlqTopic, partition, offset, err)
+	}
+	msg, err := a.read(ctx, r)
+	if err != nil {
+		return kafka.Message{}, fmt.Errorf("read %s/%d: %w", dlqTopic, partition, err)
+	}
+	if msg.Offset != offset {
+		return kafka.Message{}, ErrNotFound
+	}
+	return msg, nil
+}
+
+func (a *Admin) offsets(ctx context.Context, dlqTopic string, partition int) (first, high int64, err error) {
+	if len(a.brokers) == 0 {
+		return 0, 0, errors.New("no kafka brokers configured")
+	}
+	conn, err := kafka.DialLeader(ctx, "tcp", a.brokers[0], dlqTopic, partition)
+	if err != nil {
+		return 0, 0, fmt.Errorf("dial leader for %s/%d: %w", dlqTopic, partition, err)
+	}
+	defer conn.Close()
+	first, high, err = conn.ReadOffsets()
+	if err != nil {
+		return 0, 0, fmt.Errorf("read offsets for %s/%d: %w", dlqTopic, partition, err)
+	}
+	return first, high, nil
+}
+
+func (a *Admin) reader(dlqTopic string, partition int) *kafka.Reader {
+	return kafka.NewReader(kafka.ReaderConfig{
+		Brokers:   a.brokers,
+		Topic:     dlqTopic,
+		Partition: partition,
+		MinBytes:  1,
+		MaxBytes:  10e6, // 10MB
+	})
+}
+
+func (a *Admin) read(ctx context.Context, r *kafka.Reader) (kafka.Message, error) {
+	ctx, cancel := context.WithTimeout(ctx, a.readTimeout)
+	defer cancel()
+	return r.ReadMessage(ctx)
+}
+
+func decode(msg kafka.Message) Message {
+	m := Message{
+		Topic:         msg.Topic,
+		Partition:     msg.Partition,
+		Offset:        msg.Offset,
+		Key:           string(msg.Key),
+		Value:         string(msg.Value),
+		Time:          msg.Time,
+		OriginalTopic: headerValue(msg.Headers, HeaderOriginalTopic),
+		Error:         headerValue(msg.Headers, HeaderError),
+		FailedAt:      headerValue(msg.Headers, HeaderFailedAt),
+		ConsumerGroup: headerValue(msg.Headers, HeaderConsumerGroup),
+	}
+	m.OriginalPartition, _ = strconv.Atoi(headerValue(msg.Headers, HeaderOriginalPartition))
+	m.OriginalOffset, _ = strconv.ParseInt(headerValue(msg.Headers, HeaderOriginalOffset), 10, 64)
+	m.Attempts, _ = strconv.Atoi(headerValue(msg.Headers, HeaderAttempts))
+
+	for _, h := range msg.Headers {
+		if isDLQHeader(h.Key) {
+			continue
+		}
+		if m.Headers == nil {
+			m.Headers = make(map[string]string)
+		}
+		m.Headers[h.Key] = string(h.Value)
+	}
+	return m
+}
+
+func headerValue(headers []kafka.Header, key string) string {
+	for i := len(headers) - 1; i >= 0; i-- {
+		if headers[i].Key == key {
+			return string(headers[i].Value)
+		}
+	}
+	return ""
+}
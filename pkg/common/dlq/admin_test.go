@@ -0,0 +1,47 @@
+package dlq
+
+import (
+	"testing"
+
+	ckafka "github.com/emorenkov/scorehub/pkg/common/kafka"
+	"github.com/segmentio/kafka-go"
+)
+
+func TestRedriveHeadersTargetFailedGroup(t *testing.T) {
+	msg := kafka.Message{
+		Topic: "score_events.dlq",
+		Headers: []kafka.Header{
+			{Key: "x-request-id", Value: []byte("req-1")},
+			{Key: ckafka.HeaderTargetGroup, Value: []byte("stale-group")},
+			{Key: HeaderOriginalTopic, Value: []byte("score_events")},
+			{Key: HeaderError, Value: []byte("boom")},
+			{Key: HeaderConsumerGroup, Value: []byte("notification-service")},
+		},
+	}
+	got := map[string][]string{}
+	for _, h := range redriveHeaders(msg, 2, 42) {
+		got[h.Key] = append(got[h.Key], string(h.Value))
+	}
+	want := map[string]string{
+		"x-request-id":           "req-1",
+		HeaderRedrivenFrom:       "score_events.dlq/2/42",
+		ckafka.HeaderTargetGroup: "notification-service",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("headers = %v, want %v", got, want)
+	}
+	for k, v := range want {
+		if len(got[k]) != 1 || got[k][0] != v {
+			t.Errorf("%s = %v, want %q", k, got[k], v)
+		}
+	}
+}
+
+func TestRedriveHeadersWithoutGroup(t *testing.T) {
+	msg := kafka.Message{Topic: "score_events.dlq", Headers: []kafka.Header{{Key: HeaderOriginalTopic, Value: []byte("score_events")}}}
+	for _, h := range redriveHeaders(msg, 0, 1) {
+		if h.Key == ckafka.HeaderTargetGroup {
+			t.Fatalf("untagged message was targeted at %q", h.Value)
+		}
+	}
+}
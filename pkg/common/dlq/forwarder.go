@@ -0,0 +1,111 @@
+package dlq
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	ckafka "github.com/emorenkov/scorehub/pkg/common/kafka"
+	logpkg "github.com/emorenkov/scorehub/pkg/common/logger"
+	"github.com/emorenkov/scorehub/pkg/common/metrics"
+	"github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+)
+
+// Headers attached to dead-lettered messages.
+const (
+	HeaderOriginalTopic     = "x-dlq-original-topic"
+	HeaderOriginalPartition = "x-dlq-original-partition"
+	HeaderOriginalOffset    = "x-dlq-original-offset"
+	HeaderError             = "x-dlq-error"
+	HeaderAttempts          = "x-dlq-attempts"
+	HeaderFailedAt          = "x-dlq-failed-at"
+	HeaderConsumerGroup     = "x-dlq-consumer-group"
+)
+
+// Topic returns the dead-letter topic for a source topic.
+func Topic(topic string) string {
+	return topic + ".dlq"
+}
+
+// Forwarder publishes messages that could not be processed to their dead-letter topic.
+type Forwarder struct {
+	writer  *kafka.Writer
+	groupID string
+}
+
+// NewForwarder builds a forwarder for the consumer group groupID, which is recorded on
+// every dead-lettered message since several groups may share a source topic.
+func NewForwarder(brokers []string, groupID string) *Forwarder {
+	return &Forwarder{
+		writer: &kafka.Writer{
+			Addr:                   kafka.TCP(brokers...),
+			Balancer:               &kafka.Hash{},
+			AllowAutoTopicCreation: true,
+		},
+		groupID: groupID,
+	}
+}
+
+// Forward writes msg to Topic(msg.Topic), keeping its key, value and headers and
+// adding the DLQ headers describing the failure.
+func (f *Forwarder) Forward(ctx context.Context, msg kafka.Message, cause error, attempts int) error {
+	headers := make([]kafka.Header, 0, len(msg.Headers)+7)
+	for _, h := range msg.Headers {
+		if !isDLQHeader(h.Key) {
+			headers = append(headers, h)
+		}
+	}
+	headers = append(headers,
+		kafka.Header{Key: HeaderOriginalTopic, Value: []byte(msg.Topic)},
+		kafka.Header{Key: HeaderOriginalPartition, Value: []byte(strconv.Itoa(msg.Partition))},
+		kafka.Header{Key: HeaderOriginalOffset, Value: []byte(strconv.FormatInt(msg.Offset, 10))},
+		kafka.Header{Key: HeaderError, Value: []byte(cause.Error())},
+		kafka.Header{Key: HeaderAttempts, Value: []byte(strconv.Itoa(attempts))},
+		kafka.Header{Key: HeaderFailedAt, Value: []byte(time.Now().UTC().Format(time.RFC3339))},
+		kafka.Header{Key: HeaderConsumerGroup, Value: []byte(f.groupID)},
+	)
+
+	err := f.writer.WriteMessages(ctx, kafka.Message{
+		Topic:   Topic(msg.Topic),
+		Key:     msg.Key,
+		Value:   msg.Value,
+		Headers: headers,
+	})
+	metrics.ObserveProduced(Topic(msg.Topic), 1, err)
+	return err
+}
+
+// FailureHandler returns a consumer failure handler that dead-letters the message.
+// If forwarding fails the consumer stops without committing, so nothing is lost.
+func (f *Forwarder) FailureHandler() ckafka.FailureHandler {
+	return func(ctx context.Context, msg kafka.Message, err error, attempts int) error {
+		if fwdErr := f.Forward(ctx, msg, err, attempts); fwdErr != nil {
+			return fmt.Errorf("forward to %s: %w", Topic(msg.Topic), fwdErr)
+		}
+		logpkg.FromContext(ctx).Warn("message moved to dead-letter topic",
+			zap.Error(err),
+			zap.String("topic", msg.Topic),
+			zap.String("dlq_topic", Topic(msg.Topic)),
+			zap.String("group_id", f.groupID),
+			zap.Int("partition", msg.Partition),
+			zap.Int64("offset", msg.Offset),
+			zap.Int("attempts", attempts),
+		)
+		return nil
+	}
+}
+
+func (f *Forwarder) Close() error {
+	return f.writer.Close()
+}
+
+func isDLQHeader(key string) bool {
+	switch key {
+	case HeaderOriginalTopic, HeaderOriginalPartition, HeaderOriginalOffset, HeaderError, HeaderAttempts, HeaderFailedAt, HeaderConsumerGroup:
+		return true
+	default:
+		return false
+	}
+}
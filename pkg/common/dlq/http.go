@@ -0,0 +1,134 @@
+package dlq
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	logpkg "github.com/emorenkov/scorehub/pkg/common/logger"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultListLimit = 20
+	maxListLimit     = 500
+)
+
+// Handler exposes Admin over REST for the source topics a service consumes.
+type Handler struct {
+	admin  *Admin
+	topics map[string]struct{}
+}
+
+func NewHandler(admin *Admin, topics []string) *Handler {
+	allowed := make(map[string]struct{}, len(topics))
+	for _, t := range topics {
+		allowed[t] = struct{}{}
+	}
+	return &Handler{admin: admin, topics: allowed}
+}
+
+// Register mounts the admin routes on g; callers are expected to protect g.
+func (h *Handler) Register(g *echo.Group) {
+	g.GET("/admin/dlq/:topic/messages", h.list)
+	g.GET("/admin/dlq/:topic/messages/:partition/:offset", h.get)
+	g.POST("/admin/dlq/:topic/messages/:partition/:offset/redrive", h.redrive)
+}
+
+func (h *Handler) list(c echo.Context) error {
+	log := logpkg.FromContext(c.Request().Context())
+	topic, ok := h.topic(c)
+	if !ok {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "unknown topic"})
+	}
+	partition, err := intQuery(c, "partition", 0)
+	if err != nil || partition < 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid partition"})
+	}
+	offset, err := intQuery(c, "offset", 0)
+	if err != nil || offset < 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid offset"})
+	}
+	limit, err := intQuery(c, "limit", defaultListLimit)
+	if err != nil || limit <= 0 || limit > maxListLimit {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid limit"})
+	}
+
+	page, err := h.admin.List(c.Request().Context(), topic, int(partition), offset, int(limit))
+	if err != nil {
+		log.Error("listDeadLetters failed", zap.Error(err), zap.String("topic", topic), zap.Int64("partition", partition))
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, page)
+}
+
+func (h *Handler) get(c echo.Context) error {
+	log := logpkg.FromContext(c.Request().Context())
+	topic, partition, offset, ok := h.position(c)
+	if !ok {
+		return nil
+	}
+	msg, err := h.admin.Get(c.Request().Context(), topic, partition, offset)
+	if err != nil {
+		log.Error("getDeadLetter failed", zap.Error(err), zap.String("topic", topic), zap.Int("partition", partition), zap.Int64("offset", offset))
+		return writeError(c, err)
+	}
+	return c.JSON(http.StatusOK, msg)
+}
+
+func (h *Handler) redrive(c echo.Context) error {
+	log := logpkg.FromContext(c.Request().Context())
+	topic, partition, offset, ok := h.position(c)
+	if !ok {
+		return nil
+	}
+	msg, err := h.admin.Redrive(c.Request().Context(), topic, partition, offset)
+	if err != nil {
+		log.Error("redriveDeadLetter failed", zap.Error(err), zap.String("topic", topic), zap.Int("partition", partition), zap.Int64("offset", offset))
+		return writeError(c, err)
+	}
+	log.Info("redriveDeadLetter succeeded", zap.String("topic", msg.OriginalTopic), zap.Int("partition", partition), zap.Int64("offset", offset))
+	return c.JSON(http.StatusAccepted, msg)
+}
+
+func (h *Handler) topic(c echo.Context) (string, bool) {
+	topic := c.Param("topic")
+	_, ok := h.topics[topic]
+	return topic, ok
+}
+
+// position parses topic/partition/offset path params, writing a response when they are invalid.
+func (h *Handler) position(c echo.Context) (string, int, int64, bool) {
+	topic, ok := h.topic(c)
+	if !ok {
+		_ = c.JSON(http.StatusNotFound, map[string]string{"error": "unknown topic"})
+		return "", 0, 0, false
+	}
+	partition, err := strconv.Atoi(c.Param("partition"))
+	if err != nil || partition < 0 {
+		_ = c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid partition"})
+		return "", 0, 0, false
+	}
+	offset, err := strconv.ParseInt(c.Param("offset"), 10, 64)
+	if err != nil || offset < 0 {
+		_ = c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid offset"})
+		return "", 0, 0, false
+	}
+	return topic, partition, offset, true
+}
+
+func intQuery(c echo.Context, name string, def int64) (int64, error) {
+	v := c.QueryParam(name)
+	if v == "" {
+		return def, nil
+	}
+	return strconv.ParseInt(v, 10, 64)
+}
+
+func writeError(c echo.Context, err error) error {
+	if errors.Is(err, ErrNotFound) {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+}
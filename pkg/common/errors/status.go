@@ -41,3 +41,9 @@ func AsStatusError(err error) (*StatusError, bool) {
 	}
 	return nil, false
 }
+
+// IsClientError reports whether err carries a 4xx status, i.e. retrying it cannot succeed.
+func IsClientError(err error) bool {
+	se, ok := AsStatusError(err)
+	return ok && se.Status >= 400 && se.Status < 500
+}
@@ -0,0 +1,148 @@
+package gateway
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"strings"
+
+	"github.com/emorenkov/scorehub/pkg/common/grpcx"
+	"github.com/emorenkov/scorehub/pkg/common/requestid"
+	"github.com/emorenkov/scorehub/pkg/common/tracing"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// Prefix is the path every google.api.http binding in our protos starts with.
+const Prefix = "/api/v1"
+
+// RegisterFunc matches the generated Register<Service>HandlerFromEndpoint functions.
+type RegisterFunc func(ctx context.Context, mux *runtime.ServeMux, endpoint string, opts []grpc.DialOption) error
+
+// Config configures New.
+type Config struct {
+	GRPCAddr string
+	// GRPCTLS secures the connection to GRPCAddr; nil dials in plaintext.
+	GRPCTLS *tls.Config
+	// MaxBodyBytes caps request bodies; 0 leaves them unbounded.
+	MaxBodyBytes int64
+	// Options are applied after the defaults, e.g. to add marshalers for other media types.
+	Options []runtime.ServeMuxOption
+}
+
+// Handler is the grpc-gateway REST facade of a service, generated from the google.api.http
+// annotations and proxying to its gRPC endpoint. It is mounted on the service's Echo
+// server, which keeps only the routes that have no RPC. It does not authenticate requests
+// itself: it forwards the credential headers, and the gRPC server authenticates,
+// scope-checks and rate limits each call.
+type Handler struct {
+	mux     *runtime.ServeMux
+	maxBody int64
+	cancel  context.CancelFunc
+}
+
+type echoContextKey struct{}
+
+// New registers the generated handlers against cfg.GRPCAddr.
+func New(cfg Config, register ...RegisterFunc) (*Handler, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	muxOpts := append([]runtime.ServeMuxOption{
+		runtime.WithIncomingHeaderMatcher(headerMatcher),
+		runtime.WithOutgoingHeaderMatcher(outgoingHeaderMatcher),
+		runtime.WithMarshalerOption(runtime.MIMEWildcard, JSONMarshaler()),
+		runtime.WithMiddlewares(routeMiddleware),
+	}, cfg.Options...)
+	mux := runtime.NewServeMux(muxOpts...)
+
+	opts := []grpc.DialOption{
+		grpc.WithTransportCredentials(grpcx.TransportCredentials(cfg.GRPCTLS)),
+		grpc.WithChainUnaryInterceptor(tracing.UnaryClientInterceptor()),
+		grpc.WithChainStreamInterceptor(tracing.StreamClientInterceptor()),
+	}
+	for _, r := range register {
+		// The generated functions close their connection when ctx is cancelled.
+		if err := r(ctx, mux, cfg.GRPCAddr, opts); err != nil {
+			cancel()
+			return nil, err
+		}
+	}
+	return &Handler{mux: mux, maxBody: cfg.MaxBodyBytes, cancel: cancel}, nil
+}
+
+// JSONMarshaler returns the marshaler the gateway uses for JSON: proto field names,
+// zero values included and unknown request fields ignored.
+func JSONMarshaler() *runtime.JSONPb {
+	return &runtime.JSONPb{
+		MarshalOptions: protojson.MarshalOptions{
+			UseProtoNames:   true,
+			EmitUnpopulated: true,
+		},
+		UnmarshalOptions: protojson.UnmarshalOptions{
+			DiscardUnknown: true,
+		},
+	}
+}
+
+// Register mounts h on every path under Prefix. Routes registered on e explicitly take
+// precedence, so the non-RPC routes (streams, rules, dead letters, API keys) keep working
+// next to it. The Echo middleware on e, request IDs, tracing and metrics, applies to
+// gateway requests as well.
+func (h *Handler) Register(e *echo.Echo) {
+	e.Any(Prefix+"/*", h.serve)
+}
+
+// Close releases the connections to the gRPC endpoint.
+func (h *Handler) Close() {
+	h.cancel()
+}
+
+func (h *Handler) serve(c echo.Context) error {
+	r := c.Request()
+	if h.maxBody > 0 {
+		r.Body = http.MaxBytesReader(c.Response(), r.Body, h.maxBody)
+	}
+	h.mux.ServeHTTP(c.Response(), r.WithContext(context.WithValue(r.Context(), echoContextKey{}, c)))
+	return nil
+}
+
+// routeMiddleware replaces the /api/v1/* catch-all as the route of the Echo context and the
+// server span with the matched binding, e.g. /api/v1/users/{id}, so metrics and traces are
+// labelled per RPC.
+func routeMiddleware(next runtime.HandlerFunc) runtime.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request, params map[string]string) {
+		if pattern, ok := runtime.HTTPPattern(r.Context()); ok {
+			route := strings.ReplaceAll(pattern.String(), "=*}", "}")
+			if c, ok := r.Context().Value(echoContextKey{}).(echo.Context); ok {
+				c.SetPath(route)
+			}
+			span := trace.SpanFromContext(r.Context())
+			span.SetName(r.Method + " " + route)
+			span.SetAttributes(attribute.String("http.route", route))
+		}
+		next(w, r, params)
+	}
+}
+
+// headerMatcher forwards the headers our gRPC servers understand in addition to the gateway defaults.
+func headerMatcher(key string) (string, bool) {
+	switch strings.ToLower(key) {
+	case "x-api-key", requestid.Key, "authorization", "idempotency-key":
+		return strings.ToLower(key), true
+	default:
+		return runtime.DefaultHeaderMatcher(key)
+	}
+}
+
+// outgoingHeaderMatcher passes the rate limit headers set by the gRPC servers through
+// unchanged and keeps the Grpc-Metadata- prefix for everything else.
+func outgoingHeaderMatcher(key string) (string, bool) {
+	if strings.HasPrefix(key, "ratelimit-") || key == "retry-after" {
+		return key, true
+	}
+	return runtime.MetadataHeaderPrefix + key, true
+}
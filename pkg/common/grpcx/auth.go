@@ -0,0 +1,135 @@
+package grpcx
+
+import (
+	"context"
+	"strings"
+
+	"github.com/emorenkov/scorehub/pkg/common/auth"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Metadata keys carrying credentials; the gateway forwards both from HTTP headers.
+const (
+	APIKeyMetadata        = "x-api-key"
+	AuthorizationMetadata = "authorization"
+)
+
+// healthService is left open so probes work without credentials.
+const healthService = "/grpc.health.v1.Health/"
+
+// ErrNoCredentials is returned by an Authenticator when the call carries none of the
+// credentials it understands.
+var ErrNoCredentials = auth.ErrNoCredentials
+
+// Authenticator validates the credentials in incoming metadata. It may return a derived
+// context, e.g. carrying the caller's identity.
+type Authenticator interface {
+	Authenticate(ctx context.Context, md metadata.MD) (context.Context, error)
+}
+
+// AuthenticatorFunc adapts a function to Authenticator.
+type AuthenticatorFunc func(ctx context.Context, md metadata.MD) (context.Context, error)
+
+func (f AuthenticatorFunc) Authenticate(ctx context.Context, md metadata.MD) (context.Context, error) {
+	return f(ctx, md)
+}
+
+// Auth adapts a to Authenticator, reading x-api-key and authorization metadata and
+// storing the resulting auth.Principal in the context. A nil a yields a nil Authenticator.
+func Auth(a *auth.Authenticator) Authenticator {
+	if !a.Enabled() {
+		return nil
+	}
+	return AuthenticatorFunc(func(ctx context.Context, md metadata.MD) (context.Context, error) {
+		p, err := a.Authenticate(ctx, first(md, APIKeyMetadata), first(md, AuthorizationMetadata))
+		if err != nil {
+			return ctx, err
+		}
+		return auth.NewContext(ctx, p), nil
+	})
+}
+
+// UnaryServerAuth rejects calls that authn does not accept with codes.Unauthenticated.
+// A nil authn disables authentication.
+func UnaryServerAuth(authn Authenticator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := authenticate(ctx, authn, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerAuth is the streaming counterpart of UnaryServerAuth.
+func StreamServerAuth(authn Authenticator) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(ss.Context(), authn, info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func authenticate(ctx context.Context, authn Authenticator, method string) (context.Context, error) {
+	if authn == nil || strings.HasPrefix(method, healthService) {
+		return ctx, nil
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	authCtx, err := authn.Authenticate(ctx, md)
+	if err != nil {
+		return ctx, status.Error(codes.Unauthenticated, err.Error())
+	}
+	return authCtx, nil
+}
+
+// UnaryServerScopes rejects calls whose caller lacks the scopes scopes lists for the
+// method with codes.PermissionDenied. Methods missing from scopes need none.
+func UnaryServerScopes(scopes map[string][]string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := auth.RequireScopes(ctx, scopes[info.FullMethod]...); err != nil {
+			return nil, status.Error(codes.PermissionDenied, err.Error())
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerScopes is the streaming counterpart of UnaryServerScopes.
+func StreamServerScopes(scopes map[string][]string) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := auth.RequireScopes(ss.Context(), scopes[info.FullMethod]...); err != nil {
+			return status.Error(codes.PermissionDenied, err.Error())
+		}
+		return handler(srv, ss)
+	}
+}
+
+// UnaryClientAPIKey attaches key as x-api-key to outgoing calls; an empty key sends nothing.
+func UnaryClientAPIKey(key string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if key != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, APIKeyMetadata, key)
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+func first(md metadata.MD, key string) string {
+	if vals := md.Get(key); len(vals) > 0 {
+		return vals[0]
+	}
+	return ""
+}
+
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,78 @@
+package grpcx
+
+import (
+	"context"
+	"time"
+
+	logpkg "github.com/emorenkov/scorehub/pkg/common/logger"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryServerLogging writes one access log line per call.
+func UnaryServerLogging() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		logCall(ctx, "grpc call handled", info.FullMethod, start, err, peerField(ctx))
+		return resp, err
+	}
+}
+
+// StreamServerLogging writes one access log line per stream once it completes.
+func StreamServerLogging() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		logCall(ss.Context(), "grpc stream handled", info.FullMethod, start, err, peerField(ss.Context()))
+		return err
+	}
+}
+
+// UnaryClientLogging writes one log line per outgoing call.
+func UnaryClientLogging() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		logCall(ctx, "grpc call sent", method, start, err, zap.String("target", cc.Target()))
+		return err
+	}
+}
+
+func logCall(ctx context.Context, msg, method string, start time.Time, err error, extra zap.Field) {
+	code := status.Code(err)
+	fields := []zap.Field{
+		zap.String("method", method),
+		zap.String("code", code.String()),
+		zap.Duration("duration", time.Since(start)),
+		extra,
+	}
+	log := logpkg.FromContext(ctx)
+	if isServerError(code) {
+		log.Error(msg, append(fields, zap.Error(err))...)
+		return
+	}
+	if err != nil {
+		fields = append(fields, zap.String("error", status.Convert(err).Message()))
+	}
+	log.Info(msg, fields...)
+}
+
+func isServerError(code codes.Code) bool {
+	switch code {
+	case codes.Unknown, codes.Internal, codes.DataLoss, codes.Unimplemented, codes.Unavailable, codes.DeadlineExceeded:
+		return true
+	default:
+		return false
+	}
+}
+
+func peerField(ctx context.Context) zap.Field {
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		return zap.String("peer", p.Addr.String())
+	}
+	return zap.Skip()
+}
@@ -0,0 +1,103 @@
+package grpcx
+
+import (
+	"context"
+	"net"
+	"strings"
+
+	"github.com/emorenkov/scorehub/pkg/common/auth"
+	"github.com/emorenkov/scorehub/pkg/common/ratelimit"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryServerRateLimit rejects calls over the policies of stage in l with
+// codes.ResourceExhausted and reports the quota in ratelimit-* and retry-after header
+// metadata, which the gateway passes on as HTTP headers. Place a BeforeAuth interceptor
+// before authentication and an AfterAuth one after it; the AfterAuth one reports the
+// combined quota. A nil l disables limiting.
+func UnaryServerRateLimit(l *ratelimit.Limiter, stage ratelimit.Stage) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := rateLimit(ctx, l, stage, info.FullMethod, func(md metadata.MD) error {
+			return grpc.SetHeader(ctx, md)
+		})
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerRateLimit is the streaming counterpart of UnaryServerRateLimit; a stream
+// counts as one request.
+func StreamServerRateLimit(l *ratelimit.Limiter, stage ratelimit.Stage) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := rateLimit(ss.Context(), l, stage, info.FullMethod, ss.SetHeader)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func rateLimit(ctx context.Context, l *ratelimit.Limiter, stage ratelimit.Stage, method string, setHeader func(metadata.MD) error) (context.Context, error) {
+	if l == nil || strings.HasPrefix(method, healthService) {
+		return ctx, nil
+	}
+	ctx, res := l.Check(ctx, stage, &ratelimit.Request{
+		IP:        clientIP(ctx),
+		Route:     method,
+		Principal: auth.FromContext(ctx),
+	})
+	if res == nil || (res.Allowed && stage == ratelimit.BeforeAuth) {
+		return ctx, nil
+	}
+	md := metadata.MD{}
+	for k, v := range res.Headers() {
+		md.Set(k, v...)
+	}
+	_ = setHeader(md)
+	if !res.Allowed {
+		return ctx, status.Error(codes.ResourceExhausted, "rate limit exceeded")
+	}
+	return ctx, nil
+}
+
+// clientIP returns the peer address, or for calls from a loopback peer such as the
+// in-process gateway, the last x-forwarded-for entry. The gateway passes on whatever
+// X-Forwarded-For the HTTP client sent and appends the client's address last, so only
+// that entry cannot be chosen by the caller.
+func clientIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		host = p.Addr.String()
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		md, _ := metadata.FromIncomingContext(ctx)
+		if fwd := lastForwarded(md); fwd != "" {
+			return fwd
+		}
+	}
+	return host
+}
+
+// lastForwarded returns the last entry across every x-forwarded-for value in md.
+func lastForwarded(md metadata.MD) string {
+	vals := md.Get("x-forwarded-for")
+	for i := len(vals) - 1; i >= 0; i-- {
+		entries := strings.Split(vals[i], ",")
+		for j := len(entries) - 1; j >= 0; j-- {
+			if e := strings.TrimSpace(entries[j]); e != "" {
+				return e
+			}
+		}
+	}
+	return ""
+}
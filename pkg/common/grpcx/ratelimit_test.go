@@ -0,0 +1,39 @@
+package grpcx
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+func TestClientIP(t *testing.T) {
+	loopback := &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 40000}
+	remote := &net.TCPAddr{IP: net.ParseIP("203.0.113.9"), Port: 40000}
+	tests := []struct {
+		name string
+		addr net.Addr
+		xff  []string
+		want string
+	}{
+		{"direct peer", remote, nil, "203.0.113.9"},
+		{"direct peer ignores forwarded", remote, []string{"198.51.100.1"}, "203.0.113.9"},
+		{"gateway without forwarded", loopback, nil, "127.0.0.1"},
+		{"gateway appended address", loopback, []string{"198.51.100.7"}, "198.51.100.7"},
+		{"spoofed entries are ignored", loopback, []string{"1.2.3.4, 5.6.7.8, 198.51.100.7"}, "198.51.100.7"},
+		{"several values", loopback, []string{"1.2.3.4", "198.51.100.7"}, "198.51.100.7"},
+	}
+	for _, tt := range tests {
+		ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: tt.addr})
+		if tt.xff != nil {
+			md := metadata.MD{}
+			md.Append("x-forwarded-for", tt.xff...)
+			ctx = metadata.NewIncomingContext(ctx, md)
+		}
+		if got := clientIP(ctx); got != tt.want {
+			t.Errorf("%s: clientIP = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
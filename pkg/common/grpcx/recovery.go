@@ -0,0 +1,48 @@
+// Package grpcx holds the interceptor chain shared by every gRPC server and client:
+// panic recovery, access logging, authentication and default deadlines.
+package grpcx
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+
+	logpkg "github.com/emorenkov/scorehub/pkg/common/logger"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryServerRecovery turns a handler panic into codes.Internal instead of crashing the process.
+func UnaryServerRecovery() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				err = recovered(ctx, info.FullMethod, p)
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerRecovery is the streaming counterpart of UnaryServerRecovery.
+func StreamServerRecovery() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				err = recovered(ss.Context(), info.FullMethod, p)
+			}
+		}()
+		return handler(srv, ss)
+	}
+}
+
+func recovered(ctx context.Context, method string, p any) error {
+	logpkg.FromContext(ctx).Error("grpc handler panicked",
+		zap.String("method", method),
+		zap.String("panic", fmt.Sprint(p)),
+		zap.ByteString("stack", debug.Stack()),
+	)
+	return status.Error(codes.Internal, "internal error")
+}
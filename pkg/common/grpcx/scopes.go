@@ -0,0 +1,32 @@
+package grpcx
+
+import (
+	"testing"
+
+	"google.golang.org/grpc"
+)
+
+// CheckScopes fails t when an RPC of desc has no entry in scopes, or scopes lists a method
+// desc does not have. Services call it from a test of their MethodScopes: the gateway
+// serves every RPC with an HTTP binding, so a method missing from the map would be open to
+// any authenticated caller there.
+func CheckScopes(t testing.TB, desc grpc.ServiceDesc, scopes map[string][]string) {
+	t.Helper()
+	methods := map[string]bool{}
+	for _, m := range desc.Methods {
+		methods["/"+desc.ServiceName+"/"+m.MethodName] = true
+	}
+	for _, s := range desc.Streams {
+		methods["/"+desc.ServiceName+"/"+s.StreamName] = true
+	}
+	for m := range methods {
+		if len(scopes[m]) == 0 {
+			t.Errorf("%s has no scopes", m)
+		}
+	}
+	for m := range scopes {
+		if !methods[m] {
+			t.Errorf("scopes list unknown method %s", m)
+		}
+	}
+}
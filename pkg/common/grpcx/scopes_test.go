@@ -0,0 +1,54 @@
+package grpcx
+
+import (
+	"fmt"
+	"testing"
+
+	"google.golang.org/grpc"
+)
+
+// errorRecorder collects the failures CheckScopes reports.
+type errorRecorder struct {
+	testing.TB
+	errs []string
+}
+
+func (r *errorRecorder) Helper() {}
+
+func (r *errorRecorder) Errorf(format string, args ...any) {
+	r.errs = append(r.errs, fmt.Sprintf(format, args...))
+}
+
+func TestCheckScopes(t *testing.T) {
+	desc := grpc.ServiceDesc{
+		ServiceName: "test.Service",
+		Methods:     []grpc.MethodDesc{{MethodName: "Get"}, {MethodName: "Put"}},
+		Streams:     []grpc.StreamDesc{{StreamName: "Watch"}},
+	}
+	tests := []struct {
+		name   string
+		scopes map[string][]string
+		want   int
+	}{
+		{name: "complete", scopes: map[string][]string{
+			"/test.Service/Get": {"read"}, "/test.Service/Put": {"write"}, "/test.Service/Watch": {"read"},
+		}},
+		{name: "missing method and stream", scopes: map[string][]string{"/test.Service/Get": {"read"}}, want: 2},
+		{name: "empty scopes", scopes: map[string][]string{
+			"/test.Service/Get": {}, "/test.Service/Put": {"write"}, "/test.Service/Watch": {"read"},
+		}, want: 1},
+		{name: "unknown method", scopes: map[string][]string{
+			"/test.Service/Get": {"read"}, "/test.Service/Put": {"write"}, "/test.Service/Watch": {"read"},
+			"/test.Service/Delete": {"write"},
+		}, want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := &errorRecorder{TB: t}
+			CheckScopes(rec, desc, tt.scopes)
+			if len(rec.errs) != tt.want {
+				t.Fatalf("got errors %q, want %d", rec.errs, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package health
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// GRPCServer is the standard grpc.health.v1 service kept in sync with a Registry.
+type GRPCServer struct {
+	srv      *grpchealth.Server
+	registry *Registry
+	services []string
+}
+
+// NewGRPCServer registers grpc.health.v1 on s. The overall ("") status and each of
+// services follow the registry's readiness result.
+func NewGRPCServer(s *grpc.Server, registry *Registry, services ...string) *GRPCServer {
+	srv := grpchealth.NewServer()
+	healthpb.RegisterHealthServer(s, srv)
+	return &GRPCServer{srv: srv, registry: registry, services: services}
+}
+
+// Run re-evaluates the registry every interval until ctx is cancelled.
+func (g *GRPCServer) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		g.update(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (g *GRPCServer) update(ctx context.Context) {
+	status := healthpb.HealthCheckResponse_SERVING
+	if g.registry.Run(ctx).Status != StatusOK {
+		status = healthpb.HealthCheckResponse_NOT_SERVING
+	}
+	g.srv.SetServingStatus("", status)
+	for _, svc := range g.services {
+		g.srv.SetServingStatus(svc, status)
+	}
+}
+
+// Shutdown reports NOT_SERVING for every service so clients drain before the server stops.
+func (g *GRPCServer) Shutdown() {
+	g.srv.Shutdown()
+}
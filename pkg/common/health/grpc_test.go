@@ -0,0 +1,45 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestGRPCServerFollowsRegistry(t *testing.T) {
+	var failing atomic.Bool
+	r := NewRegistry(0)
+	r.Add("postgres", func(context.Context) error {
+		if failing.Load() {
+			return errors.New("down")
+		}
+		return nil
+	})
+	g := NewGRPCServer(grpc.NewServer(), r, "scorehub.Test")
+	ctx := context.Background()
+
+	expect := func(want healthpb.HealthCheckResponse_ServingStatus) {
+		t.Helper()
+		for _, svc := range []string{"", "scorehub.Test"} {
+			resp, err := g.srv.Check(ctx, &healthpb.HealthCheckRequest{Service: svc})
+			if err != nil || resp.GetStatus() != want {
+				t.Fatalf("service %q: %v, %v, want %v", svc, resp.GetStatus(), err, want)
+			}
+		}
+	}
+	g.update(ctx)
+	expect(healthpb.HealthCheckResponse_SERVING)
+	failing.Store(true)
+	g.update(ctx)
+	expect(healthpb.HealthCheckResponse_NOT_SERVING)
+	failing.Store(false)
+	g.update(ctx)
+	expect(healthpb.HealthCheckResponse_SERVING)
+
+	g.Shutdown()
+	expect(healthpb.HealthCheckResponse_NOT_SERVING)
+}
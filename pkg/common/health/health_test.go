@@ -0,0 +1,115 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+func blocking(ctx context.Context) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestRunTimesOutEachCheck(t *testing.T) {
+	r := NewRegistry(50 * time.Millisecond)
+	r.Add("slow", blocking)
+	r.Add("slower", blocking)
+	r.Add("fast", func(context.Context) error { return nil })
+
+	start := time.Now()
+	report := r.Run(context.Background())
+	// The checks run concurrently, so two timeouts take about one.
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("Run took %v", elapsed)
+	}
+	if report.Status != StatusFail {
+		t.Fatalf("status %q, want fail", report.Status)
+	}
+	for _, name := range []string{"slow", "slower"} {
+		res := report.Checks[name]
+		if res.Status != StatusFail || res.Error != context.DeadlineExceeded.Error() || res.DurationMS < 50 {
+			t.Fatalf("%s: %+v, want a failure after the 50ms timeout", name, res)
+		}
+	}
+	if res := report.Checks["fast"]; res.Status != StatusOK || res.Error != "" {
+		t.Fatalf("fast: %+v", res)
+	}
+}
+
+func TestNewRegistryDefaultTimeout(t *testing.T) {
+	if r := NewRegistry(0); r.timeout != defaultTimeout {
+		t.Fatalf("timeout %v, want %v", r.timeout, defaultTimeout)
+	}
+}
+
+func TestAddReplacesCheck(t *testing.T) {
+	r := NewRegistry(0)
+	r.Add("redis", func(context.Context) error { return errors.New("down") })
+	r.Add("postgres", func(context.Context) error { return nil })
+	r.Add("redis", func(context.Context) error { return nil })
+	if got := r.Names(); !slices.Equal(got, []string{"postgres", "redis"}) {
+		t.Fatalf("names %v", got)
+	}
+	if report := r.Run(context.Background()); report.Status != StatusOK {
+		t.Fatalf("report %+v, want the replacement check", report)
+	}
+}
+
+func serve(r *Registry, path string) *httptest.ResponseRecorder {
+	e := echo.New()
+	r.Register(e)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	return rec
+}
+
+func TestReadyzReportsEachCheck(t *testing.T) {
+	r := NewRegistry(0)
+	r.Add("kafka", func(context.Context) error { return nil })
+	r.Add("postgres", func(context.Context) error { return errors.New("connection refused") })
+
+	for _, path := range []string{"/readyz", "/_health"} {
+		rec := serve(r, path)
+		if rec.Code != http.StatusServiceUnavailable {
+			t.Fatalf("%s: status %d, want 503", path, rec.Code)
+		}
+		var report Report
+		if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+			t.Fatal(err)
+		}
+		if report.Status != StatusFail || len(report.Checks) != 2 ||
+			report.Checks["kafka"].Status != StatusOK ||
+			report.Checks["postgres"].Status != StatusFail || report.Checks["postgres"].Error != "connection refused" {
+			t.Fatalf("%s: report %s", path, rec.Body)
+		}
+	}
+}
+
+func TestReadyzOK(t *testing.T) {
+	r := NewRegistry(0)
+	r.Add("kafka", func(context.Context) error { return nil })
+	rec := serve(r, "/readyz")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d, want 200: %s", rec.Code, rec.Body)
+	}
+	var report Report
+	if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil || report.Status != StatusOK {
+		t.Fatalf("report %s, %v", rec.Body, err)
+	}
+}
+
+func TestLivezIgnoresChecks(t *testing.T) {
+	r := NewRegistry(0)
+	r.Add("postgres", func(context.Context) error { return errors.New("down") })
+	if rec := serve(r, "/livez"); rec.Code != http.StatusOK {
+		t.Fatalf("status %d, want 200", rec.Code)
+	}
+}
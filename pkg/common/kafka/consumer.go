@@ -2,17 +2,34 @@ package kafka
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
 
+	apperrors "github.com/emorenkov/scorehub/pkg/common/errors"
+	"github.com/emorenkov/scorehub/pkg/common/logger"
+	"github.com/emorenkov/scorehub/pkg/common/metrics"
+	"github.com/emorenkov/scorehub/pkg/common/models"
+	"github.com/emorenkov/scorehub/pkg/common/requestid"
+	"github.com/emorenkov/scorehub/pkg/common/tracing"
 	"github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
 )
 
+// HeaderTargetGroup restricts a message to one consumer group: Run in any other group
+// commits it without calling the handler. Dead-letter re-drives set it, since every group
+// consuming a topic would otherwise handle the re-driven message again.
+const HeaderTargetGroup = "x-target-consumer-group"
+
 type Consumer struct {
-	reader *kafka.Reader
+	reader  *kafka.Reader
+	groupID string
 }
 
 // NewConsumerWithBrokers constructs a consumer with explicit brokers, topic, and groupID.
 func NewConsumerWithBrokers(brokers []string, topic, groupID string) *Consumer {
 	return &Consumer{
+		groupID: groupID,
 		reader: kafka.NewReader(kafka.ReaderConfig{
 			Brokers:  brokers,
 			Topic:    topic,
@@ -29,10 +46,210 @@ func NewConsumerWithBrokers(brokers []string, topic, groupID string) *Consumer {
 // 	return NewConsumerWithBrokers(cfg.KafkaBrokers, topic, groupID)
 // }
 
+// ReadMessage reads the next message and commits its offset immediately (at-most-once).
+// Prefer Run, which commits only after the handler succeeds.
 func (c *Consumer) ReadMessage(ctx context.Context) (kafka.Message, error) {
 	return c.reader.ReadMessage(ctx)
 }
 
+// FetchMessage reads the next message without committing its offset.
+func (c *Consumer) FetchMessage(ctx context.Context) (kafka.Message, error) {
+	return c.reader.FetchMessage(ctx)
+}
+
+// CommitMessages commits the offsets of the given messages for the consumer group.
+func (c *Consumer) CommitMessages(ctx context.Context, msgs ...kafka.Message) error {
+	return c.reader.CommitMessages(ctx, msgs...)
+}
+
 func (c *Consumer) Close() error {
 	return c.reader.Close()
 }
+
+// Handler processes a single message. Returning a permanent error (see IsPermanent)
+// skips the remaining retries.
+type Handler func(ctx context.Context, msg kafka.Message) error
+
+// FailureHandler is called when a message fails permanently or exhausts its retries.
+// Returning nil commits the message and moves on; returning an error stops Run
+// without committing, so the message is redelivered after a restart.
+type FailureHandler func(ctx context.Context, msg kafka.Message, err error, attempts int) error
+
+// RetryPolicy controls how transient handler errors are retried.
+type RetryPolicy struct {
+	// MaxAttempts caps handler invocations per message; non-positive values select
+	// defaultMaxAttempts, so a message that keeps failing always reaches the FailureHandler.
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+}
+
+// defaultMaxAttempts matches the CONSUMER_MAX_ATTEMPTS default.
+const defaultMaxAttempts = 5
+
+// RetryPolicyFromConfig builds a RetryPolicy from environment-driven settings; nil and
+// unset fields yield defaults.
+func RetryPolicyFromConfig(cfg *models.ConsumerRetryConfig) RetryPolicy {
+	if cfg == nil {
+		return RetryPolicy{}.withDefaults()
+	}
+	return RetryPolicy{
+		MaxAttempts:    cfg.MaxAttempts,
+		InitialBackoff: cfg.InitialBackoff,
+		MaxBackoff:     cfg.MaxBackoff,
+		Multiplier:     cfg.Multiplier,
+	}.withDefaults()
+}
+
+// RunOptions configures Run.
+type RunOptions struct {
+	Retry     RetryPolicy
+	OnFailure FailureHandler
+}
+
+// Run consumes messages with at-least-once semantics: each message is fetched, handled
+// (with retries and exponential backoff for transient errors) and only then committed.
+// Permanent errors and exhausted retries go to opts.OnFailure. Messages targeted at
+// another consumer group (HeaderTargetGroup) are committed without being handled.
+//
+// Each message is handled under a consumer span that continues the trace carried in its
+// headers, with the request ID from its headers (or a new one) in the context so handler
+// logs can use logger.FromContext. When ctx is cancelled Run returns nil without
+// committing the in-flight message.
+func (c *Consumer) Run(ctx context.Context, handle Handler, opts RunOptions) error {
+	policy := opts.Retry.withDefaults()
+
+	for {
+		msg, err := c.reader.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("fetch message: %w", err)
+		}
+		metrics.SetConsumerLag(msg.Topic, c.groupID, msg.Partition, msg.Offset, msg.HighWaterMark)
+
+		if c.targetsOtherGroup(msg) {
+			metrics.ObserveConsumed(msg.Topic, c.groupID, metrics.ConsumeSkipped)
+		} else {
+			msgCtx, span := tracing.StartConsumerSpan(requestid.FromKafka(ctx, msg), msg, c.groupID)
+			err = c.handle(msgCtx, msg, handle, policy, opts.OnFailure)
+			tracing.End(span, err)
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				return err
+			}
+		}
+
+		if err := c.reader.CommitMessages(ctx, msg); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("commit offset %d on partition %d: %w", msg.Offset, msg.Partition, err)
+		}
+	}
+}
+
+// targetsOtherGroup reports whether msg carries a HeaderTargetGroup naming another group.
+func (c *Consumer) targetsOtherGroup(msg kafka.Message) bool {
+	for i := len(msg.Headers) - 1; i >= 0; i-- {
+		if msg.Headers[i].Key == HeaderTargetGroup {
+			return string(msg.Headers[i].Value) != c.groupID
+		}
+	}
+	return false
+}
+
+func (c *Consumer) handle(ctx context.Context, msg kafka.Message, handle Handler, policy RetryPolicy, onFailure FailureHandler) error {
+	for attempt := 1; ; attempt++ {
+		err := handle(ctx, msg)
+		if err == nil {
+			metrics.ObserveConsumed(msg.Topic, c.groupID, metrics.ConsumeSuccess)
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if IsPermanent(err) || attempt >= policy.MaxAttempts {
+			metrics.ObserveConsumed(msg.Topic, c.groupID, metrics.ConsumeFailed)
+			if onFailure != nil {
+				return onFailure(ctx, msg, err, attempt)
+			}
+			return fmt.Errorf("handle message at offset %d on partition %d: %w", msg.Offset, msg.Partition, err)
+		}
+
+		metrics.ObserveConsumed(msg.Topic, c.groupID, metrics.ConsumeRetry)
+		delay := policy.backoff(attempt)
+		logger.FromContext(ctx).Warn("kafka handler failed, retrying",
+			zap.Error(err),
+			zap.String("topic", msg.Topic),
+			zap.Int("partition", msg.Partition),
+			zap.Int64("offset", msg.Offset),
+			zap.Int("attempt", attempt),
+			zap.Duration("backoff", delay),
+		)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = defaultMaxAttempts
+	}
+	if p.InitialBackoff <= 0 {
+		p.InitialBackoff = 200 * time.Millisecond
+	}
+	if p.MaxBackoff < p.InitialBackoff {
+		p.MaxBackoff = 30 * time.Second
+	}
+	if p.Multiplier < 1 {
+		p.Multiplier = 2
+	}
+	return p
+}
+
+// backoff returns the delay before the next attempt after the given (1-based) attempt.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	d := float64(p.InitialBackoff)
+	for i := 1; i < attempt; i++ {
+		d *= p.Multiplier
+		if d >= float64(p.MaxBackoff) {
+			return p.MaxBackoff
+		}
+	}
+	return time.Duration(d)
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marks err as non-retryable for Run.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent reports whether err was marked with Permanent or is a client error (a 4xx
+// StatusError, e.g. an unknown user): the message itself is at fault, so retrying it
+// cannot succeed.
+func IsPermanent(err error) bool {
+	var pe *permanentError
+	return errors.As(err, &pe) || apperrors.IsClientError(err)
+}
@@ -0,0 +1,124 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	apperrors "github.com/emorenkov/scorehub/pkg/common/errors"
+	"github.com/emorenkov/scorehub/pkg/common/models"
+	"github.com/segmentio/kafka-go"
+)
+
+func TestRetryPolicyFromConfigDefaults(t *testing.T) {
+	for name, cfg := range map[string]*models.ConsumerRetryConfig{
+		"nil":          nil,
+		"zero":         {},
+		"negative max": {MaxAttempts: -1, InitialBackoff: time.Second, MaxBackoff: time.Minute, Multiplier: 3},
+	} {
+		p := RetryPolicyFromConfig(cfg)
+		if p.MaxAttempts != defaultMaxAttempts {
+			t.Errorf("%s: MaxAttempts = %d, want %d", name, p.MaxAttempts, defaultMaxAttempts)
+		}
+		if p.InitialBackoff <= 0 || p.MaxBackoff < p.InitialBackoff || p.Multiplier < 1 {
+			t.Errorf("%s: invalid backoff %+v", name, p)
+		}
+	}
+
+	p := RetryPolicyFromConfig(&models.ConsumerRetryConfig{MaxAttempts: 3, InitialBackoff: time.Second, MaxBackoff: time.Minute, Multiplier: 3})
+	if p.MaxAttempts != 3 || p.InitialBackoff != time.Second || p.MaxBackoff != time.Minute || p.Multiplier != 3 {
+		t.Errorf("configured policy changed: %+v", p)
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	p := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second, Multiplier: 2}.withDefaults()
+	want := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second}
+	for i, w := range want {
+		if got := p.backoff(i + 1); got != w {
+			t.Errorf("backoff(%d) = %v, want %v", i+1, got, w)
+		}
+	}
+}
+
+func TestTargetsOtherGroup(t *testing.T) {
+	c := &Consumer{groupID: "notification-service"}
+	tests := []struct {
+		name    string
+		headers []kafka.Header
+		want    bool
+	}{
+		{"untargeted", nil, false},
+		{"this group", []kafka.Header{{Key: HeaderTargetGroup, Value: []byte("notification-service")}}, false},
+		{"other group", []kafka.Header{{Key: HeaderTargetGroup, Value: []byte("user-service")}}, true},
+	}
+	for _, tt := range tests {
+		if got := c.targetsOtherGroup(kafka.Message{Headers: tt.headers}); got != tt.want {
+			t.Errorf("%s: targetsOtherGroup = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestIsPermanent(t *testing.T) {
+	for name, tc := range map[string]struct {
+		err  error
+		want bool
+	}{
+		"marked":       {Permanent(errors.New("bad payload")), true},
+		"wrapped mark": {fmt.Errorf("handle: %w", Permanent(errors.New("bad payload"))), true},
+		"client error": {apperrors.NewStatusError(http.StatusNotFound, "user not found"), true},
+		"server error": {apperrors.NewStatusError(http.StatusServiceUnavailable, "look up user"), false},
+		"plain":        {errors.New("connection reset"), false},
+	} {
+		if got := IsPermanent(tc.err); got != tc.want {
+			t.Errorf("%s: IsPermanent = %v, want %v", name, got, tc.want)
+		}
+	}
+}
+
+func TestHandleFailurePolicy(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond, Multiplier: 1}
+	for name, tc := range map[string]struct {
+		err      error
+		attempts int
+	}{
+		"permanent":    {Permanent(errors.New("bad payload")), 1},
+		"client error": {apperrors.NewStatusError(http.StatusNotFound, "user not found"), 1},
+		"transient":    {errors.New("connection reset"), 3},
+	} {
+		calls := 0
+		var failed error
+		var failedAttempts int
+		onFailure := func(_ context.Context, _ kafka.Message, err error, attempts int) error {
+			failed, failedAttempts = err, attempts
+			return nil
+		}
+		c := &Consumer{groupID: "test"}
+		err := c.handle(context.Background(), kafka.Message{Topic: "t"}, func(context.Context, kafka.Message) error {
+			calls++
+			return tc.err
+		}, policy, onFailure)
+		if err != nil {
+			t.Errorf("%s: handle = %v, want the failure handled", name, err)
+		}
+		if calls != tc.attempts || failedAttempts != tc.attempts || !errors.Is(failed, tc.err) {
+			t.Errorf("%s: %d calls, failure handler got %v after %d attempts", name, calls, failed, failedAttempts)
+		}
+	}
+}
+
+func TestHandleStopsWhenFailureHandlerFails(t *testing.T) {
+	c := &Consumer{groupID: "test"}
+	forwardErr := errors.New("dlq unavailable")
+	err := c.handle(context.Background(), kafka.Message{}, func(context.Context, kafka.Message) error {
+		return Permanent(errors.New("bad payload"))
+	}, RetryPolicy{}.withDefaults(), func(context.Context, kafka.Message, error, int) error {
+		return forwardErr
+	})
+	if !errors.Is(err, forwardErr) {
+		t.Fatalf("handle = %v, want the failure handler's error so the message is not committed", err)
+	}
+}
@@ -3,7 +3,12 @@ package kafka
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"time"
 
+	"github.com/emorenkov/scorehub/pkg/common/metrics"
+	"github.com/emorenkov/scorehub/pkg/common/requestid"
+	"github.com/emorenkov/scorehub/pkg/common/tracing"
 	"github.com/segmentio/kafka-go"
 )
 
@@ -11,13 +16,19 @@ type Producer struct {
 	writer *kafka.Writer
 }
 
+// batchTimeout bounds how long a write waits for more messages to fill a batch. Writes
+// are synchronous and callers pass everything they have in one call, so waiting the
+// kafka-go default of one second would only add latency.
+const batchTimeout = 10 * time.Millisecond
+
 // NewProducerWithBrokers constructs a producer with explicit broker list and topic.
 func NewProducerWithBrokers(brokers []string, topic string) *Producer {
 	return &Producer{
 		writer: &kafka.Writer{
-			Addr:     kafka.TCP(brokers...),
-			Topic:    topic,
-			Balancer: &kafka.LeastBytes{},
+			Addr:         kafka.TCP(brokers...),
+			Topic:        topic,
+			Balancer:     &kafka.LeastBytes{},
+			BatchTimeout: batchTimeout,
 		},
 	}
 }
@@ -28,16 +39,76 @@ func NewProducerWithBrokers(brokers []string, topic string) *Producer {
 // 	return NewProducerWithBrokers(cfg.KafkaBrokers, topic)
 // }
 
+// SendMessage JSON-encodes value and writes it under key, carrying the trace context and
+// request ID of ctx in the message headers.
 func (p *Producer) SendMessage(ctx context.Context, key string, value interface{}) error {
 	valueBytes, err := json.Marshal(value)
 	if err != nil {
 		return err
 	}
 
-	return p.writer.WriteMessages(ctx, kafka.Message{
+	ctx, span := tracing.StartProducerSpan(ctx, p.writer.Topic, 1)
+	msg := kafka.Message{
 		Key:   []byte(key),
 		Value: valueBytes,
-	})
+	}
+	tracing.InjectKafka(ctx, &msg.Headers)
+	requestid.InjectKafka(ctx, &msg.Headers)
+
+	err = p.writer.WriteMessages(ctx, msg)
+	metrics.ObserveProduced(p.writer.Topic, 1, err)
+	tracing.End(span, err)
+	return err
+}
+
+// Message is a keyed value for SendMessages; Value is JSON-encoded.
+type Message struct {
+	Key   string
+	Value any
+	// Context, when set, supplies the trace context and request ID headers of this
+	// message in place of the batch's, for messages sent on behalf of other requests.
+	Context context.Context
+}
+
+// SendMessages JSON-encodes and writes msgs in a single WriteMessages call. When only
+// some messages fail the error is a kafka.WriteErrors aligned with msgs.
+func (p *Producer) SendMessages(ctx context.Context, msgs []Message) error {
+	ctx, span := tracing.StartProducerSpan(ctx, p.writer.Topic, len(msgs))
+	batch := make([]kafka.Message, 0, len(msgs))
+	for _, m := range msgs {
+		valueBytes, err := json.Marshal(m.Value)
+		if err != nil {
+			tracing.End(span, err)
+			return err
+		}
+		msg := kafka.Message{
+			Key:   []byte(m.Key),
+			Value: valueBytes,
+		}
+		msgCtx := ctx
+		if m.Context != nil {
+			msgCtx = m.Context
+		}
+		tracing.InjectKafka(msgCtx, &msg.Headers)
+		requestid.InjectKafka(msgCtx, &msg.Headers)
+		batch = append(batch, msg)
+	}
+	err := p.writer.WriteMessages(ctx, batch...)
+	observeBatch(p.writer.Topic, len(batch), err)
+	tracing.End(span, err)
+	return err
+}
+
+// observeBatch counts a batch write, splitting partial failures reported as kafka.WriteErrors.
+func observeBatch(topic string, n int, err error) {
+	var writeErrs kafka.WriteErrors
+	if !errors.As(err, &writeErrs) {
+		metrics.ObserveProduced(topic, n, err)
+		return
+	}
+	failed := writeErrs.Count()
+	metrics.ObserveProduced(topic, n-failed, nil)
+	metrics.ObserveProduced(topic, failed, err)
 }
 
 func (p *Producer) Close() error {
@@ -1,6 +1,10 @@
 package logger
 
 import (
+	"context"
+
+	"github.com/emorenkov/scorehub/pkg/common/requestid"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
@@ -30,3 +34,27 @@ func Sync() {
 		_ = Log.Sync()
 	}
 }
+
+// FromContext returns Log annotated with the request ID and trace of ctx, so every line
+// logged while handling one request or event can be correlated.
+func FromContext(ctx context.Context) *zap.Logger {
+	l := Log
+	if l == nil {
+		l = zap.NewNop()
+	}
+	if ctx == nil {
+		return l
+	}
+
+	fields := make([]zap.Field, 0, 3)
+	if id := requestid.FromContext(ctx); id != "" {
+		fields = append(fields, zap.String("request_id", id))
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		fields = append(fields, zap.String("trace_id", sc.TraceID().String()), zap.String("span_id", sc.SpanID().String()))
+	}
+	if len(fields) == 0 {
+		return l
+	}
+	return l.With(fields...)
+}
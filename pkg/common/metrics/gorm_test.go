@@ -0,0 +1,80 @@
+package metrics
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type metricsWidget struct {
+	ID   int64
+	Name string
+}
+
+// pluginDB returns a dry-run DB, which runs the callbacks without connecting, with the
+// plugin installed. fail, when set, is added to every query as its error.
+func pluginDB(t *testing.T, fail error) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost", PreferSimpleProtocol: true}),
+		&gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true, Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Use(GormPlugin{}); err != nil {
+		t.Fatal(err)
+	}
+	if fail != nil {
+		err := db.Callback().Query().Before("gorm:query").Register("test:fail", func(db *gorm.DB) { db.AddError(fail) })
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return db
+}
+
+func TestGormPluginLabels(t *testing.T) {
+	for name, tc := range map[string]struct {
+		fail              error
+		run               func(db *gorm.DB)
+		op, table, result string
+	}{
+		"create": {run: func(db *gorm.DB) { db.Create(&metricsWidget{Name: "a"}) },
+			op: "create", table: "metrics_widgets", result: "ok"},
+		"query": {run: func(db *gorm.DB) { db.Find(&[]metricsWidget{}) },
+			op: "query", table: "metrics_widgets", result: "ok"},
+		"update": {run: func(db *gorm.DB) { db.Model(&metricsWidget{ID: 1}).Update("name", "b") },
+			op: "update", table: "metrics_widgets", result: "ok"},
+		"delete": {run: func(db *gorm.DB) { db.Delete(&metricsWidget{ID: 1}) },
+			op: "delete", table: "metrics_widgets", result: "ok"},
+		"raw without table": {run: func(db *gorm.DB) { db.Exec("SELECT 1") },
+			op: "raw", table: "unknown", result: "ok"},
+		"error": {fail: errors.New("boom"), run: func(db *gorm.DB) { db.Find(&[]metricsWidget{}) },
+			op: "query", table: "metrics_widgets", result: "error"},
+		"not found is ok": {fail: gorm.ErrRecordNotFound, run: func(db *gorm.DB) { db.First(&metricsWidget{}) },
+			op: "query", table: "metrics_widgets", result: "ok"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			db := pluginDB(t, tc.fail)
+			duration := dbQueryDuration.WithLabelValues(tc.op, tc.table, tc.result)
+			observed := sampleCount(t, duration)
+			tc.run(db)
+			if got := sampleCount(t, duration) - observed; got != 1 {
+				t.Fatalf("observed %d %s statements on %s with result %s, want 1", got, tc.op, tc.table, tc.result)
+			}
+		})
+	}
+}
+
+func TestGormPluginSkipsStatementsWithoutStart(t *testing.T) {
+	db := pluginDB(t, nil)
+	before := testutil.CollectAndCount(dbQueryDuration)
+	// An after hook without its before hook's timestamp must not observe anything.
+	observeQuery("query")(db.Session(&gorm.Session{}))
+	if got := testutil.CollectAndCount(dbQueryDuration); got != before {
+		t.Fatalf("series grew from %d to %d", before, got)
+	}
+}
@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// expectGRPC checks that call adds one count and one duration for method and code.
+func expectGRPC(t *testing.T, client bool, method, code string, call func()) {
+	t.Helper()
+	count, duration := grpcServerHandled, grpcServerDuration
+	if client {
+		count, duration = grpcClientHandled, grpcClientDuration
+	}
+	before, observed := testutil.ToFloat64(count.WithLabelValues(method, code)), sampleCount(t, duration.WithLabelValues(method, code))
+	call()
+	if got := testutil.ToFloat64(count.WithLabelValues(method, code)) - before; got != 1 {
+		t.Fatalf("%s %s: counted %v calls, want 1", method, code, got)
+	}
+	if got := sampleCount(t, duration.WithLabelValues(method, code)) - observed; got != 1 {
+		t.Fatalf("%s %s: observed %d durations, want 1", method, code, got)
+	}
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	intercept := UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/metrics.Test/Unary"}
+	expectGRPC(t, false, info.FullMethod, "OK", func() {
+		intercept(context.Background(), nil, info, func(context.Context, any) (any, error) { return "ok", nil })
+	})
+	expectGRPC(t, false, info.FullMethod, "NotFound", func() {
+		intercept(context.Background(), nil, info, func(context.Context, any) (any, error) {
+			return nil, status.Error(codes.NotFound, "missing")
+		})
+	})
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	intercept := StreamServerInterceptor()
+	info := &grpc.StreamServerInfo{FullMethod: "/metrics.Test/Stream"}
+	// Errors without a status are labelled Unknown.
+	expectGRPC(t, false, info.FullMethod, "Unknown", func() {
+		intercept(nil, nil, info, func(any, grpc.ServerStream) error { return errors.New("boom") })
+	})
+}
+
+func TestUnaryClientInterceptor(t *testing.T) {
+	intercept := UnaryClientInterceptor()
+	method := "/metrics.Test/Client"
+	expectGRPC(t, true, method, "Unavailable", func() {
+		intercept(context.Background(), method, nil, nil, nil,
+			func(context.Context, string, any, any, *grpc.ClientConn, ...grpc.CallOption) error {
+				return status.Error(codes.Unavailable, "down")
+			})
+	})
+}
@@ -0,0 +1,50 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestEchoMiddlewareLabels(t *testing.T) {
+	e := echo.New()
+	e.Use(EchoMiddleware())
+	e.GET("/metrics-test/users/:id", func(c echo.Context) error { return c.NoContent(http.StatusNoContent) })
+	e.GET("/metrics-test/missing", func(c echo.Context) error { return echo.NewHTTPError(http.StatusNotFound) })
+	e.GET("/metrics-test/broken", func(c echo.Context) error { return errors.New("boom") })
+	// The gateway replaces its catch-all with the matched binding, as routeMiddleware does.
+	e.GET("/metrics-test/api/*", func(c echo.Context) error {
+		c.SetPath("/metrics-test/api/users/{id}")
+		return c.NoContent(http.StatusOK)
+	})
+
+	for name, tc := range map[string]struct {
+		path          string
+		route, status string
+	}{
+		"path template": {"/metrics-test/users/42", "/metrics-test/users/:id", "204"},
+		"http error":    {"/metrics-test/missing", "/metrics-test/missing", "404"},
+		"other error":   {"/metrics-test/broken", "/metrics-test/broken", "500"},
+		"gateway route": {"/metrics-test/api/users/42", "/metrics-test/api/users/{id}", "200"},
+		"unmatched":     {"/metrics-test/nowhere", "unmatched", "404"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			count := httpRequests.WithLabelValues(tc.route, http.MethodGet, tc.status)
+			duration := httpDuration.WithLabelValues(tc.route, http.MethodGet, tc.status)
+			before, observed := testutil.ToFloat64(count), sampleCount(t, duration)
+
+			e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tc.path, nil))
+
+			if got := testutil.ToFloat64(count) - before; got != 1 {
+				t.Fatalf("counted %v requests, want 1", got)
+			}
+			if got := sampleCount(t, duration) - observed; got != 1 {
+				t.Fatalf("observed %d durations, want 1", got)
+			}
+		})
+	}
+}
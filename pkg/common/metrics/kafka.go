@@ -0,0 +1,64 @@
+package metrics
+
+import (
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Kafka consume outcomes.
+const (
+	ConsumeSuccess = "success"
+	ConsumeRetry   = "retry"
+	ConsumeFailed  = "failed"
+	// ConsumeSkipped counts messages targeted at another consumer group.
+	ConsumeSkipped = "skipped"
+)
+
+var (
+	kafkaProduced = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "kafka",
+		Name:      "messages_produced_total",
+		Help:      "Messages written to Kafka by topic and result (ok, error).",
+	}, []string{"topic", "result"})
+
+	kafkaConsumed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "kafka",
+		Name:      "messages_consumed_total",
+		Help:      "Consumed message handling outcomes by topic, group and result (success, retry, failed, skipped).",
+	}, []string{"topic", "group", "result"})
+
+	kafkaLag = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "kafka",
+		Name:      "consumer_lag",
+		Help:      "Messages behind the partition high watermark as of the last fetched message.",
+	}, []string{"topic", "group", "partition"})
+)
+
+// ObserveProduced counts n messages written to topic; err selects the result label.
+func ObserveProduced(topic string, n int, err error) {
+	result := "ok"
+	if err != nil {
+		result = "error"
+	}
+	kafkaProduced.WithLabelValues(topic, result).Add(float64(n))
+}
+
+// ObserveConsumed counts one handling outcome for a consumed message.
+func ObserveConsumed(topic, group, result string) {
+	kafkaConsumed.WithLabelValues(topic, group, result).Inc()
+}
+
+// SetConsumerLag records the lag of a partition given the last fetched offset and the
+// partition high watermark.
+func SetConsumerLag(topic, group string, partition int, offset, highWaterMark int64) {
+	lag := highWaterMark - offset - 1
+	if lag < 0 {
+		lag = 0
+	}
+	kafkaLag.WithLabelValues(topic, group, strconv.Itoa(partition)).Set(float64(lag))
+}
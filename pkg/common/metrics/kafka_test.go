@@ -0,0 +1,60 @@
+package metrics
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestSetConsumerLag(t *testing.T) {
+	for name, tc := range map[string]struct {
+		offset, highWaterMark int64
+		want                  float64
+	}{
+		// The high watermark is the offset of the next message to be written.
+		"caught up": {offset: 9, highWaterMark: 10, want: 0},
+		"behind":    {offset: 4, highWaterMark: 10, want: 5},
+		"first":     {offset: 0, highWaterMark: 1, want: 0},
+		// A watermark fetched before the message was written is clamped.
+		"stale watermark": {offset: 12, highWaterMark: 10, want: 0},
+	} {
+		t.Run(name, func(t *testing.T) {
+			SetConsumerLag("lag-test", name, 3, tc.offset, tc.highWaterMark)
+			if got := testutil.ToFloat64(kafkaLag.WithLabelValues("lag-test", name, "3")); got != tc.want {
+				t.Fatalf("lag %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSetConsumerLagLabelsPartitions(t *testing.T) {
+	SetConsumerLag("lag-partitions", "group", 0, 1, 10)
+	SetConsumerLag("lag-partitions", "group", 1, 7, 10)
+	SetConsumerLag("lag-partitions", "group", 0, 5, 10)
+	for partition, want := range map[string]float64{"0": 4, "1": 2} {
+		if got := testutil.ToFloat64(kafkaLag.WithLabelValues("lag-partitions", "group", partition)); got != want {
+			t.Fatalf("partition %s: lag %v, want %v", partition, got, want)
+		}
+	}
+}
+
+func TestObserveProducedAndConsumed(t *testing.T) {
+	ok, failed := kafkaProduced.WithLabelValues("produce-test", "ok"), kafkaProduced.WithLabelValues("produce-test", "error")
+	okBefore, failedBefore := testutil.ToFloat64(ok), testutil.ToFloat64(failed)
+	ObserveProduced("produce-test", 3, nil)
+	ObserveProduced("produce-test", 2, errors.New("broker down"))
+	if got := testutil.ToFloat64(ok) - okBefore; got != 3 {
+		t.Fatalf("counted %v produced, want 3", got)
+	}
+	if got := testutil.ToFloat64(failed) - failedBefore; got != 2 {
+		t.Fatalf("counted %v failed, want 2", got)
+	}
+
+	consumed := kafkaConsumed.WithLabelValues("consume-test", "group", ConsumeRetry)
+	before := testutil.ToFloat64(consumed)
+	ObserveConsumed("consume-test", "group", ConsumeRetry)
+	if got := testutil.ToFloat64(consumed) - before; got != 1 {
+		t.Fatalf("counted %v retries, want 1", got)
+	}
+}
@@ -0,0 +1,22 @@
+// Package metrics holds the Prometheus collectors shared by all services and the
+// middleware, interceptors and plugins that feed them.
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "scorehub"
+
+// Handler serves the default Prometheus registry.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// Register mounts GET /metrics on e.
+func Register(e *echo.Echo) {
+	e.GET("/metrics", echo.WrapHandler(Handler()))
+}
@@ -0,0 +1,26 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Rate-limiter decisions.
+const (
+	RateLimitAllowed = "allowed"
+	RateLimitLimited = "limited"
+	// RateLimitError counts Redis failures; the in-process limiter then decides.
+	RateLimitError = "error"
+)
+
+var rateLimitDecisions = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: namespace,
+	Subsystem: "rate_limit",
+	Name:      "decisions_total",
+	Help:      "Rate limiter decisions (allowed, limited) and store errors (error).",
+}, []string{"decision"})
+
+// ObserveRateLimit counts one limiter decision.
+func ObserveRateLimit(decision string) {
+	rateLimitDecisions.WithLabelValues(decision).Inc()
+}
@@ -0,0 +1,26 @@
+package models
+
+// Score bands follow the common FICO ranges.
+const (
+	BandPoor        = "poor"
+	BandFair        = "fair"
+	BandGood        = "good"
+	BandVeryGood    = "very_good"
+	BandExceptional = "exceptional"
+)
+
+// ScoreBand returns the band a credit score falls into.
+func ScoreBand(score int64) string {
+	switch {
+	case score >= 800:
+		return BandExceptional
+	case score >= 740:
+		return BandVeryGood
+	case score >= 670:
+		return BandGood
+	case score >= 580:
+		return BandFair
+	default:
+		return BandPoor
+	}
+}
@@ -3,21 +3,46 @@ package models
 import (
 	"os"
 	"strconv"
+	"time"
 )
 
 type RedisConfig struct {
-	RateLimitBurst int
-	RedisAddr      string
-	RedisPassword  string
-	RedisDB        int
+	RedisAddr     string
+	RedisPassword string
+	RedisDB       int
 }
 
 func LoadRedisConfig() *RedisConfig {
 	return &RedisConfig{
-		RateLimitBurst: GetEnvAsInt("RATE_LIMIT_BURST", 20),
-		RedisAddr:      GetEnv("REDIS_ADDR", "localhost:6379"),
-		RedisPassword:  GetEnv("REDIS_PASSWORD", ""),
-		RedisDB:        GetEnvAsInt("REDIS_DB", 0),
+		RedisAddr:     GetEnv("REDIS_ADDR", "localhost:6379"),
+		RedisPassword: GetEnv("REDIS_PASSWORD", ""),
+		RedisDB:       GetEnvAsInt("REDIS_DB", 0),
+	}
+}
+
+// RateLimitConfig configures request rate limiting (see pkg/common/ratelimit).
+type RateLimitConfig struct {
+	Enabled bool
+	// RPS and Burst define the default policy, a token bucket per client, used when
+	// Policies is empty.
+	RPS   int
+	Burst int
+	// IPRPS and IPBurst define the default per-IP token bucket, checked before
+	// authentication; an IPRPS of 0 disables it.
+	IPRPS   int
+	IPBurst int
+	// Policies is a JSON array of policies replacing the defaults.
+	Policies string
+}
+
+func LoadRateLimitConfig() *RateLimitConfig {
+	return &RateLimitConfig{
+		Enabled:  GetEnv("RATE_LIMIT_ENABLED", "true") == "true",
+		RPS:      GetEnvAsInt("RATE_LIMIT_RPS", 10),
+		Burst:    GetEnvAsInt("RATE_LIMIT_BURST", 20),
+		IPRPS:    GetEnvAsInt("RATE_LIMIT_IP_RPS", 50),
+		IPBurst:  GetEnvAsInt("RATE_LIMIT_IP_BURST", 100),
+		Policies: GetEnv("RATE_LIMIT_POLICIES", ""),
 	}
 }
 
@@ -27,15 +52,47 @@ type PostgresConfig struct {
 	Password string
 	DB       string
 	Port     int
+	// SSLMode is a libpq sslmode (disable, require, verify-ca, verify-full).
+	SSLMode string
+	// SSLRootCert verifies the server and is read once. SSLCert and SSLKey authenticate the
+	// client and are reloaded when the files change, so rotating them needs no restart.
+	SSLRootCert string
+	SSLCert     string
+	SSLKey      string
+	// AutoMigrate applies pending schema migrations when the connection is opened.
+	AutoMigrate bool
 }
 
 func LoadPostgresConfig() *PostgresConfig {
 	return &PostgresConfig{
-		Host:     GetEnv("POSTGRES_HOST", "localhost"),
-		Port:     GetEnvAsInt("POSTGRES_PORT", 5432),
-		User:     GetEnv("POSTGRES_USER", "scorehub_user"),
-		Password: GetEnv("POSTGRES_PASSWORD", "postgres"),
-		DB:       GetEnv("POSTGRES_DB", "scorehub"),
+		Host:        GetEnv("POSTGRES_HOST", "localhost"),
+		Port:        GetEnvAsInt("POSTGRES_PORT", 5432),
+		User:        GetEnv("POSTGRES_USER", "scorehub_user"),
+		Password:    GetEnv("POSTGRES_PASSWORD", "postgres"),
+		DB:          GetEnv("POSTGRES_DB", "scorehub"),
+		SSLMode:     GetEnv("POSTGRES_SSLMODE", "disable"),
+		SSLRootCert: GetEnv("POSTGRES_SSLROOTCERT", ""),
+		SSLCert:     GetEnv("POSTGRES_SSLCERT", ""),
+		SSLKey:      GetEnv("POSTGRES_SSLKEY", ""),
+		AutoMigrate: GetEnv("MIGRATE_ON_START", "false") == "true",
+	}
+}
+
+// ConsumerRetryConfig controls retries of failed Kafka message handlers.
+type ConsumerRetryConfig struct {
+	// MaxAttempts below 1 selects the default of 5, so poison messages are always dead-lettered.
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+}
+
+func LoadConsumerRetryConfig() *ConsumerRetryConfig {
+	return &ConsumerRetryConfig{
+		MaxAttempts:    GetEnvAsInt("CONSUMER_MAX_ATTEMPTS", 5),
+		InitialBackoff: time.Duration(GetEnvAsInt("CONSUMER_INITIAL_BACKOFF_MS", 200)) * time.Millisecond,
+		MaxBackoff:     time.Duration(GetEnvAsInt("CONSUMER_MAX_BACKOFF_MS", 30000)) * time.Millisecond,
+		Multiplier:     2,
 	}
 }
 
@@ -53,3 +110,108 @@ func GetEnvAsInt(key string, defaultValue int) int {
 	}
 	return defaultValue
 }
+
+// TracingConfig selects where OpenTelemetry spans are exported.
+type TracingConfig struct {
+	// Exporter is one of otlp, stdout, file or none.
+	Exporter string
+	// OTLPEndpoint is a host:port or URL of an OTLP/HTTP collector.
+	OTLPEndpoint string
+	OTLPInsecure bool
+	// FilePath receives JSON spans when Exporter is file.
+	FilePath string
+	// SamplePercent of new root traces to record; child spans follow their parent.
+	SamplePercent int
+}
+
+func LoadTracingConfig() *TracingConfig {
+	return &TracingConfig{
+		Exporter:      GetEnv("TRACING_EXPORTER", "none"),
+		OTLPEndpoint:  GetEnv("OTEL_EXPORTER_OTLP_ENDPOINT", "localhost:4318"),
+		OTLPInsecure:  GetEnv("OTEL_EXPORTER_OTLP_INSECURE", "true") == "true",
+		FilePath:      GetEnv("TRACING_FILE", "traces.json"),
+		SamplePercent: GetEnvAsInt("TRACING_SAMPLE_PERCENT", 100),
+	}
+}
+
+// TLSConfig locates the PEM files for one TLS endpoint.
+type TLSConfig struct {
+	CertFile string
+	KeyFile  string
+	// CAFile verifies the peer: on servers it requires client certificates signed by it
+	// (mutual TLS), on clients it replaces the system roots.
+	CAFile string
+	// ServerName is the name expected in the server certificate, for clients.
+	ServerName string
+}
+
+// LoadTLSConfig reads <prefix>TLS_CERT_FILE, TLS_KEY_FILE, TLS_CA_FILE and TLS_SERVER_NAME.
+func LoadTLSConfig(prefix string) *TLSConfig {
+	return &TLSConfig{
+		CertFile:   GetEnv(prefix+"TLS_CERT_FILE", ""),
+		KeyFile:    GetEnv(prefix+"TLS_KEY_FILE", ""),
+		CAFile:     GetEnv(prefix+"TLS_CA_FILE", ""),
+		ServerName: GetEnv(prefix+"TLS_SERVER_NAME", ""),
+	}
+}
+
+// GRPCConfig holds settings shared by gRPC servers and clients.
+type GRPCConfig struct {
+	// DefaultTimeout bounds incoming unary calls that carry no deadline.
+	DefaultTimeout time.Duration
+	// ClientTimeout bounds outgoing calls made without a deadline.
+	ClientTimeout time.Duration
+	// TLS secures the gRPC listener; the gateway reuses it to dial the listener.
+	TLS *TLSConfig
+}
+
+func LoadGRPCConfig() *GRPCConfig {
+	return &GRPCConfig{
+		DefaultTimeout: time.Duration(GetEnvAsInt("GRPC_DEFAULT_TIMEOUT_MS", 30000)) * time.Millisecond,
+		ClientTimeout:  time.Duration(GetEnvAsInt("GRPC_CLIENT_TIMEOUT_MS", 5000)) * time.Millisecond,
+		TLS:            LoadTLSConfig("GRPC_"),
+	}
+}
+
+// AuthConfig configures JWT bearer authentication. Tokens are accepted when JWTSecret
+// (HS256) or a JWKS source (RS256) is set.
+type AuthConfig struct {
+	JWTSecret string
+	// JWKSFile takes precedence over JWKSURL when both are set.
+	JWKSFile    string
+	JWKSURL     string
+	JWKSRefresh time.Duration
+	// Issuer and Audience are checked against iss and aud when set.
+	Issuer   string
+	Audience string
+}
+
+func LoadAuthConfig() *AuthConfig {
+	return &AuthConfig{
+		JWTSecret:   GetEnv("JWT_SECRET", ""),
+		JWKSFile:    GetEnv("JWT_JWKS_FILE", ""),
+		JWKSURL:     GetEnv("JWT_JWKS_URL", ""),
+		JWKSRefresh: time.Duration(GetEnvAsInt("JWT_JWKS_REFRESH_SECONDS", 300)) * time.Second,
+		Issuer:      GetEnv("JWT_ISSUER", ""),
+		Audience:    GetEnv("JWT_AUDIENCE", ""),
+	}
+}
+
+// APIKeysConfig enables API keys issued from the api_keys table.
+type APIKeysConfig struct {
+	Enabled bool
+	// CacheTTL bounds how long a revoked key keeps working in other processes.
+	CacheTTL  time.Duration
+	CacheSize int
+	// RotationGrace is how long the old key keeps working after a rotation.
+	RotationGrace time.Duration
+}
+
+func LoadAPIKeysConfig() *APIKeysConfig {
+	return &APIKeysConfig{
+		Enabled:       GetEnv("API_KEYS_ENABLED", "false") == "true",
+		CacheTTL:      time.Duration(GetEnvAsInt("API_KEY_CACHE_TTL_SECONDS", 60)) * time.Second,
+		CacheSize:     GetEnvAsInt("API_KEY_CACHE_SIZE", 10000),
+		RotationGrace: time.Duration(GetEnvAsInt("API_KEY_ROTATION_GRACE_SECONDS", 86400)) * time.Second,
+	}
+}
@@ -3,11 +3,35 @@ package models
 import "time"
 
 type User struct {
+	ID      int64  `gorm:"primaryKey;autoIncrement"`
+	Name    string `gorm:"size:255;not null"`
+	Email   string `gorm:"size:255;uniqueIndex;not null"`
+	Score   int64  `gorm:"not null;default:0"`
+	Deleted bool   `gorm:"not null;default:false"`
+	// Preferences controls how the user is notified.
+	Preferences UserPreferences `gorm:"type:jsonb;serializer:json;not null"`
+	CreatedAt   time.Time       `gorm:"autoCreateTime"`
+	UpdatedAt   time.Time       `gorm:"autoUpdateTime"`
+}
+
+// UserPreferences are the notification settings a user controls.
+type UserPreferences struct {
+	// EmailOptOut delivers notifications addressed to the email channel in-app only.
+	EmailOptOut bool `json:"email_opt_out"`
+}
+
+// ScoreHistory records a single applied score change for a user.
+type ScoreHistory struct {
 	ID        int64     `gorm:"primaryKey;autoIncrement"`
-	Name      string    `gorm:"size:255;not null"`
-	Email     string    `gorm:"size:255;uniqueIndex;not null"`
-	Score     int64     `gorm:"not null;default:0"`
-	Deleted   bool      `gorm:"not null;default:false"`
+	UserID    int64     `gorm:"index;not null"`
+	OldScore  int64     `gorm:"not null"`
+	NewScore  int64     `gorm:"not null"`
+	Change    int32     `gorm:"not null"`
+	EventID   string    `gorm:"size:128"`
 	CreatedAt time.Time `gorm:"autoCreateTime"`
-	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+// TableName keeps the history table singular to match the SQL schema.
+func (ScoreHistory) TableName() string {
+	return "score_history"
 }
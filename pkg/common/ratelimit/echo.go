@@ -0,0 +1,46 @@
+package ratelimit
+
+import (
+	"net/http"
+
+	"github.com/emorenkov/scorehub/pkg/common/auth"
+	"github.com/labstack/echo/v4"
+)
+
+// EchoMiddleware applies the policies of stage to every request on the group it is
+// mounted on, answering 429 when a policy is exhausted. Mount a BeforeAuth middleware
+// before the authentication middleware and an AfterAuth one after it, so API key and
+// user policies see the caller; the AfterAuth middleware reports the combined quota. A
+// nil Limiter lets requests through.
+//
+// IP policies use c.RealIP(), so the server's IPExtractor must not trust headers the
+// client sets; the services use echo.ExtractIPDirect().
+func (l *Limiter) EchoMiddleware(stage Stage) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		if l == nil {
+			return next
+		}
+		return func(c echo.Context) error {
+			req := c.Request()
+			ctx, res := l.Check(req.Context(), stage, &Request{
+				IP:        c.RealIP(),
+				Route:     req.Method + " " + c.Path(),
+				Principal: auth.FromContext(req.Context()),
+			})
+			if res == nil {
+				return next(c)
+			}
+			c.SetRequest(req.WithContext(ctx))
+			if res.Allowed && stage == BeforeAuth {
+				return next(c)
+			}
+			for k, v := range res.Headers() {
+				c.Response().Header()[k] = v
+			}
+			if !res.Allowed {
+				return c.JSON(http.StatusTooManyRequests, map[string]string{"error": "rate limit exceeded"})
+			}
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/emorenkov/scorehub/pkg/common/models"
+	"github.com/labstack/echo/v4"
+)
+
+func TestEchoMiddlewareIgnoresForwardedFor(t *testing.T) {
+	l, err := New(&models.RateLimitConfig{
+		Enabled:  true,
+		Policies: `[{"name":"ip","key":"ip","algorithm":"sliding_window","limit":1,"period":"1m"}]`,
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := echo.New()
+	e.IPExtractor = echo.ExtractIPDirect()
+	e.GET("/", func(c echo.Context) error { return c.NoContent(http.StatusOK) }, l.EchoMiddleware(BeforeAuth))
+
+	for i, want := range []int{http.StatusOK, http.StatusTooManyRequests} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = "203.0.113.9:40000"
+		// A fresh forwarded address on every request must not buy a fresh quota.
+		req.Header.Set(echo.HeaderXForwardedFor, "198.51.100."+string(rune('1'+i)))
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		if rec.Code != want {
+			t.Fatalf("request %d: status %d, want %d", i, rec.Code, want)
+		}
+	}
+}
+
+func TestEchoMiddlewareLimitsBeforeAuth(t *testing.T) {
+	l, err := New(&models.RateLimitConfig{
+		Enabled: true,
+		Policies: `[{"name":"ip","key":"ip","algorithm":"sliding_window","limit":1,"period":"1m"},
+			{"name":"users","key":"user","algorithm":"sliding_window","limit":5,"period":"1m"}]`,
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	deny := func(echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error { return c.NoContent(http.StatusUnauthorized) }
+	}
+	e := echo.New()
+	e.IPExtractor = echo.ExtractIPDirect()
+	e.GET("/", func(c echo.Context) error { return c.NoContent(http.StatusOK) },
+		l.EchoMiddleware(BeforeAuth), deny, l.EchoMiddleware(AfterAuth))
+
+	for i, want := range []int{http.StatusUnauthorized, http.StatusTooManyRequests} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = "203.0.113.9:40000"
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		if rec.Code != want {
+			t.Fatalf("request %d: status %d, want %d", i, rec.Code, want)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+)
+
+// memoryKeys bounds the keys the in-process limiter tracks; the least recently used are
+// forgotten, which at worst resets their quota.
+const memoryKeys = 10000
+
+// memoryStore implements the policies in process. It is only used while Redis is
+// unavailable or absent, so its quotas are per replica.
+type memoryStore struct {
+	mu      sync.Mutex
+	entries *lru.Cache[string, *entry]
+}
+
+type entry struct {
+	// tokens and refilled track a token bucket.
+	tokens   float64
+	refilled time.Time
+	// hits logs the requests in a sliding window, oldest first.
+	hits []time.Time
+}
+
+func newMemoryStore() *memoryStore {
+	entries, _ := lru.New[string, *entry](memoryKeys)
+	return &memoryStore{entries: entries}
+}
+
+func (s *memoryStore) take(p *Policy, key string, now time.Time) *Result {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries.Get(key)
+	if !ok {
+		e = &entry{tokens: float64(p.Burst), refilled: now}
+		s.entries.Add(key, e)
+	}
+	res := &Result{Limit: p.capacity()}
+
+	switch p.Algorithm {
+	case TokenBucket:
+		capacity := float64(p.Burst)
+		rate := float64(p.Limit) / float64(p.Period) // tokens per nanosecond
+		if now.After(e.refilled) {
+			e.tokens = min(capacity, e.tokens+float64(now.Sub(e.refilled))*rate)
+			e.refilled = now
+		}
+		if e.tokens >= 1 {
+			e.tokens--
+			res.Allowed = true
+		} else {
+			res.RetryAfter = time.Duration(math.Ceil((1 - e.tokens) / rate))
+		}
+		res.Remaining = int(e.tokens)
+		res.Reset = time.Duration(math.Ceil((capacity - e.tokens) / rate))
+
+	case SlidingWindow:
+		cutoff := now.Add(-p.Period)
+		i := 0
+		for i < len(e.hits) && !e.hits[i].After(cutoff) {
+			i++
+		}
+		e.hits = e.hits[i:]
+		if len(e.hits) < p.Limit {
+			e.hits = append(e.hits, now)
+			res.Allowed = true
+		}
+		res.Remaining = p.Limit - len(e.hits)
+		res.Reset = e.hits[0].Add(p.Period).Sub(now)
+		if !res.Allowed {
+			res.RetryAfter = res.Reset
+		}
+	}
+	return res
+}
@@ -0,0 +1,208 @@
+package ratelimit
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/emorenkov/scorehub/pkg/common/models"
+)
+
+// Algorithm selects how a policy counts requests.
+type Algorithm string
+
+const (
+	// TokenBucket refills Limit tokens per Period up to Burst, so idle clients may burst.
+	TokenBucket Algorithm = "token_bucket"
+	// SlidingWindow allows at most Limit requests in any Period by logging each request.
+	SlidingWindow Algorithm = "sliding_window"
+)
+
+// KeyKind selects what a policy counts requests per.
+type KeyKind string
+
+const (
+	KeyIP KeyKind = "ip"
+	// KeyAPIKey applies only to callers authenticated with an API key.
+	KeyAPIKey KeyKind = "api_key"
+	// KeyUser applies only to callers authenticated with a token, per sub claim.
+	KeyUser KeyKind = "user"
+	// KeyRoute shares one quota between all callers of a route.
+	KeyRoute KeyKind = "route"
+	// KeyClient is the API key, else the user, else the IP.
+	KeyClient KeyKind = "client"
+)
+
+// Policy is one limit. A request is checked against the policies that apply to it in
+// order, ip policies before authentication and the rest after it, and is rejected by
+// the first that is exhausted; the policies after it are not charged.
+type Policy struct {
+	Name      string    `json:"name"`
+	Key       KeyKind   `json:"key"`
+	Algorithm Algorithm `json:"algorithm"`
+	// Limit requests per Period.
+	Limit  int           `json:"limit"`
+	Period time.Duration `json:"-"`
+	// Burst is the token bucket capacity; it defaults to Limit.
+	Burst int `json:"burst,omitempty"`
+	// Routes restricts the policy to these routes: full method names for RPCs, whether
+	// called over gRPC or through the REST gateway, and "METHOD /path/:param" or
+	// "/path/:param" for the Echo routes. A trailing * matches a prefix. Empty means every
+	// route.
+	Routes []string `json:"routes,omitempty"`
+}
+
+// ParsePolicies returns the policies in cfg.Policies, or when it is empty the default
+// per-IP token bucket of cfg.IPRPS and cfg.IPBurst (omitted when cfg.IPRPS is 0) followed
+// by the per-client token bucket of cfg.RPS and cfg.Burst. Periods are Go durations:
+//
+//	[{"name":"keys","key":"api_key","algorithm":"sliding_window","limit":600,"period":"1m"}]
+func ParsePolicies(cfg *models.RateLimitConfig) ([]Policy, error) {
+	if cfg.Policies == "" {
+		var policies []Policy
+		if cfg.IPRPS > 0 {
+			policies = append(policies, Policy{Name: "ip", Key: KeyIP, Algorithm: TokenBucket, Limit: cfg.IPRPS, Period: time.Second, Burst: cfg.IPBurst})
+		}
+		policies = append(policies, Policy{Name: "default", Key: KeyClient, Algorithm: TokenBucket, Limit: cfg.RPS, Period: time.Second, Burst: cfg.Burst})
+		for i := range policies {
+			if err := policies[i].validate(); err != nil {
+				return nil, err
+			}
+		}
+		return policies, nil
+	}
+
+	var raw []struct {
+		Policy
+		Period string `json:"period"`
+	}
+	if err := json.Unmarshal([]byte(cfg.Policies), &raw); err != nil {
+		return nil, fmt.Errorf("parse rate limit policies: %w", err)
+	}
+	policies := make([]Policy, 0, len(raw))
+	names := make(map[string]struct{}, len(raw))
+	for _, r := range raw {
+		p := r.Policy
+		period, err := time.ParseDuration(r.Period)
+		if err != nil {
+			return nil, fmt.Errorf("rate limit policy %q: period: %w", p.Name, err)
+		}
+		p.Period = period
+		if err := p.validate(); err != nil {
+			return nil, err
+		}
+		if _, dup := names[p.Name]; dup {
+			return nil, fmt.Errorf("rate limit policy %q: duplicate name", p.Name)
+		}
+		names[p.Name] = struct{}{}
+		policies = append(policies, p)
+	}
+	return policies, nil
+}
+
+func (p *Policy) validate() error {
+	if p.Name == "" {
+		return fmt.Errorf("rate limit policy: name is required")
+	}
+	switch p.Key {
+	case KeyIP, KeyAPIKey, KeyUser, KeyRoute, KeyClient:
+	default:
+		return fmt.Errorf("rate limit policy %q: unknown key %q", p.Name, p.Key)
+	}
+	switch p.Algorithm {
+	case TokenBucket, SlidingWindow:
+	default:
+		return fmt.Errorf("rate limit policy %q: unknown algorithm %q", p.Name, p.Algorithm)
+	}
+	if p.Limit <= 0 || p.Period <= 0 {
+		return fmt.Errorf("rate limit policy %q: limit and period must be positive", p.Name)
+	}
+	if p.Burst <= 0 {
+		p.Burst = p.Limit
+	}
+	return nil
+}
+
+// capacity is the most requests the policy allows at once.
+func (p *Policy) capacity() int {
+	if p.Algorithm == TokenBucket {
+		return p.Burst
+	}
+	return p.Limit
+}
+
+// stage returns when the policy is checked: ip policies need no caller, so they run
+// before authentication.
+func (p *Policy) stage() Stage {
+	if p.Key == KeyIP {
+		return BeforeAuth
+	}
+	return AfterAuth
+}
+
+func (p *Policy) matches(route string) bool {
+	if len(p.Routes) == 0 {
+		return true
+	}
+	path := route
+	if i := strings.IndexByte(route, ' '); i >= 0 {
+		path = route[i+1:]
+	}
+	for _, r := range p.Routes {
+		if prefix, ok := strings.CutSuffix(r, "*"); ok {
+			if strings.HasPrefix(route, prefix) || strings.HasPrefix(path, prefix) {
+				return true
+			}
+		} else if r == route || r == path {
+			return true
+		}
+	}
+	return false
+}
+
+// key returns the bucket req counts against, or false when the policy does not apply.
+func (p *Policy) key(req *Request) (string, bool) {
+	var k string
+	switch p.Key {
+	case KeyIP:
+		k = ipKey(req)
+	case KeyAPIKey:
+		k = apiKeyKey(req)
+	case KeyUser:
+		k = userKey(req)
+	case KeyRoute:
+		k = "route:" + req.Route
+	case KeyClient:
+		if k = apiKeyKey(req); k == "" {
+			if k = userKey(req); k == "" {
+				k = ipKey(req)
+			}
+		}
+	}
+	if k == "" {
+		return "", false
+	}
+	return p.Name + ":" + k, true
+}
+
+func ipKey(req *Request) string {
+	if req.IP == "" {
+		return ""
+	}
+	return "ip:" + req.IP
+}
+
+func apiKeyKey(req *Request) string {
+	if req.Principal == nil || !req.Principal.APIKey {
+		return ""
+	}
+	return "key:" + req.Principal.Subject
+}
+
+func userKey(req *Request) string {
+	if req.Principal == nil || req.Principal.APIKey {
+		return ""
+	}
+	return "user:" + req.Principal.Subject
+}
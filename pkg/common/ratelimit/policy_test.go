@@ -0,0 +1,103 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/emorenkov/scorehub/pkg/common/auth"
+	"github.com/emorenkov/scorehub/pkg/common/models"
+)
+
+func TestPolicyMatches(t *testing.T) {
+	p := Policy{Routes: []string{
+		"POST /api/v1/score-events",
+		"/api/v1/users/:id",
+		"/api/v1/admin/*",
+		"/event.EventService/*",
+	}}
+	tests := []struct {
+		route string
+		want  bool
+	}{
+		{"POST /api/v1/score-events", true},
+		{"GET /api/v1/score-events", false},
+		{"GET /api/v1/users/:id", true},
+		{"DELETE /api/v1/users/:id", true},
+		{"GET /api/v1/users/:id/notifications", false},
+		{"GET /api/v1/admin/keys", true},
+		{"/event.EventService/SendScoreEvent", true},
+		{"/user.UserService/GetUser", false},
+	}
+	for _, tt := range tests {
+		if got := p.matches(tt.route); got != tt.want {
+			t.Errorf("matches(%q) = %v, want %v", tt.route, got, tt.want)
+		}
+	}
+	if !(&Policy{}).matches("GET /anything") {
+		t.Error("a policy without routes must match every route")
+	}
+}
+
+func TestPolicyKey(t *testing.T) {
+	user := &auth.Principal{Subject: "alice"}
+	apiKey := &auth.Principal{Subject: "api-key:abc", APIKey: true}
+	tests := []struct {
+		kind      KeyKind
+		principal *auth.Principal
+		want      string
+	}{
+		{KeyIP, user, "p:ip:198.51.100.7"},
+		{KeyAPIKey, apiKey, "p:key:api-key:abc"},
+		{KeyAPIKey, user, ""},
+		{KeyUser, user, "p:user:alice"},
+		{KeyUser, apiKey, ""},
+		{KeyRoute, nil, "p:route:GET /a"},
+		{KeyClient, apiKey, "p:key:api-key:abc"},
+		{KeyClient, user, "p:user:alice"},
+		{KeyClient, nil, "p:ip:198.51.100.7"},
+	}
+	for _, tt := range tests {
+		p := Policy{Name: "p", Key: tt.kind}
+		got, ok := p.key(&Request{IP: "198.51.100.7", Route: "GET /a", Principal: tt.principal})
+		if got != tt.want || ok != (tt.want != "") {
+			t.Errorf("%s key for %+v = %q, %v; want %q", tt.kind, tt.principal, got, ok, tt.want)
+		}
+	}
+}
+
+func TestParsePolicies(t *testing.T) {
+	policies, err := ParsePolicies(&models.RateLimitConfig{
+		Policies: `[{"name":"keys","key":"api_key","algorithm":"sliding_window","limit":600,"period":"1m"},
+			{"name":"ip","key":"ip","algorithm":"token_bucket","limit":5,"period":"1s"}]`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(policies) != 2 || policies[0].Period != time.Minute || policies[1].Burst != 5 {
+		t.Fatalf("policies = %+v", policies)
+	}
+	if policies[0].stage() != AfterAuth || policies[1].stage() != BeforeAuth {
+		t.Fatal("ip policies must run before authentication and the others after it")
+	}
+
+	defaults, err := ParsePolicies(&models.RateLimitConfig{RPS: 10, Burst: 20, IPRPS: 50, IPBurst: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(defaults) != 2 || defaults[0].Name != "ip" || defaults[1].Key != KeyClient {
+		t.Fatalf("default policies = %+v", defaults)
+	}
+
+	for _, bad := range []string{
+		`[{"name":"a","key":"ip","algorithm":"token_bucket","limit":1,"period":"1s"},{"name":"a","key":"ip","algorithm":"token_bucket","limit":1,"period":"1s"}]`,
+		`[{"name":"a","key":"host","algorithm":"token_bucket","limit":1,"period":"1s"}]`,
+		`[{"name":"a","key":"ip","algorithm":"leaky","limit":1,"period":"1s"}]`,
+		`[{"name":"a","key":"ip","algorithm":"token_bucket","limit":0,"period":"1s"}]`,
+		`[{"name":"a","key":"ip","algorithm":"token_bucket","limit":1,"period":"soon"}]`,
+		`[{"key":"ip","algorithm":"token_bucket","limit":1,"period":"1s"}]`,
+	} {
+		if _, err := ParsePolicies(&models.RateLimitConfig{Policies: bad}); err == nil {
+			t.Errorf("ParsePolicies accepted %s", bad)
+		}
+	}
+}
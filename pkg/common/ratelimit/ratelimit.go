@@ -0,0 +1,179 @@
+// Package ratelimit limits requests per API key, user, IP or route with token-bucket and
+// sliding-window policies. Counters live in Redis, updated atomically by Lua scripts, so
+// every replica shares them; while Redis is unavailable each process falls back to an
+// in-process limiter with the same policies.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/emorenkov/scorehub/pkg/common/auth"
+	logpkg "github.com/emorenkov/scorehub/pkg/common/logger"
+	"github.com/emorenkov/scorehub/pkg/common/metrics"
+	"github.com/emorenkov/scorehub/pkg/common/models"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// fallbackPeriod is how long the in-process limiter is used after a Redis error before
+// Redis is tried again, so an outage does not add a timeout to every request.
+const fallbackPeriod = 5 * time.Second
+
+// Request identifies a call for the policies.
+type Request struct {
+	IP string
+	// Route is "METHOD /path/:param" for REST and the full method name for gRPC.
+	Route string
+	// Principal is the authenticated caller, if any.
+	Principal *auth.Principal
+}
+
+// Result is the outcome of the most restrictive policy that applied.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is when the full quota is available again.
+	Reset time.Duration
+	// RetryAfter is when the next request may succeed; zero when Allowed.
+	RetryAfter time.Duration
+	policy     *Policy
+}
+
+// Headers returns the RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset and
+// RateLimit-Policy headers, plus Retry-After when the request was rejected.
+func (r *Result) Headers() http.Header {
+	h := http.Header{}
+	h.Set("RateLimit-Limit", strconv.Itoa(r.Limit))
+	h.Set("RateLimit-Remaining", strconv.Itoa(r.Remaining))
+	h.Set("RateLimit-Reset", strconv.Itoa(seconds(r.Reset)))
+	policy := fmt.Sprintf("%d;w=%d", r.policy.Limit, seconds(r.policy.Period))
+	if r.policy.Algorithm == TokenBucket {
+		policy += fmt.Sprintf(";burst=%d", r.policy.Burst)
+	}
+	h.Set("RateLimit-Policy", policy)
+	if !r.Allowed {
+		h.Set("Retry-After", strconv.Itoa(max(seconds(r.RetryAfter), 1)))
+	}
+	return h
+}
+
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// Limiter checks requests against a set of policies.
+type Limiter struct {
+	policies []Policy
+	redis    *redisStore
+	local    *memoryStore
+	// redisRetryAt is the UnixNano time before which Redis is skipped.
+	redisRetryAt atomic.Int64
+}
+
+// New returns a limiter for cfg, backed by client when it is not nil. It returns nil
+// when rate limiting is disabled; a nil Limiter allows everything.
+func New(cfg *models.RateLimitConfig, client *redis.Client) (*Limiter, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	policies, err := ParsePolicies(cfg)
+	if err != nil {
+		return nil, err
+	}
+	l := &Limiter{policies: policies, local: newMemoryStore()}
+	if client != nil {
+		l.redis = &redisStore{client: client}
+	}
+	return l, nil
+}
+
+// Stage is the point in request handling at which policies are checked.
+type Stage int
+
+const (
+	// BeforeAuth checks the ip policies before the caller is authenticated, so requests
+	// that fail authentication are limited too.
+	BeforeAuth Stage = iota
+	// AfterAuth checks the remaining policies once the caller is known.
+	AfterAuth
+)
+
+type resultKey struct{}
+
+// Check consumes one request from each policy of stage that applies to req, in the
+// configured order, and stops at the first that rejects it so later policies are not
+// charged. It returns the most restrictive result so far, including the one an earlier
+// stage stored in ctx, and ctx carrying that result for the next stage. The result is
+// nil when no policy applied.
+func (l *Limiter) Check(ctx context.Context, stage Stage, req *Request) (context.Context, *Result) {
+	if l == nil {
+		return ctx, nil
+	}
+	worst, _ := ctx.Value(resultKey{}).(*Result)
+	for i := range l.policies {
+		p := &l.policies[i]
+		if p.stage() != stage || !p.matches(req.Route) {
+			continue
+		}
+		key, ok := p.key(req)
+		if !ok {
+			continue
+		}
+		res := l.take(ctx, p, key)
+		res.policy = p
+		if worst == nil || stricter(res, worst) {
+			worst = res
+		}
+		if !res.Allowed {
+			break
+		}
+	}
+	if worst == nil {
+		return ctx, nil
+	}
+	// A request is counted once: when it is rejected, or when its last stage admits it.
+	if !worst.Allowed {
+		metrics.ObserveRateLimit(metrics.RateLimitLimited)
+	} else if stage == AfterAuth {
+		metrics.ObserveRateLimit(metrics.RateLimitAllowed)
+	}
+	return context.WithValue(ctx, resultKey{}, worst), worst
+}
+
+func (l *Limiter) take(ctx context.Context, p *Policy, key string) *Result {
+	if l.redis != nil && time.Now().UnixNano() >= l.redisRetryAt.Load() {
+		res, err := l.redis.take(ctx, p, key)
+		if err == nil {
+			return res
+		}
+		if ctx.Err() == nil {
+			l.redisRetryAt.Store(time.Now().Add(fallbackPeriod).UnixNano())
+			metrics.ObserveRateLimit(metrics.RateLimitError)
+			logpkg.FromContext(ctx).Warn("rate limit store failed, using in-process limiter",
+				zap.Error(err), zap.Duration("retry_in", fallbackPeriod))
+		}
+	}
+	return l.local.take(p, key, time.Now())
+}
+
+// stricter reports whether a should be reported instead of b: rejections first, the
+// longest wait among them, otherwise the fewest remaining requests.
+func stricter(a, b *Result) bool {
+	if a.Allowed != b.Allowed {
+		return !a.Allowed
+	}
+	if !a.Allowed {
+		return a.RetryAfter > b.RetryAfter
+	}
+	if a.Remaining != b.Remaining {
+		return a.Remaining < b.Remaining
+	}
+	return a.Reset > b.Reset
+}
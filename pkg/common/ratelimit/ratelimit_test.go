@@ -0,0 +1,53 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/emorenkov/scorehub/pkg/common/auth"
+	"github.com/emorenkov/scorehub/pkg/common/models"
+)
+
+func TestCheckStopsAtFirstRejection(t *testing.T) {
+	l, err := New(&models.RateLimitConfig{
+		Enabled: true,
+		Policies: `[{"name":"route","key":"route","algorithm":"sliding_window","limit":1,"period":"1m"},
+			{"name":"users","key":"user","algorithm":"sliding_window","limit":2,"period":"1m"}]`,
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	alice := &auth.Principal{Subject: "alice"}
+	check := func(route string) *Result {
+		_, res := l.Check(context.Background(), AfterAuth, &Request{Route: route, Principal: alice})
+		return res
+	}
+
+	if res := check("GET /a"); !res.Allowed || res.Remaining != 0 {
+		t.Fatalf("first call: %+v", res)
+	}
+	// Rejected by the route policy, so the user policy must keep its second request.
+	if res := check("GET /a"); res.Allowed || res.policy.Name != "route" {
+		t.Fatalf("second call: allowed=%v policy=%s", res.Allowed, res.policy.Name)
+	}
+	if res := check("GET /b"); !res.Allowed {
+		t.Fatalf("user quota was charged by a rejected request: %+v", res)
+	}
+}
+
+func TestCheckCombinesStages(t *testing.T) {
+	l, err := New(&models.RateLimitConfig{Enabled: true, RPS: 10, Burst: 20, IPRPS: 1, IPBurst: 3}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := &Request{IP: "203.0.113.9", Route: "GET /a"}
+	ctx, res := l.Check(context.Background(), BeforeAuth, req)
+	if !res.Allowed || res.policy.Name != "ip" || res.Remaining != 2 {
+		t.Fatalf("before auth: %+v", res)
+	}
+	req.Principal = &auth.Principal{Subject: "alice"}
+	// The per-client quota has more left, so the IP quota is still the one reported.
+	if _, res = l.Check(ctx, AfterAuth, req); res.policy.Name != "ip" {
+		t.Fatalf("after auth reported policy %s, want ip", res.policy.Name)
+	}
+}
@@ -0,0 +1,125 @@
+package ratelimit
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const keyPrefix = "ratelimit:"
+
+// Both scripts read the clock from Redis so replicas with skewed clocks share one
+// timeline, and return {allowed, remaining, retry_after_ms, reset_ms}.
+
+// tokenBucketScript keeps the bucket as a hash of its fractional token count and the time
+// it was last refilled. ARGV: capacity, tokens per millisecond. The key expires once the
+// bucket would be full again, which is the same as having no key.
+var tokenBucketScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1]) or capacity
+local ts = tonumber(state[2]) or now
+if now > ts then
+  tokens = math.min(capacity, tokens + (now - ts) * rate)
+  ts = now
+end
+
+local allowed = 0
+local retry = 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  allowed = 1
+else
+  retry = math.ceil((1 - tokens) / rate)
+end
+local reset = math.ceil((capacity - tokens) / rate)
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', ts)
+redis.call('PEXPIRE', KEYS[1], math.max(reset, 1))
+return {allowed, math.floor(tokens), retry, reset}
+`)
+
+// slidingWindowScript logs requests in a sorted set scored by time and drops those older
+// than the window. ARGV: limit, window in milliseconds, a unique member for this request.
+var slidingWindowScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+local count = redis.call('ZCARD', KEYS[1])
+local allowed = 0
+if count < limit then
+  redis.call('ZADD', KEYS[1], now, ARGV[3])
+  count = count + 1
+  allowed = 1
+end
+redis.call('PEXPIRE', KEYS[1], window)
+
+local reset = 0
+local oldest = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+if oldest[2] then
+  reset = tonumber(oldest[2]) + window - now
+end
+local retry = 0
+if allowed == 0 then
+  retry = reset
+end
+return {allowed, limit - count, retry, reset}
+`)
+
+type redisStore struct {
+	client *redis.Client
+}
+
+func (s *redisStore) take(ctx context.Context, p *Policy, key string) (*Result, error) {
+	var (
+		vals []int64
+		err  error
+	)
+	switch p.Algorithm {
+	case TokenBucket:
+		rate := float64(p.Limit) / float64(p.Period.Milliseconds())
+		vals, err = tokenBucketScript.Run(ctx, s.client, []string{keyPrefix + key},
+			p.Burst, strconv.FormatFloat(rate, 'g', -1, 64)).Int64Slice()
+	case SlidingWindow:
+		var member string
+		if member, err = requestID(); err != nil {
+			return nil, err
+		}
+		vals, err = slidingWindowScript.Run(ctx, s.client, []string{keyPrefix + key},
+			p.Limit, p.Period.Milliseconds(), member).Int64Slice()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(vals) != 4 {
+		return nil, fmt.Errorf("rate limit script returned %d values", len(vals))
+	}
+	return &Result{
+		Allowed:    vals[0] == 1,
+		Limit:      p.capacity(),
+		Remaining:  int(vals[1]),
+		RetryAfter: time.Duration(vals[2]) * time.Millisecond,
+		Reset:      time.Duration(vals[3]) * time.Millisecond,
+	}, nil
+}
+
+// requestID makes sliding-window members unique when requests share a millisecond.
+func requestID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,82 @@
+package ratelimit
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// testRedis connects to SCOREHUB_TEST_REDIS_ADDR, skipping the test when it is unset, and
+// returns a store and a key unique to the test.
+func testRedis(t *testing.T) (*redisStore, string) {
+	t.Helper()
+	addr := os.Getenv("SCOREHUB_TEST_REDIS_ADDR")
+	if addr == "" {
+		t.Skip("SCOREHUB_TEST_REDIS_ADDR is not set")
+	}
+	client := redis.NewClient(&redis.Options{Addr: addr})
+	t.Cleanup(func() { client.Close() })
+	id, err := requestID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := "test:" + t.Name() + ":" + id
+	t.Cleanup(func() { client.Del(context.Background(), keyPrefix+key) })
+	return &redisStore{client: client}, key
+}
+
+func TestRedisTokenBucket(t *testing.T) {
+	s, key := testRedis(t)
+	ctx := context.Background()
+	p := &Policy{Name: "tb", Algorithm: TokenBucket, Limit: 10, Period: time.Second, Burst: 3}
+
+	for i := 2; i >= 0; i-- {
+		res, err := s.take(ctx, p, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !res.Allowed || res.Remaining != i || res.Limit != 3 {
+			t.Fatalf("request %d: %+v", 3-i, res)
+		}
+	}
+	res, err := s.take(ctx, p, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Allowed || res.RetryAfter <= 0 || res.RetryAfter > 100*time.Millisecond {
+		t.Fatalf("over burst: %+v, want rejected with a retry within one refill", res)
+	}
+
+	// One token refills every 100ms.
+	time.Sleep(res.RetryAfter + 20*time.Millisecond)
+	if res, err = s.take(ctx, p, key); err != nil || !res.Allowed {
+		t.Fatalf("after refill: %+v, %v", res, err)
+	}
+}
+
+func TestRedisSlidingWindow(t *testing.T) {
+	s, key := testRedis(t)
+	ctx := context.Background()
+	p := &Policy{Name: "sw", Algorithm: SlidingWindow, Limit: 2, Period: 200 * time.Millisecond}
+
+	for i := 1; i >= 0; i-- {
+		res, err := s.take(ctx, p, key)
+		if err != nil || !res.Allowed || res.Remaining != i {
+			t.Fatalf("request %d: %+v, %v", 2-i, res, err)
+		}
+	}
+	res, err := s.take(ctx, p, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Allowed || res.RetryAfter <= 0 {
+		t.Fatalf("over limit: %+v", res)
+	}
+	time.Sleep(res.RetryAfter + 20*time.Millisecond)
+	if res, err = s.take(ctx, p, key); err != nil || !res.Allowed {
+		t.Fatalf("after the window: %+v, %v", res, err)
+	}
+}
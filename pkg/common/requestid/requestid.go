@@ -0,0 +1,157 @@
+// Package requestid carries a correlation ID for one request or event through
+// context.Context, HTTP headers, gRPC metadata and Kafka message headers.
+package requestid
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/segmentio/kafka-go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	// Header is the HTTP header clients may set and responses always carry.
+	Header = "X-Request-ID"
+	// Key is the gRPC metadata key and Kafka header used between services.
+	Key = "x-request-id"
+
+	maxLen = 128
+)
+
+type ctxKey struct{}
+
+// New generates a request ID.
+func New() string {
+	return uuid.NewString()
+}
+
+// NewContext returns ctx carrying id.
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, ctxKey{}, id)
+}
+
+// FromContext returns the request ID in ctx, or "" when there is none.
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(ctxKey{}).(string)
+	return id
+}
+
+// orNew returns id when it is safe to log and propagate, otherwise a fresh ID.
+func orNew(id string) string {
+	if id == "" || len(id) > maxLen {
+		return New()
+	}
+	for i := 0; i < len(id); i++ {
+		if c := id[i]; c < 0x21 || c > 0x7e {
+			return New()
+		}
+	}
+	return id
+}
+
+// EchoMiddleware accepts the caller's X-Request-ID or generates one, stores it in the
+// request context and echoes it on the response.
+func EchoMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			id := orNew(req.Header.Get(Header))
+			req.Header.Set(Header, id)
+			c.Response().Header().Set(Header, id)
+			c.SetRequest(req.WithContext(NewContext(req.Context(), id)))
+			return next(c)
+		}
+	}
+}
+
+// UnaryServerInterceptor reads x-request-id from the incoming metadata, generating one
+// when absent, and returns it in the response header metadata.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx = fromIncoming(ctx)
+		_ = grpc.SetHeader(ctx, metadata.Pairs(Key, FromContext(ctx)))
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor is the streaming counterpart of UnaryServerInterceptor.
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := fromIncoming(ss.Context())
+		_ = ss.SetHeader(metadata.Pairs(Key, FromContext(ctx)))
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// UnaryClientInterceptor forwards the request ID in ctx as outgoing metadata.
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(toOutgoing(ctx), method, req, reply, cc, opts...)
+	}
+}
+
+// StreamClientInterceptor forwards the request ID in ctx as outgoing metadata.
+func StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(toOutgoing(ctx), desc, cc, method, opts...)
+	}
+}
+
+func fromIncoming(ctx context.Context) context.Context {
+	var id string
+	if vals := metadata.ValueFromIncomingContext(ctx, Key); len(vals) > 0 {
+		id = vals[0]
+	}
+	return NewContext(ctx, orNew(id))
+}
+
+func toOutgoing(ctx context.Context) context.Context {
+	id := FromContext(ctx)
+	if id == "" {
+		return ctx
+	}
+	if md, ok := metadata.FromOutgoingContext(ctx); ok && len(md.Get(Key)) > 0 {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, Key, id)
+}
+
+// InjectKafka adds the request ID in ctx to headers, replacing any previous value.
+func InjectKafka(ctx context.Context, headers *[]kafka.Header) {
+	id := FromContext(ctx)
+	if id == "" {
+		return
+	}
+	for i, h := range *headers {
+		if h.Key == Key {
+			(*headers)[i].Value = []byte(id)
+			return
+		}
+	}
+	*headers = append(*headers, kafka.Header{Key: Key, Value: []byte(id)})
+}
+
+// FromKafka returns ctx carrying the request ID from msg's headers, or a new one for
+// messages produced without it.
+func FromKafka(ctx context.Context, msg kafka.Message) context.Context {
+	var id string
+	for _, h := range msg.Headers {
+		if h.Key == Key {
+			id = string(h.Value)
+			break
+		}
+	}
+	return NewContext(ctx, orNew(id))
+}
+
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
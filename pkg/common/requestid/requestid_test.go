@@ -0,0 +1,192 @@
+package requestid
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/segmentio/kafka-go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestOrNew(t *testing.T) {
+	for name, tc := range map[string]struct {
+		id   string
+		keep bool
+	}{
+		"valid":         {"req-42_a.b:c", true},
+		"longest":       {strings.Repeat("a", maxLen), true},
+		"empty":         {"", false},
+		"too long":      {strings.Repeat("a", maxLen+1), false},
+		"space":         {"req 42", false},
+		"newline":       {"req\n42", false},
+		"non-ascii":     {"réq", false},
+		"control chars": {"req\x0042", false},
+	} {
+		t.Run(name, func(t *testing.T) {
+			got := orNew(tc.id)
+			if tc.keep {
+				if got != tc.id {
+					t.Fatalf("replaced %q with %q", tc.id, got)
+				}
+				return
+			}
+			if _, err := uuid.Parse(got); err != nil {
+				t.Fatalf("replaced %q with %q, want a new UUID", tc.id, got)
+			}
+		})
+	}
+}
+
+func TestEchoMiddleware(t *testing.T) {
+	for name, tc := range map[string]struct {
+		header string
+		keep   bool
+	}{
+		"caller's id": {"abc-123", true},
+		"missing":     {"", false},
+		"invalid":     {"bad id", false},
+	} {
+		t.Run(name, func(t *testing.T) {
+			e := echo.New()
+			e.Use(EchoMiddleware())
+			var seen string
+			e.GET("/", func(c echo.Context) error {
+				seen = FromContext(c.Request().Context())
+				return c.NoContent(http.StatusOK)
+			})
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tc.header != "" {
+				req.Header.Set(Header, tc.header)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			got := rec.Header().Get(Header)
+			if got == "" || got != seen {
+				t.Fatalf("response id %q, handler saw %q", got, seen)
+			}
+			if tc.keep != (got == tc.header) {
+				t.Fatalf("id %q for header %q", got, tc.header)
+			}
+		})
+	}
+}
+
+// transportStream records the header metadata set by a unary interceptor.
+type transportStream struct {
+	grpc.ServerTransportStream
+	header metadata.MD
+}
+
+func (s *transportStream) SetHeader(md metadata.MD) error {
+	s.header = metadata.Join(s.header, md)
+	return nil
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	stream := &transportStream{}
+	ctx := grpc.NewContextWithServerTransportStream(
+		metadata.NewIncomingContext(context.Background(), metadata.Pairs(Key, "abc-123")), stream)
+	var seen string
+	_, err := UnaryServerInterceptor()(ctx, nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, _ any) (any, error) {
+		seen = FromContext(ctx)
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seen != "abc-123" {
+		t.Fatalf("handler saw %q, want the incoming id", seen)
+	}
+	if got := stream.header.Get(Key); len(got) != 1 || got[0] != "abc-123" {
+		t.Fatalf("response header %v", got)
+	}
+}
+
+// serverStreamStub is a server stream over ctx that records its header metadata.
+type serverStreamStub struct {
+	grpc.ServerStream
+	ctx    context.Context
+	header metadata.MD
+}
+
+func (s *serverStreamStub) Context() context.Context { return s.ctx }
+
+func (s *serverStreamStub) SetHeader(md metadata.MD) error {
+	s.header = metadata.Join(s.header, md)
+	return nil
+}
+
+func TestStreamServerInterceptorGeneratesID(t *testing.T) {
+	ss := &serverStreamStub{ctx: context.Background()}
+	var seen string
+	err := StreamServerInterceptor()(nil, ss, &grpc.StreamServerInfo{}, func(_ any, stream grpc.ServerStream) error {
+		seen = FromContext(stream.Context())
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := uuid.Parse(seen); err != nil {
+		t.Fatalf("handler saw %q, want a new UUID", seen)
+	}
+	if got := ss.header.Get(Key); len(got) != 1 || got[0] != seen {
+		t.Fatalf("response header %v, want [%s]", got, seen)
+	}
+}
+
+func TestUnaryClientInterceptor(t *testing.T) {
+	for name, tc := range map[string]struct {
+		ctx  context.Context
+		want []string
+	}{
+		"forwards": {NewContext(context.Background(), "abc-123"), []string{"abc-123"}},
+		"keeps an explicit id": {
+			metadata.AppendToOutgoingContext(NewContext(context.Background(), "abc-123"), Key, "explicit"),
+			[]string{"explicit"},
+		},
+		"none": {context.Background(), nil},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var got []string
+			err := UnaryClientInterceptor()(tc.ctx, "/test/Method", nil, nil, nil,
+				func(ctx context.Context, _ string, _, _ any, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+					md, _ := metadata.FromOutgoingContext(ctx)
+					got = md.Get(Key)
+					return nil
+				})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Join(got, ",") != strings.Join(tc.want, ",") {
+				t.Fatalf("outgoing %s %v, want %v", Key, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestKafkaRoundTrip(t *testing.T) {
+	headers := []kafka.Header{{Key: Key, Value: []byte("stale")}, {Key: "traceparent", Value: []byte("tp")}}
+	InjectKafka(NewContext(context.Background(), "abc-123"), &headers)
+	if len(headers) != 2 || string(headers[0].Value) != "abc-123" {
+		t.Fatalf("headers %v, want the stale id replaced", headers)
+	}
+	if got := FromContext(FromKafka(context.Background(), kafka.Message{Headers: headers})); got != "abc-123" {
+		t.Fatalf("consumed id %q", got)
+	}
+
+	InjectKafka(context.Background(), &headers)
+	if len(headers) != 2 || string(headers[0].Value) != "abc-123" {
+		t.Fatalf("a context without an id changed the headers: %v", headers)
+	}
+	got := FromContext(FromKafka(context.Background(), kafka.Message{}))
+	if _, err := uuid.Parse(got); err != nil {
+		t.Fatalf("message without an id consumed as %q, want a new UUID", got)
+	}
+}
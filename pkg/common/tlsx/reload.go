@@ -0,0 +1,125 @@
+package tlsx
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	logpkg "github.com/emorenkov/scorehub/pkg/common/logger"
+	"go.uber.org/zap"
+)
+
+// reloadInterval is how often files are checked for changes, at most once per handshake.
+// It is a variable so tests can check on every handshake.
+var reloadInterval = 10 * time.Second
+
+// reloadable holds a value parsed from files and re-parses it when their size or
+// modification time changes. A failed reload keeps serving the previous value, so a
+// half-written certificate does not take the service down.
+type reloadable[T any] struct {
+	paths []string
+	load  func() (T, error)
+
+	mu      sync.Mutex
+	val     T
+	stamp   string
+	checked time.Time
+}
+
+func newReloadable[T any](load func() (T, error), paths ...string) (*reloadable[T], error) {
+	r := &reloadable[T]{paths: paths, load: load}
+	st, err := stamp(paths)
+	if err != nil {
+		return nil, err
+	}
+	if r.val, err = load(); err != nil {
+		return nil, err
+	}
+	r.stamp = st
+	r.checked = time.Now()
+	return r, nil
+}
+
+func (r *reloadable[T]) get() T {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if time.Since(r.checked) < reloadInterval {
+		return r.val
+	}
+	r.checked = time.Now()
+	st, err := stamp(r.paths)
+	if err != nil || st == r.stamp {
+		return r.val
+	}
+	val, err := r.load()
+	if err != nil {
+		logpkg.FromContext(context.Background()).Warn("tls reload failed, keeping previous files",
+			zap.Error(err), zap.Strings("files", r.paths))
+		return r.val
+	}
+	r.val, r.stamp = val, st
+	logpkg.FromContext(context.Background()).Info("tls files reloaded", zap.Strings("files", r.paths))
+	return r.val
+}
+
+func stamp(paths []string) (string, error) {
+	var b strings.Builder
+	for _, p := range paths {
+		fi, err := os.Stat(p)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&b, "%d:%d;", fi.ModTime().UnixNano(), fi.Size())
+	}
+	return b.String(), nil
+}
+
+func loadKeyPair(certFile, keyFile string) func() (*tls.Certificate, error) {
+	return func() (*tls.Certificate, error) {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load key pair %s: %w", certFile, err)
+		}
+		return &cert, nil
+	}
+}
+
+func loadPool(caFile string) func() (*x509.CertPool, error) {
+	return func() (*x509.CertPool, error) {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates in %s", caFile)
+		}
+		return pool, nil
+	}
+}
+
+// verify checks the peer chain against roots; the standard verification cannot be used
+// because it would pin the pool loaded at startup.
+func verify(certs []*x509.Certificate, roots *x509.CertPool, dnsName string, usage x509.ExtKeyUsage) error {
+	if len(certs) == 0 {
+		return errors.New("tls: peer sent no certificate")
+	}
+	intermediates := x509.NewCertPool()
+	for _, c := range certs[1:] {
+		intermediates.AddCert(c)
+	}
+	_, err := certs[0].Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		DNSName:       dnsName,
+		KeyUsages:     []x509.ExtKeyUsage{usage},
+	})
+	return err
+}
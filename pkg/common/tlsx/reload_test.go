@@ -0,0 +1,111 @@
+package tlsx
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// reloadOnEveryGet makes reloadables check their files on every get for the test.
+func reloadOnEveryGet(t *testing.T) {
+	t.Helper()
+	prev := reloadInterval
+	reloadInterval = 0
+	t.Cleanup(func() { reloadInterval = prev })
+}
+
+// rewrite replaces path's content and moves its modification time forward, so the change
+// is seen even on filesystems with coarse timestamps.
+func rewrite(t *testing.T, path string, data []byte) {
+	t.Helper()
+	mtime := time.Now()
+	if fi, err := os.Stat(path); err == nil {
+		mtime = fi.ModTime().Add(time.Second)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// readValid loads path, failing on content that says "bad".
+func readValid(path string) func() (string, error) {
+	return func() (string, error) {
+		b, err := os.ReadFile(path)
+		if string(b) == "bad" {
+			return "", errors.New("bad content")
+		}
+		return string(b), err
+	}
+}
+
+func TestReloadableGet(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "value")
+	rewrite(t, path, []byte("first"))
+	r, err := newReloadable(readValid(path), path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rewrite(t, path, []byte("second"))
+	if got := r.get(); got != "first" {
+		t.Fatalf("got %q within the reload interval, want first", got)
+	}
+
+	reloadOnEveryGet(t)
+	if got := r.get(); got != "second" {
+		t.Fatalf("got %q after the change, want second", got)
+	}
+	rewrite(t, path, []byte("bad"))
+	if got := r.get(); got != "second" {
+		t.Fatalf("got %q after a failed reload, want the previous value", got)
+	}
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if got := r.get(); got != "second" {
+		t.Fatalf("got %q with the file missing, want the previous value", got)
+	}
+	rewrite(t, path, []byte("third"))
+	if got := r.get(); got != "third" {
+		t.Fatalf("got %q once the file is back, want third", got)
+	}
+}
+
+func TestNewReloadableFailsOnInvalidFiles(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := newReloadable(readValid(filepath.Join(dir, "missing")), filepath.Join(dir, "missing")); err == nil {
+		t.Fatal("loaded a missing file")
+	}
+	path := filepath.Join(dir, "value")
+	rewrite(t, path, []byte("bad"))
+	if _, err := newReloadable(readValid(path), path); err == nil {
+		t.Fatal("loaded invalid content")
+	}
+}
+
+func TestStamp(t *testing.T) {
+	dir := t.TempDir()
+	a, b := filepath.Join(dir, "a"), filepath.Join(dir, "b")
+	rewrite(t, a, []byte("a"))
+	rewrite(t, b, []byte("b"))
+
+	before, err := stamp([]string{a, b})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := stamp([]string{a, b}); again != before {
+		t.Fatalf("stamp changed without a change: %q, %q", before, again)
+	}
+	rewrite(t, b, []byte("b"))
+	if after, _ := stamp([]string{a, b}); after == before {
+		t.Fatal("stamp unchanged after rewriting a file")
+	}
+	if _, err := stamp([]string{a, filepath.Join(dir, "missing")}); err == nil {
+		t.Fatal("stamped a missing file")
+	}
+}
@@ -0,0 +1,103 @@
+// Package tlsx builds TLS configurations from models.TLSConfig. Certificates, keys and CA
+// bundles are re-read when the files change, so rotated certificates are picked up
+// without a restart.
+package tlsx
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+
+	"github.com/emorenkov/scorehub/pkg/common/models"
+	"github.com/labstack/echo/v4"
+)
+
+// Server returns a server configuration presenting cfg's certificate. When cfg.CAFile is
+// set, clients must present a certificate signed by it (mutual TLS). It returns nil when
+// cfg has no certificate, which means plaintext.
+func Server(cfg *models.TLSConfig) (*tls.Config, error) {
+	if cfg == nil || cfg.CertFile == "" {
+		return nil, nil
+	}
+	if cfg.KeyFile == "" {
+		return nil, errors.New("tls key file is required with a certificate")
+	}
+	pair, err := newReloadable(loadKeyPair(cfg.CertFile, cfg.KeyFile), cfg.CertFile, cfg.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return pair.get(), nil
+		},
+	}
+	if cfg.CAFile != "" {
+		cas, err := newReloadable(loadPool(cfg.CAFile), cfg.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		c.ClientAuth = tls.RequireAnyClientCert
+		c.VerifyConnection = func(cs tls.ConnectionState) error {
+			return verify(cs.PeerCertificates, cas.get(), "", x509.ExtKeyUsageClientAuth)
+		}
+	}
+	return c, nil
+}
+
+// Client returns a client configuration that verifies the server against cfg.CAFile (or
+// the system roots) and presents cfg's certificate when one is set. It returns nil when
+// cfg is empty, which means plaintext.
+func Client(cfg *models.TLSConfig) (*tls.Config, error) {
+	if cfg == nil || (cfg.CertFile == "" && cfg.CAFile == "" && cfg.ServerName == "") {
+		return nil, nil
+	}
+	c := &tls.Config{MinVersion: tls.VersionTLS12, ServerName: cfg.ServerName}
+	if cfg.CertFile != "" {
+		if cfg.KeyFile == "" {
+			return nil, errors.New("tls key file is required with a certificate")
+		}
+		pair, err := newReloadable(loadKeyPair(cfg.CertFile, cfg.KeyFile), cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		c.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return pair.get(), nil
+		}
+	}
+	if cfg.CAFile != "" {
+		cas, err := newReloadable(loadPool(cfg.CAFile), cfg.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		// Verification is done in VerifyConnection against the current pool.
+		c.InsecureSkipVerify = true
+		c.VerifyConnection = func(cs tls.ConnectionState) error {
+			return verify(cs.PeerCertificates, cas.get(), cs.ServerName, x509.ExtKeyUsageServerAuth)
+		}
+	}
+	return c, nil
+}
+
+// StartEcho serves e on addr, over TLS when cfg is not nil.
+func StartEcho(e *echo.Echo, addr string, cfg *tls.Config) error {
+	if cfg == nil {
+		return e.Start(addr)
+	}
+	e.TLSServer.Addr = addr
+	e.TLSServer.TLSConfig = cfg
+	return e.StartServer(e.TLSServer)
+}
+
+// SelfClient returns the configuration a process uses to dial its own gRPC listener set
+// up from cfg, e.g. for the gateway: it presents the listener's certificate and trusts
+// cfg.CAFile, so that CA must also sign the server certificate. The server certificate
+// must name cfg.ServerName or, when that is empty, the dialed host (usually localhost).
+// It returns nil when the listener is plaintext.
+func SelfClient(cfg *models.TLSConfig) (*tls.Config, error) {
+	if cfg == nil || cfg.CertFile == "" {
+		return nil, nil
+	}
+	return Client(cfg)
+}
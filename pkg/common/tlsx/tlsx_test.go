@@ -0,0 +1,244 @@
+package tlsx
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/emorenkov/scorehub/pkg/common/models"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T, name string) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue returns a PEM certificate and key for localhost signed by ca.
+func (ca *testCA) issue(t *testing.T, serial int64, usage x509.ExtKeyUsage) (certPEM, keyPEM []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+// writePair writes a certificate issued by ca to dir/name.crt and dir/name.key.
+func (ca *testCA) writePair(t *testing.T, dir, name string, serial int64, usage x509.ExtKeyUsage) *models.TLSConfig {
+	t.Helper()
+	cfg := &models.TLSConfig{CertFile: filepath.Join(dir, name+".crt"), KeyFile: filepath.Join(dir, name+".key")}
+	certPEM, keyPEM := ca.issue(t, serial, usage)
+	rewrite(t, cfg.CertFile, certPEM)
+	rewrite(t, cfg.KeyFile, keyPEM)
+	return cfg
+}
+
+// listen serves TLS handshakes with cfg, writing one byte on each accepted connection so
+// clients learn whether the server accepted their certificate.
+func listen(t *testing.T, cfg *tls.Config) string {
+	t.Helper()
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				if conn.(*tls.Conn).Handshake() == nil {
+					conn.Write([]byte{1})
+				}
+			}()
+		}
+	}()
+	return ln.Addr().String()
+}
+
+// handshake connects to addr with cfg and returns the certificate the server presented.
+func handshake(t *testing.T, addr string, cfg *tls.Config) (*x509.Certificate, error) {
+	t.Helper()
+	conn, err := tls.DialWithDialer(&net.Dialer{Timeout: 5 * time.Second}, "tcp", addr, cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	if _, err := conn.Read(make([]byte, 1)); err != nil {
+		return nil, err
+	}
+	return conn.ConnectionState().PeerCertificates[0], nil
+}
+
+func TestServerPresentsRotatedCertificate(t *testing.T) {
+	reloadOnEveryGet(t)
+	dir := t.TempDir()
+	ca := newTestCA(t, "ca")
+	rewrite(t, filepath.Join(dir, "ca.crt"), ca.pem)
+
+	serverCfg := ca.writePair(t, dir, "server", 1, x509.ExtKeyUsageServerAuth)
+	server, err := Server(serverCfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := Client(&models.TLSConfig{CAFile: filepath.Join(dir, "ca.crt"), ServerName: "localhost"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listen(t, server)
+
+	cert, err := handshake(t, addr, client)
+	if err != nil || cert.SerialNumber.Int64() != 1 {
+		t.Fatalf("got %v, %v, want certificate 1", cert, err)
+	}
+	ca.writePair(t, dir, "server", 2, x509.ExtKeyUsageServerAuth)
+	cert, err = handshake(t, addr, client)
+	if err != nil || cert.SerialNumber.Int64() != 2 {
+		t.Fatalf("got %v, %v, want the rotated certificate 2", cert, err)
+	}
+}
+
+func TestMutualTLS(t *testing.T) {
+	reloadOnEveryGet(t)
+	dir := t.TempDir()
+	ca, other := newTestCA(t, "ca"), newTestCA(t, "other")
+	caFile := filepath.Join(dir, "ca.crt")
+	rewrite(t, caFile, ca.pem)
+
+	serverCfg := ca.writePair(t, dir, "server", 1, x509.ExtKeyUsageServerAuth)
+	serverCfg.CAFile = caFile
+	server, err := Server(serverCfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listen(t, server)
+
+	clientFor := func(cfg *models.TLSConfig) *tls.Config {
+		t.Helper()
+		cfg.CAFile, cfg.ServerName = caFile, "localhost"
+		c, err := Client(cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+	trusted := clientFor(ca.writePair(t, dir, "client", 10, x509.ExtKeyUsageClientAuth))
+	untrusted := clientFor(other.writePair(t, dir, "intruder", 11, x509.ExtKeyUsageClientAuth))
+	serverCert := clientFor(ca.writePair(t, dir, "wrong-usage", 12, x509.ExtKeyUsageServerAuth))
+	anonymous := clientFor(&models.TLSConfig{})
+
+	if _, err := handshake(t, addr, trusted); err != nil {
+		t.Fatalf("client signed by the CA: %v", err)
+	}
+	for name, c := range map[string]*tls.Config{"other CA": untrusted, "server usage": serverCert, "no certificate": anonymous} {
+		if _, err := handshake(t, addr, c); err == nil {
+			t.Errorf("%s: accepted", name)
+		}
+	}
+
+	// Trusting the other CA as well takes effect without rebuilding the server config.
+	rewrite(t, caFile, append(append([]byte{}, ca.pem...), other.pem...))
+	if _, err := handshake(t, addr, untrusted); err != nil {
+		t.Fatalf("client of the newly trusted CA: %v", err)
+	}
+}
+
+func TestClientRejectsUntrustedServer(t *testing.T) {
+	dir := t.TempDir()
+	ca, other := newTestCA(t, "ca"), newTestCA(t, "other")
+	rewrite(t, filepath.Join(dir, "ca.crt"), ca.pem)
+
+	server, err := Server(other.writePair(t, dir, "server", 1, x509.ExtKeyUsageServerAuth))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := Client(&models.TLSConfig{CAFile: filepath.Join(dir, "ca.crt"), ServerName: "localhost"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := handshake(t, listen(t, server), client); err == nil {
+		t.Fatal("client accepted a server signed by another CA")
+	}
+	// The name is checked too, although InsecureSkipVerify disables the standard check.
+	server, err = Server(ca.writePair(t, dir, "server", 2, x509.ExtKeyUsageServerAuth))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.ServerName = "user-service"
+	if _, err := handshake(t, listen(t, server), client); err == nil {
+		t.Fatal("client accepted a certificate for another name")
+	}
+}
+
+func TestNilConfigs(t *testing.T) {
+	for name, cfg := range map[string]*models.TLSConfig{"nil": nil, "empty": {}} {
+		if c, err := Server(cfg); c != nil || err != nil {
+			t.Errorf("Server(%s) = %v, %v, want plaintext", name, c, err)
+		}
+		if c, err := Client(cfg); c != nil || err != nil {
+			t.Errorf("Client(%s) = %v, %v, want plaintext", name, c, err)
+		}
+	}
+	if _, err := Server(&models.TLSConfig{CertFile: "server.crt"}); err == nil {
+		t.Error("Server accepted a certificate without a key")
+	}
+}
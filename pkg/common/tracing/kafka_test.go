@@ -0,0 +1,83 @@
+package tracing
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestHeaderCarrier(t *testing.T) {
+	headers := []kafka.Header{{Key: "request-id", Value: []byte("r1")}, {Key: "traceparent", Value: []byte("stale")}}
+	c := HeaderCarrier{Headers: &headers}
+
+	c.Set("traceparent", "fresh")
+	c.Set("tracestate", "k=v")
+	if got := c.Get("traceparent"); got != "fresh" {
+		t.Fatalf("traceparent %q, want the replaced value", got)
+	}
+	if got := c.Keys(); !slices.Equal(got, []string{"request-id", "traceparent", "tracestate"}) {
+		t.Fatalf("keys %v", got)
+	}
+	if got := c.Get("missing"); got != "" {
+		t.Fatalf("missing header read as %q", got)
+	}
+}
+
+// TestKafkaTraceRoundTrip injects a producer span into message headers and checks that
+// the consumer span continues its trace.
+func TestKafkaTraceRoundTrip(t *testing.T) {
+	rec := recordSpans(t)
+	ctx, producer := StartProducerSpan(context.Background(), "score-events", 1)
+	msg := kafka.Message{Topic: "score-events", Partition: 2, Offset: 41, Key: []byte("user:7"),
+		Headers: []kafka.Header{{Key: "traceparent", Value: []byte("00-stale")}}}
+	InjectKafka(ctx, &msg.Headers)
+	producer.End()
+
+	// A re-published message carries one traceparent: the current span's.
+	var values []string
+	for _, h := range msg.Headers {
+		if h.Key == "traceparent" {
+			values = append(values, string(h.Value))
+		}
+	}
+	if want := traceParentOf(producer.SpanContext()); len(values) != 1 || values[0] != want {
+		t.Fatalf("traceparent headers %q, want [%s]", values, want)
+	}
+
+	_, consumer := StartConsumerSpan(context.Background(), msg, "notification-service")
+	consumer.End()
+
+	spans := rec.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("recorded %d spans, want 2", len(spans))
+	}
+	got := spans[1]
+	if got.SpanKind() != trace.SpanKindConsumer || got.Name() != "process score-events" {
+		t.Fatalf("consumer span %q of kind %v", got.Name(), got.SpanKind())
+	}
+	if got.Parent().SpanID() != producer.SpanContext().SpanID() || got.SpanContext().TraceID() != producer.SpanContext().TraceID() {
+		t.Fatalf("consumer span parent %v, want the producer span %v", got.Parent(), producer.SpanContext())
+	}
+	for _, want := range []attribute.KeyValue{
+		attribute.String("messaging.consumer.group.name", "notification-service"),
+		attribute.String("messaging.destination.partition.id", "2"),
+		attribute.Int64("messaging.kafka.offset", 41),
+	} {
+		if !slices.Contains(got.Attributes(), want) {
+			t.Fatalf("consumer span lacks %v: %v", want, got.Attributes())
+		}
+	}
+}
+
+func TestConsumerSpanWithoutTraceContextStartsATrace(t *testing.T) {
+	rec := recordSpans(t)
+	_, span := StartConsumerSpan(context.Background(), kafka.Message{Topic: "score-events"}, "email-service")
+	span.End()
+	if got := rec.Ended()[0]; got.Parent().IsValid() || !got.SpanContext().IsValid() {
+		t.Fatalf("span %v has parent %v, want a new root", got.SpanContext(), got.Parent())
+	}
+}
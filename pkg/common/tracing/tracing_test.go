@@ -0,0 +1,70 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// recordSpans installs the W3C propagator and a tracer provider that records every span,
+// restoring the previous globals when the test ends.
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	prevProvider, prevPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	rec := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prevProvider)
+		otel.SetTextMapPropagator(prevPropagator)
+	})
+	return rec
+}
+
+// traceParentOf formats sc the way the W3C propagator writes a sampled span.
+func traceParentOf(sc trace.SpanContext) string {
+	return fmt.Sprintf("00-%s-%s-01", sc.TraceID(), sc.SpanID())
+}
+
+func TestTraceParentRoundTrip(t *testing.T) {
+	recordSpans(t)
+	ctx, span := Tracer().Start(context.Background(), "store")
+	defer span.End()
+
+	tp := TraceParent(ctx)
+	if want := traceParentOf(span.SpanContext()); tp != want {
+		t.Fatalf("traceparent %q, want %q", tp, want)
+	}
+	restored := trace.SpanContextFromContext(WithTraceParent(context.Background(), tp))
+	if !restored.IsRemote() || restored.TraceID() != span.SpanContext().TraceID() ||
+		restored.SpanID() != span.SpanContext().SpanID() || !restored.IsSampled() {
+		t.Fatalf("restored %+v from %q", restored, tp)
+	}
+}
+
+func TestTraceParentWithoutSpan(t *testing.T) {
+	if tp := TraceParent(context.Background()); tp != "" {
+		t.Fatalf("traceparent %q without a span, want empty", tp)
+	}
+	ctx := context.Background()
+	if WithTraceParent(ctx, "") != ctx {
+		t.Fatal("an empty traceparent changed the context")
+	}
+	if sc := trace.SpanContextFromContext(WithTraceParent(ctx, "00-garbage-01")); sc.IsValid() {
+		t.Fatalf("malformed traceparent produced %+v", sc)
+	}
+}
+
+func TestSampleRatio(t *testing.T) {
+	for percent, want := range map[int]float64{-5: 0, 0: 0, 25: 0.25, 100: 1, 250: 1} {
+		if got := sampleRatio(percent); got != want {
+			t.Errorf("sampleRatio(%d) = %v, want %v", percent, got, want)
+		}
+	}
+}
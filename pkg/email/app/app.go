@@ -5,36 +5,122 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/emorenkov/scorehub/pkg/common/apikeys"
+	"github.com/emorenkov/scorehub/pkg/common/auth"
+	"github.com/emorenkov/scorehub/pkg/common/db"
+	"github.com/emorenkov/scorehub/pkg/common/dlq"
+	"github.com/emorenkov/scorehub/pkg/common/grpcx"
+	"github.com/emorenkov/scorehub/pkg/common/health"
 	ckafka "github.com/emorenkov/scorehub/pkg/common/kafka"
 	logpkg "github.com/emorenkov/scorehub/pkg/common/logger"
+	"github.com/emorenkov/scorehub/pkg/common/ratelimit"
+	"github.com/emorenkov/scorehub/pkg/common/tlsx"
 	"github.com/emorenkov/scorehub/pkg/email/config"
 	"github.com/emorenkov/scorehub/pkg/email/repository"
 	"github.com/emorenkov/scorehub/pkg/email/rest"
 	"github.com/emorenkov/scorehub/pkg/email/service"
 	"github.com/emorenkov/scorehub/pkg/notification"
+	userpb "github.com/emorenkov/scorehub/pkg/user/models/proto"
+	"github.com/redis/go-redis/v9"
+	"github.com/segmentio/kafka-go"
 	"go.uber.org/zap"
 	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc"
+	"gorm.io/gorm"
 )
 
 type App struct {
-	cfg        *config.Config
-	restServer *rest.Server
-	consumer   *ckafka.Consumer
-	svc        service.Email
-	cancel     context.CancelFunc
+	cfg         *config.Config
+	restServer  *rest.Server
+	consumer    *ckafka.Consumer
+	deadLetters *dlq.Forwarder
+	dlqAdmin    *dlq.Admin
+	svc         service.Email
+	userConn    *grpc.ClientConn
+	db          *gorm.DB
+	redis       *redis.Client
+	cancel      context.CancelFunc
 }
 
 func New(cfg *config.Config) (*App, error) {
-	sender := repository.NewLoggerSender(logpkg.Log)
-	svc := service.NewEmail(sender)
-	restServer := rest.NewServer(cfg, svc, logpkg.Log)
+	userTLS, err := tlsx.Client(cfg.UserServiceTLS)
+	if err != nil {
+		return nil, fmt.Errorf("init user service tls: %w", err)
+	}
+	userConn, err := grpc.Dial(cfg.UserServiceAddr, grpcx.DialOptions(grpcx.ClientConfig{
+		APIKey:  cfg.UserServiceAPIKey,
+		Timeout: cfg.UserLookupTimeout,
+		TLS:     userTLS,
+	})...)
+	if err != nil {
+		return nil, fmt.Errorf("dial user service: %w", err)
+	}
+	users := repository.NewUserDirectory(userpb.NewUserServiceClient(userConn), cfg.UserLookupTimeout)
+
+	sender := repository.NewLoggerSender()
+	svc := service.NewEmail(sender, users)
+	checks := health.NewRegistry(0)
+	checks.Add("kafka", health.Kafka(cfg.KafkaBrokers))
+	checks.Add("user_service", health.GRPCConn(userConn))
+
+	dlqAdmin := dlq.NewAdmin(cfg.KafkaBrokers)
+	httpTLS, err := tlsx.Server(cfg.HTTPTLS)
+	if err != nil {
+		return nil, fmt.Errorf("init http tls: %w", err)
+	}
+
+	var keysDB *gorm.DB
+	var keys auth.KeyLookup
+	var keysHandler *apikeys.Handler
+	if cfg.APIKeys.Enabled {
+		if keysDB, err = db.NewPostgresDB(cfg.DbConfig); err != nil {
+			return nil, fmt.Errorf("init db: %w", err)
+		}
+		checks.Add("postgres", health.DB(keysDB))
+		cache, h := apikeys.New(keysDB, cfg.APIKeys)
+		keys, keysHandler = cache, h
+	}
+	authn, err := auth.FromConfig(context.Background(), cfg.APIKey, keys, cfg.Auth)
+	if err != nil {
+		return nil, fmt.Errorf("init auth: %w", err)
+	}
+
+	var redisClient *redis.Client
+	if cfg.RateLimit.Enabled && cfg.RedisConfig.RedisAddr != "" {
+		redisClient = redis.NewClient(&redis.Options{
+			Addr:     cfg.RedisConfig.RedisAddr,
+			Password: cfg.RedisConfig.RedisPassword,
+			DB:       cfg.RedisConfig.RedisDB,
+		})
+	}
+	limiter, err := ratelimit.New(cfg.RateLimit, redisClient)
+	if err != nil {
+		return nil, fmt.Errorf("init rate limiter: %w", err)
+	}
+
+	restServer := rest.NewServer(rest.Deps{
+		Config:      cfg,
+		Service:     svc,
+		DeadLetters: dlq.NewHandler(dlqAdmin, []string{cfg.NotificationsTopic}),
+		Keys:        keysHandler,
+		Authn:       authn,
+		Limiter:     limiter,
+		Checks:      checks,
+		TLS:         httpTLS,
+		Log:         logpkg.Log,
+	})
 	consumer := ckafka.NewConsumerWithBrokers(cfg.KafkaBrokers, cfg.NotificationsTopic, cfg.KafkaGroupID)
 
 	return &App{
-		cfg:        cfg,
-		restServer: restServer,
-		consumer:   consumer,
-		svc:        svc,
+		cfg:         cfg,
+		restServer:  restServer,
+		consumer:    consumer,
+		deadLetters: dlq.NewForwarder(cfg.KafkaBrokers, cfg.KafkaGroupID),
+		dlqAdmin:    dlqAdmin,
+		svc:         svc,
+		userConn:    userConn,
+		db:          keysDB,
+		redis:       redisClient,
 	}, nil
 }
 
@@ -50,30 +136,33 @@ func (a *App) Run() <-chan error {
 	}()
 
 	go func() {
-		logpkg.Log.Info("starting notifications consumer", zap.String("topic", a.cfg.NotificationsTopic))
-		for {
-			msg, err := a.consumer.ReadMessage(ctx)
-			if err != nil {
-				if ctx.Err() != nil {
-					return
-				}
-				errCh <- fmt.Errorf("consume notification: %w", err)
-				return
-			}
-			var notif notification.NotificationMessage
-			if err := json.Unmarshal(msg.Value, &notif); err != nil {
-				logpkg.Log.Error("failed to unmarshal notification", zap.Error(err))
-				continue
-			}
-			if err := a.svc.Send(ctx, notif.UserID, notif.Message); err != nil {
-				logpkg.Log.Error("failed to send email", zap.Error(err))
-			}
+		logpkg.FromContext(ctx).Info("starting notifications consumer", zap.String("topic", a.cfg.NotificationsTopic))
+		err := a.consumer.Run(ctx, a.handleNotification, ckafka.RunOptions{
+			Retry:     ckafka.RetryPolicyFromConfig(a.cfg.ConsumerRetry),
+			OnFailure: a.deadLetters.FailureHandler(),
+		})
+		if err != nil {
+			errCh <- fmt.Errorf("consume notification: %w", err)
 		}
 	}()
 
 	return errCh
 }
 
+func (a *App) handleNotification(ctx context.Context, msg kafka.Message) error {
+	var notif notification.NotificationMessage
+	if err := json.Unmarshal(msg.Value, &notif); err != nil {
+		return ckafka.Permanent(fmt.Errorf("unmarshal notification: %w", err))
+	}
+	// Messages without a channel predate notification rules and were always emailed.
+	if notif.Channel != "" && notif.Channel != notification.ChannelEmail {
+		return nil
+	}
+	// Messages without an email address are sent to the user's address in user-service;
+	// unknown users go to the DLQ and user-service outages are retried.
+	return a.svc.Send(ctx, notif.UserID, notif.Email, notif.Message)
+}
+
 func (a *App) Shutdown(ctx context.Context) error {
 	if a.cancel != nil {
 		a.cancel()
@@ -89,5 +178,35 @@ func (a *App) Shutdown(ctx context.Context) error {
 		return a.consumer.Close()
 	})
 
+	g.Go(func() error {
+		return a.deadLetters.Close()
+	})
+
+	g.Go(func() error {
+		return a.dlqAdmin.Close()
+	})
+
+	g.Go(func() error {
+		return a.userConn.Close()
+	})
+
+	g.Go(func() error {
+		if a.redis != nil {
+			return a.redis.Close()
+		}
+		return nil
+	})
+
+	g.Go(func() error {
+		if a.db == nil {
+			return nil
+		}
+		sqlDB, err := a.db.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.Close()
+	})
+
 	return g.Wait()
 }
@@ -3,6 +3,9 @@ package config
 import (
 	"os"
 	"strings"
+	"time"
+
+	"github.com/emorenkov/scorehub/pkg/common/models"
 )
 
 type Config struct {
@@ -12,6 +15,25 @@ type Config struct {
 	KafkaBrokers       []string
 	KafkaGroupID       string
 	NotificationsTopic string
+	ConsumerRetry      *models.ConsumerRetryConfig
+	// UserServiceAddr is dialled to look up the address of notifications sent without one.
+	UserServiceAddr string
+	// UserServiceAPIKey is sent as x-api-key on calls to user-service.
+	UserServiceAPIKey string
+	// UserServiceTLS secures the connection to user-service; a certificate enables mutual TLS.
+	UserServiceTLS *models.TLSConfig
+	// UserLookupTimeout bounds each user-service call.
+	UserLookupTimeout time.Duration
+	// RedisConfig shares rate limit counters between replicas.
+	RedisConfig *models.RedisConfig
+	RateLimit   *models.RateLimitConfig
+	Tracing     *models.TracingConfig
+	// HTTPTLS serves the REST API over HTTPS when a certificate is set.
+	HTTPTLS *models.TLSConfig
+	Auth    *models.AuthConfig
+	APIKeys *models.APIKeysConfig
+	// DbConfig is only used to look up API keys when APIKeys.Enabled is set.
+	DbConfig *models.PostgresConfig
 }
 
 func Load() *Config {
@@ -22,6 +44,18 @@ func Load() *Config {
 		KafkaBrokers:       splitAndTrim(getEnv("KAFKA_BROKERS", "localhost:9092")),
 		KafkaGroupID:       getEnv("KAFKA_GROUP_ID", "scorehub-group"),
 		NotificationsTopic: getEnv("NOTIFICATIONS_TOPIC", "notifications"),
+		ConsumerRetry:      models.LoadConsumerRetryConfig(),
+		UserServiceAddr:    getEnv("USER_SERVICE_ADDR", "localhost:50051"),
+		UserServiceAPIKey:  getEnv("USER_SERVICE_API_KEY", ""),
+		UserServiceTLS:     models.LoadTLSConfig("USER_SERVICE_"),
+		UserLookupTimeout:  time.Duration(models.GetEnvAsInt("USER_LOOKUP_TIMEOUT_MS", 2000)) * time.Millisecond,
+		RedisConfig:        models.LoadRedisConfig(),
+		RateLimit:          models.LoadRateLimitConfig(),
+		Tracing:            models.LoadTracingConfig(),
+		HTTPTLS:            models.LoadTLSConfig("HTTP_"),
+		Auth:               models.LoadAuthConfig(),
+		APIKeys:            models.LoadAPIKeysConfig(),
+		DbConfig:           models.LoadPostgresConfig(),
 	}
 }
 
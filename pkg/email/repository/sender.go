@@ -3,23 +3,22 @@ package repository
 import (
 	"context"
 
+	logpkg "github.com/emorenkov/scorehub/pkg/common/logger"
 	"go.uber.org/zap"
 )
 
 type Sender interface {
-	Send(ctx context.Context, userID int64, message string) error
+	Send(ctx context.Context, userID int64, to, message string) error
 }
 
 // LoggerSender simulates email delivery by logging the payload.
-type LoggerSender struct {
-	log *zap.Logger
-}
+type LoggerSender struct{}
 
-func NewLoggerSender(log *zap.Logger) *LoggerSender {
-	return &LoggerSender{log: log}
+func NewLoggerSender() *LoggerSender {
+	return &LoggerSender{}
 }
 
-func (s *LoggerSender) Send(_ context.Context, userID int64, message string) error {
-	s.log.Info("sending email", zap.Int64("user_id", userID), zap.String("message", message))
+func (s *LoggerSender) Send(ctx context.Context, userID int64, to, message string) error {
+	logpkg.FromContext(ctx).Info("sending email", zap.Int64("user_id", userID), zap.String("to", to), zap.String("message", message))
 	return nil
 }
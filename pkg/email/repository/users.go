@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	apperrors "github.com/emorenkov/scorehub/pkg/common/errors"
+	userpb "github.com/emorenkov/scorehub/pkg/user/models/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const defaultUserLookupTimeout = 2 * time.Second
+
+// Recipient is the email address and preference of a user.
+type Recipient struct {
+	Email       string
+	EmailOptOut bool
+}
+
+// Addresses resolves the email address of a user.
+type Addresses interface {
+	// Lookup returns the recipient for userID, or a 404 StatusError when the user does not
+	// exist (or was deleted).
+	Lookup(ctx context.Context, userID int64) (*Recipient, error)
+}
+
+// UserDirectory looks addresses up in user-service.
+type UserDirectory struct {
+	client  userpb.UserServiceClient
+	timeout time.Duration
+}
+
+// NewUserDirectory returns a directory over client. Each lookup is bounded by timeout;
+// a non-positive timeout selects the default.
+func NewUserDirectory(client userpb.UserServiceClient, timeout time.Duration) *UserDirectory {
+	if timeout <= 0 {
+		timeout = defaultUserLookupTimeout
+	}
+	return &UserDirectory{client: client, timeout: timeout}
+}
+
+// Lookup implements Addresses. Failures other than a missing user are reported as 503
+// so the consumer retries them.
+func (d *UserDirectory) Lookup(ctx context.Context, userID int64) (*Recipient, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.timeout)
+	defer cancel()
+	resp, err := d.client.GetUser(ctx, &userpb.GetUserRequest{Id: userID})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, apperrors.NewStatusError(http.StatusNotFound, "user not found")
+		}
+		return nil, apperrors.WrapStatus(err, http.StatusServiceUnavailable, "look up user")
+	}
+	u := resp.GetUser()
+	return &Recipient{Email: u.GetEmail(), EmailOptOut: u.GetPreferences().GetEmailOptOut()}, nil
+}
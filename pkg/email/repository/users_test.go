@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	apperrors "github.com/emorenkov/scorehub/pkg/common/errors"
+	userpb "github.com/emorenkov/scorehub/pkg/user/models/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type userClient struct {
+	userpb.UserServiceClient
+	err      error
+	deadline time.Duration
+}
+
+func (c *userClient) GetUser(ctx context.Context, in *userpb.GetUserRequest, _ ...grpc.CallOption) (*userpb.UserResponse, error) {
+	if d, ok := ctx.Deadline(); ok {
+		c.deadline = time.Until(d)
+	}
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &userpb.UserResponse{User: &userpb.User{
+		Id:          in.GetId(),
+		Email:       "ann@example.com",
+		Preferences: &userpb.UserPreferences{EmailOptOut: true},
+	}}, nil
+}
+
+func TestUserDirectoryLookup(t *testing.T) {
+	client := &userClient{}
+	r, err := NewUserDirectory(client, time.Second).Lookup(context.Background(), 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *r != (Recipient{Email: "ann@example.com", EmailOptOut: true}) {
+		t.Fatalf("got %+v", r)
+	}
+	if client.deadline <= 0 || client.deadline > time.Second {
+		t.Fatalf("call deadline %v, want within 1s", client.deadline)
+	}
+}
+
+func TestUserDirectoryErrors(t *testing.T) {
+	for code, want := range map[codes.Code]int{
+		codes.NotFound:         http.StatusNotFound,
+		codes.Unavailable:      http.StatusServiceUnavailable,
+		codes.DeadlineExceeded: http.StatusServiceUnavailable,
+	} {
+		_, err := NewUserDirectory(&userClient{err: status.Error(code, "x")}, 0).Lookup(context.Background(), 7)
+		if se, ok := apperrors.AsStatusError(err); !ok || se.Status != want {
+			t.Errorf("%v: got %v, want status %d", code, err, want)
+		}
+	}
+}
@@ -2,8 +2,17 @@ package rest
 
 import (
 	"context"
-	"net/http"
+	"crypto/tls"
 
+	"github.com/emorenkov/scorehub/pkg/common/apikeys"
+	"github.com/emorenkov/scorehub/pkg/common/auth"
+	"github.com/emorenkov/scorehub/pkg/common/dlq"
+	"github.com/emorenkov/scorehub/pkg/common/health"
+	"github.com/emorenkov/scorehub/pkg/common/metrics"
+	"github.com/emorenkov/scorehub/pkg/common/ratelimit"
+	"github.com/emorenkov/scorehub/pkg/common/requestid"
+	"github.com/emorenkov/scorehub/pkg/common/tlsx"
+	"github.com/emorenkov/scorehub/pkg/common/tracing"
 	"github.com/emorenkov/scorehub/pkg/email/config"
 	"github.com/emorenkov/scorehub/pkg/email/service"
 	"github.com/labstack/echo/v4"
@@ -12,58 +21,89 @@ import (
 )
 
 type Server struct {
-	cfg *config.Config
-	svc service.Email
-	log *zap.Logger
-	e   *echo.Echo
+	cfg     *config.Config
+	svc     service.Email
+	dlq     *dlq.Handler
+	keys    *apikeys.Handler
+	authn   *auth.Authenticator
+	limiter *ratelimit.Limiter
+	checks  *health.Registry
+	tls     *tls.Config
+	log     *zap.Logger
+	e       *echo.Echo
 }
 
-func NewServer(cfg *config.Config, svc service.Email, log *zap.Logger) *Server {
+// Deps are the collaborators of the REST server. All may be nil.
+type Deps struct {
+	Config  *config.Config
+	Service service.Email
+	// DeadLetters and Keys mount the dead-letter and API key admin routes.
+	DeadLetters *dlq.Handler
+	Keys        *apikeys.Handler
+	// Authn guards /api/v1.
+	Authn   *auth.Authenticator
+	Limiter *ratelimit.Limiter
+	// Checks backs /livez and /readyz.
+	Checks *health.Registry
+	// TLS enables HTTPS.
+	TLS *tls.Config
+	Log *zap.Logger
+}
+
+// NewServer builds the REST server from d.
+func NewServer(d Deps) *Server {
 	e := echo.New()
 	e.HideBanner = true
 	e.HidePort = true
+	e.IPExtractor = echo.ExtractIPDirect()
 
 	s := &Server{
-		cfg: cfg,
-		svc: svc,
-		log: log,
-		e:   e,
+		cfg:     d.Config,
+		svc:     d.Service,
+		dlq:     d.DeadLetters,
+		keys:    d.Keys,
+		authn:   d.Authn,
+		limiter: d.Limiter,
+		checks:  d.Checks,
+		tls:     d.TLS,
+		log:     d.Log,
+		e:       e,
 	}
 
 	e.Use(echoMiddleware.Recover())
+	e.Use(requestid.EchoMiddleware())
+	e.Use(tracing.EchoMiddleware())
+	e.Use(metrics.EchoMiddleware())
 
 	s.registerRoutes()
 	return s
 }
 
 func (s *Server) registerRoutes() {
-	s.e.GET("/_health", func(c echo.Context) error {
-		return c.NoContent(http.StatusOK)
-	})
+	metrics.Register(s.e)
+	if s.checks != nil {
+		s.checks.Register(s.e)
+	}
 
-	api := s.e.Group("/api/v1", s.keyAuthMiddleware)
-	api.POST("/emails", s.sendEmail)
+	api := s.e.Group("/api/v1",
+		s.limiter.EchoMiddleware(ratelimit.BeforeAuth), s.authn.EchoMiddleware(), s.limiter.EchoMiddleware(ratelimit.AfterAuth))
+	api.POST("/emails", s.sendEmail, auth.Scopes(auth.ScopeNotificationsWrite))
+	admin := api.Group("", auth.Scopes(auth.ScopeAdmin))
+	if s.dlq != nil {
+		s.dlq.Register(admin)
+	}
+	if s.keys != nil {
+		s.keys.Register(admin)
+	}
 }
 
 func (s *Server) Serve() error {
 	addr := ":" + s.cfg.HTTPPort
-	s.log.Info("starting REST server", zap.String("addr", addr))
-	return s.e.Start(addr)
+	s.log.Info("starting REST server", zap.String("addr", addr), zap.Bool("tls", s.tls != nil))
+	return tlsx.StartEcho(s.e, addr, s.tls)
 }
 
 func (s *Server) Shutdown(ctx context.Context) error {
 	s.log.Info("shutting down REST server")
 	return s.e.Shutdown(ctx)
 }
-
-func (s *Server) keyAuthMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
-	if s.cfg.APIKey == "" {
-		return next
-	}
-	return func(c echo.Context) error {
-		if c.Request().Header.Get("X-API-Key") != s.cfg.APIKey {
-			return c.NoContent(http.StatusUnauthorized)
-		}
-		return next(c)
-	}
-}
@@ -6,27 +6,47 @@ import (
 	"strings"
 
 	apperrors "github.com/emorenkov/scorehub/pkg/common/errors"
+	logpkg "github.com/emorenkov/scorehub/pkg/common/logger"
 	"github.com/emorenkov/scorehub/pkg/email/repository"
+	"go.uber.org/zap"
 )
 
 type Email interface {
-	Send(ctx context.Context, userID int64, message string) error
+	// Send emails message to the address to on behalf of userID. An empty to is resolved
+	// to the user's current address in user-service, and nothing is sent to users who
+	// opted out of email.
+	Send(ctx context.Context, userID int64, to, message string) error
 }
 
 type email struct {
-	sender repository.Sender
+	sender    repository.Sender
+	addresses repository.Addresses
 }
 
-func NewEmail(sender repository.Sender) Email {
-	return &email{sender: sender}
+func NewEmail(sender repository.Sender, addresses repository.Addresses) Email {
+	return &email{sender: sender, addresses: addresses}
 }
 
-func (s *email) Send(ctx context.Context, userID int64, message string) error {
+func (s *email) Send(ctx context.Context, userID int64, to, message string) error {
 	message = strings.TrimSpace(message)
+	to = strings.TrimSpace(to)
 	if userID <= 0 || message == "" {
 		return apperrors.NewStatusError(http.StatusBadRequest, "user_id and message are required")
 	}
-	if err := s.sender.Send(ctx, userID, message); err != nil {
+	if to == "" {
+		r, err := s.addresses.Lookup(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if r.EmailOptOut {
+			logpkg.FromContext(ctx).Info("skipping email for opted-out user", zap.Int64("user_id", userID))
+			return nil
+		}
+		if to = strings.TrimSpace(r.Email); to == "" {
+			return apperrors.NewStatusError(http.StatusUnprocessableEntity, "user has no email address")
+		}
+	}
+	if err := s.sender.Send(ctx, userID, to, message); err != nil {
 		return apperrors.WrapStatus(err, http.StatusInternalServerError, "send email")
 	}
 	return nil
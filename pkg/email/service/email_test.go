@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	apperrors "github.com/emorenkov/scorehub/pkg/common/errors"
+	"github.com/emorenkov/scorehub/pkg/email/repository"
+)
+
+type sentEmail struct {
+	userID      int64
+	to, message string
+}
+
+type recordingSender struct{ sent []sentEmail }
+
+func (s *recordingSender) Send(_ context.Context, userID int64, to, message string) error {
+	s.sent = append(s.sent, sentEmail{userID, to, message})
+	return nil
+}
+
+type addresses struct {
+	addr   string
+	optOut bool
+	err    error
+	calls  int
+}
+
+func (a *addresses) Lookup(context.Context, int64) (*repository.Recipient, error) {
+	a.calls++
+	if a.err != nil {
+		return nil, a.err
+	}
+	return &repository.Recipient{Email: a.addr, EmailOptOut: a.optOut}, nil
+}
+
+func TestSendUsesGivenAddress(t *testing.T) {
+	sender, users := &recordingSender{}, &addresses{addr: "other@example.com"}
+	if err := NewEmail(sender, users).Send(context.Background(), 7, " ann@example.com ", "hi"); err != nil {
+		t.Fatal(err)
+	}
+	if users.calls != 0 {
+		t.Fatalf("looked the user up %d times", users.calls)
+	}
+	if len(sender.sent) != 1 || sender.sent[0] != (sentEmail{7, "ann@example.com", "hi"}) {
+		t.Fatalf("sent %+v", sender.sent)
+	}
+}
+
+func TestSendLooksUpMissingAddress(t *testing.T) {
+	sender, users := &recordingSender{}, &addresses{addr: "ann@example.com"}
+	if err := NewEmail(sender, users).Send(context.Background(), 7, "", "hi"); err != nil {
+		t.Fatal(err)
+	}
+	if len(sender.sent) != 1 || sender.sent[0].to != "ann@example.com" {
+		t.Fatalf("sent %+v", sender.sent)
+	}
+}
+
+func TestSendSkipsOptedOutUser(t *testing.T) {
+	sender := &recordingSender{}
+	if err := NewEmail(sender, &addresses{addr: "ann@example.com", optOut: true}).Send(context.Background(), 7, "", "hi"); err != nil {
+		t.Fatal(err)
+	}
+	if len(sender.sent) != 0 {
+		t.Fatalf("sent %+v", sender.sent)
+	}
+}
+
+func TestSendLookupErrors(t *testing.T) {
+	for name, tc := range map[string]struct {
+		users *addresses
+		want  int
+	}{
+		"unknown user": {&addresses{err: apperrors.NewStatusError(http.StatusNotFound, "user not found")}, http.StatusNotFound},
+		"unavailable":  {&addresses{err: apperrors.WrapStatus(errors.New("down"), http.StatusServiceUnavailable, "look up user")}, http.StatusServiceUnavailable},
+		"no address":   {&addresses{}, http.StatusUnprocessableEntity},
+	} {
+		sender := &recordingSender{}
+		err := NewEmail(sender, tc.users).Send(context.Background(), 7, "", "hi")
+		se, ok := apperrors.AsStatusError(err)
+		if !ok || se.Status != tc.want {
+			t.Errorf("%s: got %v, want status %d", name, err, tc.want)
+		}
+		if len(sender.sent) != 0 {
+			t.Errorf("%s: sent %+v", name, sender.sent)
+		}
+	}
+}
+
+func TestSendValidatesBeforeLookup(t *testing.T) {
+	users := &addresses{addr: "ann@example.com"}
+	err := NewEmail(&recordingSender{}, users).Send(context.Background(), 0, "", "hi")
+	if se, ok := apperrors.AsStatusError(err); !ok || se.Status != http.StatusBadRequest {
+		t.Fatalf("got %v, want 400", err)
+	}
+	if users.calls != 0 {
+		t.Fatalf("looked the user up %d times", users.calls)
+	}
+}
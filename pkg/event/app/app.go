@@ -5,7 +5,15 @@ import (
 	"fmt"
 	"net"
 
+	"github.com/emorenkov/scorehub/pkg/common/apikeys"
+	"github.com/emorenkov/scorehub/pkg/common/auth"
+	"github.com/emorenkov/scorehub/pkg/common/db"
+	"github.com/emorenkov/scorehub/pkg/common/gateway"
+	"github.com/emorenkov/scorehub/pkg/common/grpcx"
+	"github.com/emorenkov/scorehub/pkg/common/health"
 	logpkg "github.com/emorenkov/scorehub/pkg/common/logger"
+	"github.com/emorenkov/scorehub/pkg/common/ratelimit"
+	"github.com/emorenkov/scorehub/pkg/common/tlsx"
 	"github.com/emorenkov/scorehub/pkg/event/config"
 	grpcserver "github.com/emorenkov/scorehub/pkg/event/grpc"
 	eventpb "github.com/emorenkov/scorehub/pkg/event/proto"
@@ -13,19 +21,25 @@ import (
 	"github.com/emorenkov/scorehub/pkg/event/rest"
 	"github.com/emorenkov/scorehub/pkg/event/service"
 	userpb "github.com/emorenkov/scorehub/pkg/user/models/proto"
+	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 	"golang.org/x/sync/errgroup"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	"gorm.io/gorm"
 )
 
 type App struct {
 	cfg          *config.Config
 	restServer   *rest.Server
+	gateway      *gateway.Handler
 	grpcServer   *grpc.Server
 	grpcListener net.Listener
+	grpcHealth   *health.GRPCServer
 	publisher    repository.Publisher
 	userConn     *grpc.ClientConn
+	redis        *redis.Client
+	db           *gorm.DB
+	cancel       context.CancelFunc
 }
 
 func New(cfg *config.Config) (*App, error) {
@@ -34,17 +48,104 @@ func New(cfg *config.Config) (*App, error) {
 		return nil, fmt.Errorf("init kafka publisher: %w", err)
 	}
 
-	userConn, err := grpc.Dial(cfg.UserServiceAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	userTLS, err := tlsx.Client(cfg.UserServiceTLS)
+	if err != nil {
+		return nil, fmt.Errorf("init user service tls: %w", err)
+	}
+	userConn, err := grpc.Dial(cfg.UserServiceAddr, grpcx.DialOptions(grpcx.ClientConfig{
+		APIKey:  cfg.UserServiceAPIKey,
+		Timeout: cfg.GRPC.ClientTimeout,
+		TLS:     userTLS,
+	})...)
 	if err != nil {
 		return nil, fmt.Errorf("dial user service: %w", err)
 	}
 
-	svc := service.NewEvent(pub, userpb.NewUserServiceClient(userConn))
+	var redisClient *redis.Client
+	var dedupe repository.IdempotencyStore
+	if cfg.RedisConfig.RedisAddr != "" && (cfg.IdempotencyWindow > 0 || cfg.RateLimit.Enabled) {
+		redisClient = redis.NewClient(&redis.Options{
+			Addr:     cfg.RedisConfig.RedisAddr,
+			Password: cfg.RedisConfig.RedisPassword,
+			DB:       cfg.RedisConfig.RedisDB,
+		})
+	}
+	if redisClient != nil && cfg.IdempotencyWindow > 0 {
+		dedupe = repository.NewRedisIdempotencyStore(redisClient)
+	}
+
+	svc := service.NewEvent(pub, userpb.NewUserServiceClient(userConn), dedupe, cfg.IdempotencyWindow, cfg.MaxBatchSize)
+
+	checks := health.NewRegistry(0)
+	checks.Add("kafka", health.Kafka(cfg.KafkaBrokers))
+	checks.Add("user_service", health.GRPCConn(userConn))
+	if redisClient != nil {
+		checks.Add("redis", health.Redis(redisClient))
+	}
+
+	grpcTLS, err := tlsx.Server(cfg.GRPC.TLS)
+	if err != nil {
+		return nil, fmt.Errorf("init grpc tls: %w", err)
+	}
+	gatewayTLS, err := tlsx.SelfClient(cfg.GRPC.TLS)
+	if err != nil {
+		return nil, fmt.Errorf("init gateway tls: %w", err)
+	}
+	httpTLS, err := tlsx.Server(cfg.HTTPTLS)
+	if err != nil {
+		return nil, fmt.Errorf("init http tls: %w", err)
+	}
+
+	var keysDB *gorm.DB
+	var keys auth.KeyLookup
+	var keysHandler *apikeys.Handler
+	if cfg.APIKeys.Enabled {
+		if keysDB, err = db.NewPostgresDB(cfg.DbConfig); err != nil {
+			return nil, fmt.Errorf("init db: %w", err)
+		}
+		checks.Add("postgres", health.DB(keysDB))
+		cache, h := apikeys.New(keysDB, cfg.APIKeys)
+		keys, keysHandler = cache, h
+	}
+	authn, err := auth.FromConfig(context.Background(), cfg.APIKey, keys, cfg.Auth)
+	if err != nil {
+		return nil, fmt.Errorf("init auth: %w", err)
+	}
+
+	limiter, err := ratelimit.New(cfg.RateLimit, redisClient)
+	if err != nil {
+		return nil, fmt.Errorf("init rate limiter: %w", err)
+	}
 
-	restServer := rest.NewServer(cfg, svc, logpkg.Log)
+	gw, err := gateway.New(gateway.Config{
+		GRPCAddr:     "localhost:" + cfg.GRPCPort,
+		GRPCTLS:      gatewayTLS,
+		MaxBodyBytes: rest.MaxBodyBytes,
+		Options:      rest.GatewayOptions(),
+	}, eventpb.RegisterEventServiceHandlerFromEndpoint)
+	if err != nil {
+		return nil, fmt.Errorf("init gateway: %w", err)
+	}
+	restServer := rest.NewServer(rest.Deps{
+		Config:  cfg,
+		Gateway: gw,
+		Keys:    keysHandler,
+		Authn:   authn,
+		Limiter: limiter,
+		Checks:  checks,
+		TLS:     httpTLS,
+		Log:     logpkg.Log,
+	})
 
-	grpcSrv := grpc.NewServer()
-	eventpb.RegisterEventServiceServer(grpcSrv, grpcserver.NewServer(svc, logpkg.Log))
+	grpcSrv := grpcx.NewServer(grpcx.ServerConfig{
+		Auth:           grpcx.Auth(authn),
+		Scopes:         grpcserver.MethodScopes,
+		RateLimit:      limiter,
+		DefaultTimeout: cfg.GRPC.DefaultTimeout,
+		TLS:            grpcTLS,
+	})
+	eventpb.RegisterEventServiceServer(grpcSrv, grpcserver.NewServer(svc, cfg.MaxBatchSize, cfg.MaxStreamEvents))
+	grpcHealth := health.NewGRPCServer(grpcSrv, checks, eventpb.EventService_ServiceDesc.ServiceName)
 	grpcAddr := ":" + cfg.GRPCPort
 	lis, err := net.Listen("tcp", grpcAddr)
 	if err != nil {
@@ -54,15 +155,21 @@ func New(cfg *config.Config) (*App, error) {
 	return &App{
 		cfg:          cfg,
 		restServer:   restServer,
+		gateway:      gw,
 		grpcServer:   grpcSrv,
 		grpcListener: lis,
+		grpcHealth:   grpcHealth,
 		publisher:    pub,
 		userConn:     userConn,
+		redis:        redisClient,
+		db:           keysDB,
 	}, nil
 }
 
 func (a *App) Run() <-chan error {
 	errCh := make(chan error, 2)
+	ctx, cancel := context.WithCancel(context.Background())
+	a.cancel = cancel
 
 	go func() {
 		if err := a.restServer.Serve(); err != nil {
@@ -71,19 +178,27 @@ func (a *App) Run() <-chan error {
 	}()
 
 	go func() {
-		logpkg.Log.Info("starting gRPC server", zap.String("addr", ":"+a.cfg.GRPCPort))
+		logpkg.FromContext(ctx).Info("starting gRPC server", zap.String("addr", ":"+a.cfg.GRPCPort))
 		if err := a.grpcServer.Serve(a.grpcListener); err != nil {
 			errCh <- fmt.Errorf("grpc server: %w", err)
 		}
 	}()
 
+	go a.grpcHealth.Run(ctx, 0)
+
 	return errCh
 }
 
 func (a *App) Shutdown(ctx context.Context) error {
+	if a.cancel != nil {
+		a.cancel()
+	}
+	a.grpcHealth.Shutdown()
+
 	g, ctx := errgroup.WithContext(ctx)
 
 	g.Go(func() error {
+		defer a.gateway.Close()
 		return a.restServer.Shutdown(ctx)
 	})
 
@@ -114,5 +229,23 @@ func (a *App) Shutdown(ctx context.Context) error {
 		return nil
 	})
 
+	g.Go(func() error {
+		if a.redis != nil {
+			return a.redis.Close()
+		}
+		return nil
+	})
+
+	g.Go(func() error {
+		if a.db == nil {
+			return nil
+		}
+		sqlDB, err := a.db.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.Close()
+	})
+
 	return g.Wait()
 }
@@ -3,6 +3,9 @@ package config
 import (
 	"os"
 	"strings"
+	"time"
+
+	"github.com/emorenkov/scorehub/pkg/common/models"
 )
 
 type Config struct {
@@ -13,17 +16,50 @@ type Config struct {
 	ScoreEventsTopic string
 	APIKey           string
 	UserServiceAddr  string
+	// UserServiceAPIKey is sent as x-api-key on calls to user-service.
+	UserServiceAPIKey string
+	// UserServiceTLS secures the connection to user-service; a certificate enables mutual TLS.
+	UserServiceTLS *models.TLSConfig
+	RedisConfig    *models.RedisConfig
+	RateLimit      *models.RateLimitConfig
+	// IdempotencyWindow is how long an event_id is remembered; 0 disables deduplication.
+	IdempotencyWindow time.Duration
+	// MaxBatchSize caps events per SendScoreEvents call and per streamed chunk.
+	MaxBatchSize int
+	// MaxStreamEvents caps events per StreamScoreEvents call.
+	MaxStreamEvents int
+	Tracing         *models.TracingConfig
+	// HTTPTLS serves the REST API over HTTPS when a certificate is set.
+	HTTPTLS *models.TLSConfig
+	Auth    *models.AuthConfig
+	APIKeys *models.APIKeysConfig
+	// DbConfig is only used to look up API keys when APIKeys.Enabled is set.
+	DbConfig *models.PostgresConfig
+	GRPC     *models.GRPCConfig
 }
 
 func Load() *Config {
 	return &Config{
-		ServiceName:      getEnv("SERVICE_NAME", "event-service"),
-		GRPCPort:         getEnv("GRPC_PORT", "50052"),
-		HTTPPort:         getEnv("HTTP_PORT", "8082"),
-		KafkaBrokers:     splitAndTrim(getEnv("KAFKA_BROKERS", "localhost:9092")),
-		ScoreEventsTopic: getEnv("SCORE_EVENTS_TOPIC", "score_events"),
-		APIKey:           getEnv("API_KEY", ""),
-		UserServiceAddr:  getEnv("USER_SERVICE_ADDR", "localhost:50051"),
+		ServiceName:       getEnv("SERVICE_NAME", "event-service"),
+		GRPCPort:          getEnv("GRPC_PORT", "50052"),
+		HTTPPort:          getEnv("HTTP_PORT", "8082"),
+		KafkaBrokers:      splitAndTrim(getEnv("KAFKA_BROKERS", "localhost:9092")),
+		ScoreEventsTopic:  getEnv("SCORE_EVENTS_TOPIC", "score_events"),
+		APIKey:            getEnv("API_KEY", ""),
+		UserServiceAddr:   getEnv("USER_SERVICE_ADDR", "localhost:50051"),
+		UserServiceAPIKey: getEnv("USER_SERVICE_API_KEY", ""),
+		UserServiceTLS:    models.LoadTLSConfig("USER_SERVICE_"),
+		RedisConfig:       models.LoadRedisConfig(),
+		RateLimit:         models.LoadRateLimitConfig(),
+		IdempotencyWindow: time.Duration(models.GetEnvAsInt("IDEMPOTENCY_WINDOW_SECONDS", 86400)) * time.Second,
+		MaxBatchSize:      models.GetEnvAsInt("MAX_BATCH_SIZE", 1000),
+		MaxStreamEvents:   models.GetEnvAsInt("MAX_STREAM_EVENTS", 100000),
+		Tracing:           models.LoadTracingConfig(),
+		HTTPTLS:           models.LoadTLSConfig("HTTP_"),
+		Auth:              models.LoadAuthConfig(),
+		APIKeys:           models.LoadAPIKeysConfig(),
+		DbConfig:          models.LoadPostgresConfig(),
+		GRPC:              models.LoadGRPCConfig(),
 	}
 }
 
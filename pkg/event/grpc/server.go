@@ -2,44 +2,176 @@ package grpcserver
 
 import (
 	"context"
+	"errors"
+	"io"
 	"net/http"
 
+	"github.com/emorenkov/scorehub/pkg/common/auth"
 	apperrors "github.com/emorenkov/scorehub/pkg/common/errors"
+	logpkg "github.com/emorenkov/scorehub/pkg/common/logger"
 	"github.com/emorenkov/scorehub/pkg/event"
 	eventpb "github.com/emorenkov/scorehub/pkg/event/proto"
 	"github.com/emorenkov/scorehub/pkg/event/service"
 	"go.uber.org/zap"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 )
 
 type Server struct {
 	eventpb.UnimplementedEventServiceServer
-	svc service.Event
-	log *zap.Logger
+	svc             service.Event
+	chunkSize       int
+	maxStreamEvents int
 }
 
-func NewServer(svc service.Event, log *zap.Logger) *Server {
-	return &Server{svc: svc, log: log}
+// NewServer builds the gRPC server; streamed events are forwarded to the service in
+// chunks of chunkSize, and a stream carries at most maxStreamEvents events.
+func NewServer(svc service.Event, chunkSize, maxStreamEvents int) *Server {
+	if chunkSize <= 0 {
+		chunkSize = 500
+	}
+	if maxStreamEvents <= 0 {
+		maxStreamEvents = 100000
+	}
+	return &Server{svc: svc, chunkSize: chunkSize, maxStreamEvents: maxStreamEvents}
+}
+
+// MethodScopes lists the scopes each RPC requires, for grpcx.ServerConfig.Scopes.
+var MethodScopes = map[string][]string{
+	"/event.EventService/SendScoreEvent":    {auth.ScopeEventsWrite},
+	"/event.EventService/SendScoreEvents":   {auth.ScopeEventsWrite},
+	"/event.EventService/StreamScoreEvents": {auth.ScopeEventsWrite},
 }
 
 func (s *Server) SendScoreEvent(ctx context.Context, req *eventpb.ScoreEventRequest) (*eventpb.EventAck, error) {
-	ev := &event.ScoreEvent{
-		UserID:   req.GetUserId(),
-		NewScore: req.GetNewScore(),
-		Change:   req.GetChange(),
+	log := logpkg.FromContext(ctx)
+	ev := fromProtoEvent(req)
+	if vals := metadata.ValueFromIncomingContext(ctx, "idempotency-key"); len(vals) > 0 {
+		switch {
+		case ev.EventID == "":
+			ev.EventID = vals[0]
+		case ev.EventID != vals[0]:
+			log.Error("grpc SendScoreEvent conflicting idempotency key", zap.String("event_id", ev.EventID))
+			return nil, status.Error(codes.InvalidArgument, "idempotency-key does not match event_id")
+		}
 	}
 	ack, err := s.svc.Send(ctx, ev)
 	if err != nil {
-		if s.log != nil {
-			s.log.Error("grpc SendScoreEvent failed", zap.Error(err), zap.Int64("user_id", ev.UserID))
-		}
+		log.Error("grpc SendScoreEvent failed", zap.Error(err), zap.Int64("user_id", ev.UserID))
+		return nil, mapError(err)
+	}
+	log.Info("grpc SendScoreEvent succeeded", zap.String("status", ack.Status), zap.Int64("user_id", ev.UserID), zap.String("event_id", ack.EventID), zap.Bool("duplicate", ack.Duplicate))
+	return &eventpb.EventAck{Status: ack.Status, EventId: ack.EventID, Duplicate: ack.Duplicate}, nil
+}
+
+func (s *Server) SendScoreEvents(ctx context.Context, req *eventpb.SendScoreEventsRequest) (*eventpb.SendScoreEventsResponse, error) {
+	log := logpkg.FromContext(ctx)
+	evs := make([]*event.ScoreEvent, 0, len(req.GetEvents()))
+	for _, e := range req.GetEvents() {
+		evs = append(evs, fromProtoEvent(e))
+	}
+	ack, err := s.svc.SendBatch(ctx, evs)
+	if err != nil {
+		log.Error("grpc SendScoreEvents failed", zap.Error(err), zap.Int("count", len(evs)))
 		return nil, mapError(err)
 	}
-	if s.log != nil {
-		s.log.Info("grpc SendScoreEvent succeeded", zap.String("status", ack.Status), zap.Int64("user_id", ev.UserID))
+	log.Info("grpc SendScoreEvents succeeded", zap.Int("accepted", ack.Accepted), zap.Int("rejected", ack.Rejected))
+	resp := &eventpb.SendScoreEventsResponse{}
+	appendResults(resp, ack, 0)
+	return resp, nil
+}
+
+func (s *Server) StreamScoreEvents(stream eventpb.EventService_StreamScoreEventsServer) error {
+	ctx := stream.Context()
+	log := logpkg.FromContext(ctx)
+	resp := &eventpb.SendScoreEventsResponse{}
+	chunk := make([]*event.ScoreEvent, 0, s.chunkSize)
+	offset := 0
+
+	flush := func() error {
+		if len(chunk) == 0 {
+			return nil
+		}
+		ack, err := s.svc.SendBatch(ctx, chunk)
+		if err != nil {
+			return err
+		}
+		appendResults(resp, ack, offset)
+		offset += len(chunk)
+		chunk = chunk[:0]
+		return nil
+	}
+
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return withResults(status.Convert(err), resp)
+		}
+		if offset+len(chunk) >= s.maxStreamEvents {
+			if err := flush(); err != nil {
+				log.Error("grpc StreamScoreEvents failed", zap.Error(err), zap.Int("received", offset+len(chunk)))
+				return withResults(status.Convert(mapError(err)), resp)
+			}
+			log.Warn("grpc StreamScoreEvents too many events", zap.Int("max", s.maxStreamEvents))
+			return withResults(status.Newf(codes.ResourceExhausted, "a stream carries at most %d events", s.maxStreamEvents), resp)
+		}
+		chunk = append(chunk, fromProtoEvent(req))
+		if len(chunk) >= s.chunkSize {
+			if err := flush(); err != nil {
+				log.Error("grpc StreamScoreEvents failed", zap.Error(err), zap.Int("received", offset+len(chunk)))
+				return withResults(status.Convert(mapError(err)), resp)
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		log.Error("grpc StreamScoreEvents failed", zap.Error(err), zap.Int("received", offset+len(chunk)))
+		return withResults(status.Convert(mapError(err)), resp)
+	}
+	log.Info("grpc StreamScoreEvents succeeded", zap.Int32("accepted", resp.Accepted), zap.Int32("rejected", resp.Rejected))
+	return stream.SendAndClose(resp)
+}
+
+func fromProtoEvent(req *eventpb.ScoreEventRequest) *event.ScoreEvent {
+	return &event.ScoreEvent{
+		UserID:   req.GetUserId(),
+		NewScore: req.GetNewScore(),
+		Change:   req.GetChange(),
+		Type:     req.GetType(),
+		EventID:  req.GetEventId(),
+	}
+}
+
+// withResults returns st as an error carrying the results of the chunks already
+// published, when there are any, so the client knows which events were accepted before
+// the stream failed.
+func withResults(st *status.Status, resp *eventpb.SendScoreEventsResponse) error {
+	if len(resp.GetResults()) == 0 {
+		return st.Err()
+	}
+	if withDetails, err := st.WithDetails(resp); err == nil {
+		return withDetails.Err()
+	}
+	return st.Err()
+}
+
+// appendResults adds ack's results to resp, shifting indexes by offset.
+func appendResults(resp *eventpb.SendScoreEventsResponse, ack *event.BatchAck, offset int) {
+	for _, r := range ack.Results {
+		resp.Results = append(resp.Results, &eventpb.ScoreEventResult{
+			Index:     int32(r.Index + offset),
+			Status:    r.Status,
+			EventId:   r.EventID,
+			Duplicate: r.Duplicate,
+			Error:     r.Error,
+			Code:      int32(r.Code),
+		})
 	}
-	return &eventpb.EventAck{Status: ack.Status}, nil
+	resp.Accepted += int32(ack.Accepted)
+	resp.Rejected += int32(ack.Rejected)
 }
 
 func mapError(err error) error {
@@ -47,6 +179,18 @@ func mapError(err error) error {
 		switch se.Status {
 		case http.StatusBadRequest:
 			return status.Error(codes.InvalidArgument, se.Message)
+		case http.StatusUnauthorized:
+			return status.Error(codes.Unauthenticated, se.Message)
+		case http.StatusForbidden:
+			return status.Error(codes.PermissionDenied, se.Message)
+		case http.StatusNotFound:
+			return status.Error(codes.NotFound, se.Message)
+		case http.StatusConflict:
+			return status.Error(codes.Aborted, se.Message)
+		case http.StatusUnprocessableEntity:
+			return status.Error(codes.FailedPrecondition, se.Message)
+		case http.StatusServiceUnavailable:
+			return status.Error(codes.Unavailable, se.Message)
 		default:
 			return status.Error(codes.Internal, se.Error())
 		}
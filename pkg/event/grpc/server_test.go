@@ -0,0 +1,166 @@
+package grpcserver
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	apperrors "github.com/emorenkov/scorehub/pkg/common/errors"
+	"github.com/emorenkov/scorehub/pkg/common/grpcx"
+	"github.com/emorenkov/scorehub/pkg/event"
+	eventpb "github.com/emorenkov/scorehub/pkg/event/proto"
+	"github.com/emorenkov/scorehub/pkg/event/service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestMethodScopesCoverEveryRPC(t *testing.T) {
+	grpcx.CheckScopes(t, eventpb.EventService_ServiceDesc, MethodScopes)
+}
+
+// batchService accepts every event of a batch, failing from batch failAt on.
+type batchService struct {
+	service.Event
+	batches int
+	failAt  int
+}
+
+func (s *batchService) SendBatch(_ context.Context, evs []*event.ScoreEvent) (*event.BatchAck, error) {
+	s.batches++
+	if s.failAt > 0 && s.batches >= s.failAt {
+		return nil, apperrors.NewStatusError(http.StatusServiceUnavailable, "publisher unavailable")
+	}
+	ack := &event.BatchAck{}
+	for i := range evs {
+		ack.Results = append(ack.Results, event.ScoreEventResult{Index: i, Status: "accepted"})
+		ack.Accepted++
+	}
+	return ack, nil
+}
+
+// fakeEventStream receives n events and records the response.
+type fakeEventStream struct {
+	eventpb.EventService_StreamScoreEventsServer
+	n    int
+	resp *eventpb.SendScoreEventsResponse
+}
+
+func (f *fakeEventStream) Context() context.Context { return context.Background() }
+
+func (f *fakeEventStream) Recv() (*eventpb.ScoreEventRequest, error) {
+	if f.n == 0 {
+		return nil, io.EOF
+	}
+	f.n--
+	return &eventpb.ScoreEventRequest{UserId: 1, NewScore: 10}, nil
+}
+
+func (f *fakeEventStream) SendAndClose(resp *eventpb.SendScoreEventsResponse) error {
+	f.resp = resp
+	return nil
+}
+
+// streamResults returns the results attached to err.
+func streamResults(t *testing.T, err error) *eventpb.SendScoreEventsResponse {
+	t.Helper()
+	for _, d := range status.Convert(err).Details() {
+		if resp, ok := d.(*eventpb.SendScoreEventsResponse); ok {
+			return resp
+		}
+	}
+	t.Fatalf("error %v carries no results", err)
+	return nil
+}
+
+func TestStreamScoreEventsCapsEvents(t *testing.T) {
+	stream := &fakeEventStream{n: 7}
+	err := NewServer(&batchService{}, 2, 5).StreamScoreEvents(stream)
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("err = %v, want ResourceExhausted", err)
+	}
+	if resp := streamResults(t, err); resp.Accepted != 5 || len(resp.Results) != 5 {
+		t.Fatalf("published %d of 5 events before the cap: %v", resp.Accepted, resp.Results)
+	}
+}
+
+func TestStreamScoreEventsAtCapSucceeds(t *testing.T) {
+	stream := &fakeEventStream{n: 5}
+	if err := NewServer(&batchService{}, 2, 5).StreamScoreEvents(stream); err != nil {
+		t.Fatal(err)
+	}
+	if stream.resp.Accepted != 5 {
+		t.Fatalf("accepted = %d, want 5", stream.resp.Accepted)
+	}
+}
+
+func TestStreamScoreEventsReturnsPublishedResultsOnFailure(t *testing.T) {
+	stream := &fakeEventStream{n: 5}
+	err := NewServer(&batchService{failAt: 2}, 2, 0).StreamScoreEvents(stream)
+	if status.Code(err) != codes.Unavailable {
+		t.Fatalf("err = %v, want Unavailable", err)
+	}
+	resp := streamResults(t, err)
+	if resp.Accepted != 2 || len(resp.Results) != 2 || resp.Results[1].Index != 1 {
+		t.Fatalf("results of the first chunk = %v", resp.Results)
+	}
+}
+
+func TestStreamScoreEventsFailureWithoutResults(t *testing.T) {
+	err := NewServer(&batchService{failAt: 1}, 2, 0).StreamScoreEvents(&fakeEventStream{n: 1})
+	if status.Code(err) != codes.Unavailable || len(status.Convert(err).Details()) != 0 {
+		t.Fatalf("err = %v, want Unavailable without details", err)
+	}
+}
+
+// sendService records the event passed to Send.
+type sendService struct {
+	service.Event
+	got *event.ScoreEvent
+}
+
+func (s *sendService) Send(_ context.Context, ev *event.ScoreEvent) (*event.EventAck, error) {
+	s.got = ev
+	return &event.EventAck{Status: "accepted", EventID: ev.EventID}, nil
+}
+
+func TestSendScoreEventIdempotencyKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		bodyID  string
+		header  string
+		wantID  string
+		wantErr codes.Code
+	}{
+		{name: "body only", bodyID: "a", wantID: "a"},
+		{name: "header only", header: "b", wantID: "b"},
+		{name: "both equal", bodyID: "c", header: "c", wantID: "c"},
+		{name: "mismatch", bodyID: "a", header: "b", wantErr: codes.InvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &sendService{}
+			ctx := context.Background()
+			if tt.header != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("idempotency-key", tt.header))
+			}
+			_, err := NewServer(svc, 0, 0).SendScoreEvent(ctx, &eventpb.ScoreEventRequest{UserId: 1, EventId: tt.bodyID})
+			if tt.wantErr != codes.OK {
+				if status.Code(err) != tt.wantErr {
+					t.Fatalf("code = %v, want %v", status.Code(err), tt.wantErr)
+				}
+				if svc.got != nil {
+					t.Fatal("event was sent despite the conflicting key")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SendScoreEvent: %v", err)
+			}
+			if svc.got.EventID != tt.wantID {
+				t.Fatalf("event_id = %q, want %q", svc.got.EventID, tt.wantID)
+			}
+		})
+	}
+}
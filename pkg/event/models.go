@@ -2,12 +2,36 @@ package event
 
 // ScoreEvent represents the payload published to Kafka and exposed via APIs.
 type ScoreEvent struct {
-	UserID   int64 `json:"user_id"`
-	NewScore int64 `json:"new_score"`
-	Change   int32 `json:"change"`
+	UserID   int64  `json:"user_id"`
+	NewScore int64  `json:"new_score"`
+	Change   int32  `json:"change"`
+	Type     string `json:"type,omitempty"`
+	EventID  string `json:"event_id,omitempty"`
 }
 
+// EventTypeScoreUpdate is the type assigned to score events that do not specify one.
+const EventTypeScoreUpdate = "score_update"
+
 // EventAck mirrors the gRPC/REST acknowledgement response.
 type EventAck struct {
-	Status string `json:"status"`
+	Status    string `json:"status"`
+	EventID   string `json:"event_id"`
+	Duplicate bool   `json:"duplicate,omitempty"`
+}
+
+// ScoreEventResult is the outcome of one event in a batch.
+type ScoreEventResult struct {
+	Index     int    `json:"index"`
+	Status    string `json:"status"`
+	EventID   string `json:"event_id,omitempty"`
+	Duplicate bool   `json:"duplicate,omitempty"`
+	Error     string `json:"error,omitempty"`
+	Code      int    `json:"code"`
+}
+
+// BatchAck reports per-event results of a batch, in request order.
+type BatchAck struct {
+	Results  []ScoreEventResult `json:"results"`
+	Accepted int                `json:"accepted"`
+	Rejected int                `json:"rejected"`
 }
@@ -1,16 +1,18 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
-// 	protoc-gen-go v1.26.0
+// 	protoc-gen-go v1.36.10
 // 	protoc        v5.29.3
 // source: pkg/event/proto/event.proto
 
 package eventpb
 
 import (
+	_ "google.golang.org/genproto/googleapis/api/annotations"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	reflect "reflect"
 	sync "sync"
+	unsafe "unsafe"
 )
 
 const (
@@ -21,22 +23,25 @@ const (
 )
 
 type ScoreEventRequest struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	UserId   int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	NewScore int64                  `protobuf:"varint,2,opt,name=new_score,json=newScore,proto3" json:"new_score,omitempty"`
+	Change   int32                  `protobuf:"varint,3,opt,name=change,proto3" json:"change,omitempty"`
+	// Event type, e.g. "score_update" (the default when empty).
+	Type string `protobuf:"bytes,4,opt,name=type,proto3" json:"type,omitempty"`
+	// Client-supplied idempotency key. Retries with the same event_id within the
+	// dedupe window return the original ack instead of publishing again. The
+	// "idempotency-key" metadata header is used when this is empty.
+	EventId       string `protobuf:"bytes,5,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
-
-	UserId   int64 `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
-	NewScore int64 `protobuf:"varint,2,opt,name=new_score,json=newScore,proto3" json:"new_score,omitempty"`
-	Change   int32 `protobuf:"varint,3,opt,name=change,proto3" json:"change,omitempty"`
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *ScoreEventRequest) Reset() {
 	*x = ScoreEventRequest{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_event_proto_event_proto_msgTypes[0]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_pkg_event_proto_event_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *ScoreEventRequest) String() string {
@@ -47,7 +52,7 @@ func (*ScoreEventRequest) ProtoMessage() {}
 
 func (x *ScoreEventRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_pkg_event_proto_event_proto_msgTypes[0]
-	if protoimpl.UnsafeEnabled && x != nil {
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -83,21 +88,35 @@ func (x *ScoreEventRequest) GetChange() int32 {
 	return 0
 }
 
+func (x *ScoreEventRequest) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *ScoreEventRequest) GetEventId() string {
+	if x != nil {
+		return x.EventId
+	}
+	return ""
+}
+
 type EventAck struct {
-	state         protoimpl.MessageState
-	sizeCache     protoimpl.SizeCache
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Status  string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	EventId string                 `protobuf:"bytes,2,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"`
+	// True when the ack is a replay of an earlier request with the same event_id.
+	Duplicate     bool `protobuf:"varint,3,opt,name=duplicate,proto3" json:"duplicate,omitempty"`
 	unknownFields protoimpl.UnknownFields
-
-	Status string `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *EventAck) Reset() {
 	*x = EventAck{}
-	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_event_proto_event_proto_msgTypes[1]
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		ms.StoreMessageInfo(mi)
-	}
+	mi := &file_pkg_event_proto_event_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
 func (x *EventAck) String() string {
@@ -108,7 +127,7 @@ func (*EventAck) ProtoMessage() {}
 
 func (x *EventAck) ProtoReflect() protoreflect.Message {
 	mi := &file_pkg_event_proto_event_proto_msgTypes[1]
-	if protoimpl.UnsafeEnabled && x != nil {
+	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
@@ -130,56 +149,278 @@ func (x *EventAck) GetStatus() string {
 	return ""
 }
 
-var File_pkg_event_proto_event_proto protoreflect.FileDescriptor
+func (x *EventAck) GetEventId() string {
+	if x != nil {
+		return x.EventId
+	}
+	return ""
+}
+
+func (x *EventAck) GetDuplicate() bool {
+	if x != nil {
+		return x.Duplicate
+	}
+	return false
+}
+
+type SendScoreEventsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Events        []*ScoreEventRequest   `protobuf:"bytes,1,rep,name=events,proto3" json:"events,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SendScoreEventsRequest) Reset() {
+	*x = SendScoreEventsRequest{}
+	mi := &file_pkg_event_proto_event_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SendScoreEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SendScoreEventsRequest) ProtoMessage() {}
+
+func (x *SendScoreEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_event_proto_event_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SendScoreEventsRequest.ProtoReflect.Descriptor instead.
+func (*SendScoreEventsRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_event_proto_event_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *SendScoreEventsRequest) GetEvents() []*ScoreEventRequest {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+// ScoreEventResult is the outcome of one event in a batch, in request order.
+type ScoreEventResult struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Index int32                  `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	// "ok" or "error".
+	Status    string `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	EventId   string `protobuf:"bytes,3,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"`
+	Duplicate bool   `protobuf:"varint,4,opt,name=duplicate,proto3" json:"duplicate,omitempty"`
+	Error     string `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
+	// HTTP-style status code of the item: 200 on success, 4xx/5xx otherwise.
+	Code          int32 `protobuf:"varint,6,opt,name=code,proto3" json:"code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
 
-var file_pkg_event_proto_event_proto_rawDesc = []byte{
-	0x0a, 0x1b, 0x70, 0x6b, 0x67, 0x2f, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x2f, 0x70, 0x72, 0x6f, 0x74,
-	0x6f, 0x2f, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x05, 0x65,
-	0x76, 0x65, 0x6e, 0x74, 0x22, 0x61, 0x0a, 0x11, 0x53, 0x63, 0x6f, 0x72, 0x65, 0x45, 0x76, 0x65,
-	0x6e, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x17, 0x0a, 0x07, 0x75, 0x73, 0x65,
-	0x72, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x75, 0x73, 0x65, 0x72,
-	0x49, 0x64, 0x12, 0x1b, 0x0a, 0x09, 0x6e, 0x65, 0x77, 0x5f, 0x73, 0x63, 0x6f, 0x72, 0x65, 0x18,
-	0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x6e, 0x65, 0x77, 0x53, 0x63, 0x6f, 0x72, 0x65, 0x12,
-	0x16, 0x0a, 0x06, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52,
-	0x06, 0x63, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x22, 0x22, 0x0a, 0x08, 0x45, 0x76, 0x65, 0x6e, 0x74,
-	0x41, 0x63, 0x6b, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x32, 0x4b, 0x0a, 0x0c, 0x45,
-	0x76, 0x65, 0x6e, 0x74, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x3b, 0x0a, 0x0e, 0x53,
-	0x65, 0x6e, 0x64, 0x53, 0x63, 0x6f, 0x72, 0x65, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x18, 0x2e,
-	0x65, 0x76, 0x65, 0x6e, 0x74, 0x2e, 0x53, 0x63, 0x6f, 0x72, 0x65, 0x45, 0x76, 0x65, 0x6e, 0x74,
-	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0f, 0x2e, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x2e,
-	0x45, 0x76, 0x65, 0x6e, 0x74, 0x41, 0x63, 0x6b, 0x42, 0x35, 0x5a, 0x33, 0x67, 0x69, 0x74, 0x68,
-	0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x73, 0x6f, 0x6c, 0x6f, 0x72, 0x61, 0x64, 0x2f, 0x73,
-	0x63, 0x6f, 0x72, 0x65, 0x68, 0x75, 0x62, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x65, 0x76, 0x65, 0x6e,
-	0x74, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x3b, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x70, 0x62, 0x62,
-	0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+func (x *ScoreEventResult) Reset() {
+	*x = ScoreEventResult{}
+	mi := &file_pkg_event_proto_event_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
+func (x *ScoreEventResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScoreEventResult) ProtoMessage() {}
+
+func (x *ScoreEventResult) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_event_proto_event_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScoreEventResult.ProtoReflect.Descriptor instead.
+func (*ScoreEventResult) Descriptor() ([]byte, []int) {
+	return file_pkg_event_proto_event_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ScoreEventResult) GetIndex() int32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *ScoreEventResult) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ScoreEventResult) GetEventId() string {
+	if x != nil {
+		return x.EventId
+	}
+	return ""
+}
+
+func (x *ScoreEventResult) GetDuplicate() bool {
+	if x != nil {
+		return x.Duplicate
+	}
+	return false
+}
+
+func (x *ScoreEventResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *ScoreEventResult) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+type SendScoreEventsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Results       []*ScoreEventResult    `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	Accepted      int32                  `protobuf:"varint,2,opt,name=accepted,proto3" json:"accepted,omitempty"`
+	Rejected      int32                  `protobuf:"varint,3,opt,name=rejected,proto3" json:"rejected,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SendScoreEventsResponse) Reset() {
+	*x = SendScoreEventsResponse{}
+	mi := &file_pkg_event_proto_event_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SendScoreEventsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SendScoreEventsResponse) ProtoMessage() {}
+
+func (x *SendScoreEventsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_event_proto_event_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SendScoreEventsResponse.ProtoReflect.Descriptor instead.
+func (*SendScoreEventsResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_event_proto_event_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *SendScoreEventsResponse) GetResults() []*ScoreEventResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+func (x *SendScoreEventsResponse) GetAccepted() int32 {
+	if x != nil {
+		return x.Accepted
+	}
+	return 0
+}
+
+func (x *SendScoreEventsResponse) GetRejected() int32 {
+	if x != nil {
+		return x.Rejected
+	}
+	return 0
+}
+
+var File_pkg_event_proto_event_proto protoreflect.FileDescriptor
+
+const file_pkg_event_proto_event_proto_rawDesc = "" +
+	"\n" +
+	"\x1bpkg/event/proto/event.proto\x12\x05event\x1a\x1cgoogle/api/annotations.proto\"\x90\x01\n" +
+	"\x11ScoreEventRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x03R\x06userId\x12\x1b\n" +
+	"\tnew_score\x18\x02 \x01(\x03R\bnewScore\x12\x16\n" +
+	"\x06change\x18\x03 \x01(\x05R\x06change\x12\x12\n" +
+	"\x04type\x18\x04 \x01(\tR\x04type\x12\x19\n" +
+	"\bevent_id\x18\x05 \x01(\tR\aeventId\"[\n" +
+	"\bEventAck\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\x12\x19\n" +
+	"\bevent_id\x18\x02 \x01(\tR\aeventId\x12\x1c\n" +
+	"\tduplicate\x18\x03 \x01(\bR\tduplicate\"J\n" +
+	"\x16SendScoreEventsRequest\x120\n" +
+	"\x06events\x18\x01 \x03(\v2\x18.event.ScoreEventRequestR\x06events\"\xa3\x01\n" +
+	"\x10ScoreEventResult\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x05R\x05index\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12\x19\n" +
+	"\bevent_id\x18\x03 \x01(\tR\aeventId\x12\x1c\n" +
+	"\tduplicate\x18\x04 \x01(\bR\tduplicate\x12\x14\n" +
+	"\x05error\x18\x05 \x01(\tR\x05error\x12\x12\n" +
+	"\x04code\x18\x06 \x01(\x05R\x04code\"\x84\x01\n" +
+	"\x17SendScoreEventsResponse\x121\n" +
+	"\aresults\x18\x01 \x03(\v2\x17.event.ScoreEventResultR\aresults\x12\x1a\n" +
+	"\baccepted\x18\x02 \x01(\x05R\baccepted\x12\x1a\n" +
+	"\brejected\x18\x03 \x01(\x05R\brejected2\xbb\x02\n" +
+	"\fEventService\x12\\\n" +
+	"\x0eSendScoreEvent\x12\x18.event.ScoreEventRequest\x1a\x0f.event.EventAck\"\x1f\x82\xd3\xe4\x93\x02\x19:\x01*\"\x14/api/v1/score-events\x12|\n" +
+	"\x0fSendScoreEvents\x12\x1d.event.SendScoreEventsRequest\x1a\x1e.event.SendScoreEventsResponse\"*\x82\xd3\xe4\x93\x02$:\x06events\"\x1a/api/v1/score-events:batch\x12O\n" +
+	"\x11StreamScoreEvents\x12\x18.event.ScoreEventRequest\x1a\x1e.event.SendScoreEventsResponse(\x01B7Z5github.com/emorenkov/scorehub/pkg/event/proto;eventpbb\x06proto3"
+
 var (
 	file_pkg_event_proto_event_proto_rawDescOnce sync.Once
-	file_pkg_event_proto_event_proto_rawDescData = file_pkg_event_proto_event_proto_rawDesc
+	file_pkg_event_proto_event_proto_rawDescData []byte
 )
 
 func file_pkg_event_proto_event_proto_rawDescGZIP() []byte {
 	file_pkg_event_proto_event_proto_rawDescOnce.Do(func() {
-		file_pkg_event_proto_event_proto_rawDescData = protoimpl.X.CompressGZIP(file_pkg_event_proto_event_proto_rawDescData)
+		file_pkg_event_proto_event_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_pkg_event_proto_event_proto_rawDesc), len(file_pkg_event_proto_event_proto_rawDesc)))
 	})
 	return file_pkg_event_proto_event_proto_rawDescData
 }
 
-var file_pkg_event_proto_event_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
-var file_pkg_event_proto_event_proto_goTypes = []interface{}{
-	(*ScoreEventRequest)(nil), // 0: event.ScoreEventRequest
-	(*EventAck)(nil),          // 1: event.EventAck
+var file_pkg_event_proto_event_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_pkg_event_proto_event_proto_goTypes = []any{
+	(*ScoreEventRequest)(nil),       // 0: event.ScoreEventRequest
+	(*EventAck)(nil),                // 1: event.EventAck
+	(*SendScoreEventsRequest)(nil),  // 2: event.SendScoreEventsRequest
+	(*ScoreEventResult)(nil),        // 3: event.ScoreEventResult
+	(*SendScoreEventsResponse)(nil), // 4: event.SendScoreEventsResponse
 }
 var file_pkg_event_proto_event_proto_depIdxs = []int32{
-	0, // 0: event.EventService.SendScoreEvent:input_type -> event.ScoreEventRequest
-	1, // 1: event.EventService.SendScoreEvent:output_type -> event.EventAck
-	1, // [1:2] is the sub-list for method output_type
-	0, // [0:1] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	0, // 0: event.SendScoreEventsRequest.events:type_name -> event.ScoreEventRequest
+	3, // 1: event.SendScoreEventsResponse.results:type_name -> event.ScoreEventResult
+	0, // 2: event.EventService.SendScoreEvent:input_type -> event.ScoreEventRequest
+	2, // 3: event.EventService.SendScoreEvents:input_type -> event.SendScoreEventsRequest
+	0, // 4: event.EventService.StreamScoreEvents:input_type -> event.ScoreEventRequest
+	1, // 5: event.EventService.SendScoreEvent:output_type -> event.EventAck
+	4, // 6: event.EventService.SendScoreEvents:output_type -> event.SendScoreEventsResponse
+	4, // 7: event.EventService.StreamScoreEvents:output_type -> event.SendScoreEventsResponse
+	5, // [5:8] is the sub-list for method output_type
+	2, // [2:5] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
 }
 
 func init() { file_pkg_event_proto_event_proto_init() }
@@ -187,39 +428,13 @@ func file_pkg_event_proto_event_proto_init() {
 	if File_pkg_event_proto_event_proto != nil {
 		return
 	}
-	if !protoimpl.UnsafeEnabled {
-		file_pkg_event_proto_event_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ScoreEventRequest); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_pkg_event_proto_event_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*EventAck); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
-			RawDescriptor: file_pkg_event_proto_event_proto_rawDesc,
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_pkg_event_proto_event_proto_rawDesc), len(file_pkg_event_proto_event_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   2,
+			NumMessages:   5,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -228,7 +443,6 @@ func file_pkg_event_proto_event_proto_init() {
 		MessageInfos:      file_pkg_event_proto_event_proto_msgTypes,
 	}.Build()
 	File_pkg_event_proto_event_proto = out.File
-	file_pkg_event_proto_event_proto_rawDesc = nil
 	file_pkg_event_proto_event_proto_goTypes = nil
 	file_pkg_event_proto_event_proto_depIdxs = nil
 }
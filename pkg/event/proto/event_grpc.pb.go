@@ -23,6 +23,13 @@ const _ = grpc.SupportPackageIsVersion7
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type EventServiceClient interface {
 	SendScoreEvent(ctx context.Context, in *ScoreEventRequest, opts ...grpc.CallOption) (*EventAck, error)
+	// SendScoreEvents validates and publishes a batch; each event gets its own result.
+	SendScoreEvents(ctx context.Context, in *SendScoreEventsRequest, opts ...grpc.CallOption) (*SendScoreEventsResponse, error)
+	// StreamScoreEvents accepts a client stream of events, publishing them in chunks,
+	// and returns the results once the client closes the stream. When the stream fails
+	// after chunks were published, the error status carries their results as a
+	// SendScoreEventsResponse detail.
+	StreamScoreEvents(ctx context.Context, opts ...grpc.CallOption) (EventService_StreamScoreEventsClient, error)
 }
 
 type eventServiceClient struct {
@@ -42,11 +49,61 @@ func (c *eventServiceClient) SendScoreEvent(ctx context.Context, in *ScoreEventR
 	return out, nil
 }
 
+func (c *eventServiceClient) SendScoreEvents(ctx context.Context, in *SendScoreEventsRequest, opts ...grpc.CallOption) (*SendScoreEventsResponse, error) {
+	out := new(SendScoreEventsResponse)
+	err := c.cc.Invoke(ctx, "/event.EventService/SendScoreEvents", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventServiceClient) StreamScoreEvents(ctx context.Context, opts ...grpc.CallOption) (EventService_StreamScoreEventsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &EventService_ServiceDesc.Streams[0], "/event.EventService/StreamScoreEvents", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &eventServiceStreamScoreEventsClient{stream}
+	return x, nil
+}
+
+type EventService_StreamScoreEventsClient interface {
+	Send(*ScoreEventRequest) error
+	CloseAndRecv() (*SendScoreEventsResponse, error)
+	grpc.ClientStream
+}
+
+type eventServiceStreamScoreEventsClient struct {
+	grpc.ClientStream
+}
+
+func (x *eventServiceStreamScoreEventsClient) Send(m *ScoreEventRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *eventServiceStreamScoreEventsClient) CloseAndRecv() (*SendScoreEventsResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(SendScoreEventsResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // EventServiceServer is the server API for EventService service.
 // All implementations must embed UnimplementedEventServiceServer
 // for forward compatibility
 type EventServiceServer interface {
 	SendScoreEvent(context.Context, *ScoreEventRequest) (*EventAck, error)
+	// SendScoreEvents validates and publishes a batch; each event gets its own result.
+	SendScoreEvents(context.Context, *SendScoreEventsRequest) (*SendScoreEventsResponse, error)
+	// StreamScoreEvents accepts a client stream of events, publishing them in chunks,
+	// and returns the results once the client closes the stream. When the stream fails
+	// after chunks were published, the error status carries their results as a
+	// SendScoreEventsResponse detail.
+	StreamScoreEvents(EventService_StreamScoreEventsServer) error
 	mustEmbedUnimplementedEventServiceServer()
 }
 
@@ -57,6 +114,12 @@ type UnimplementedEventServiceServer struct {
 func (UnimplementedEventServiceServer) SendScoreEvent(context.Context, *ScoreEventRequest) (*EventAck, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SendScoreEvent not implemented")
 }
+func (UnimplementedEventServiceServer) SendScoreEvents(context.Context, *SendScoreEventsRequest) (*SendScoreEventsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SendScoreEvents not implemented")
+}
+func (UnimplementedEventServiceServer) StreamScoreEvents(EventService_StreamScoreEventsServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamScoreEvents not implemented")
+}
 func (UnimplementedEventServiceServer) mustEmbedUnimplementedEventServiceServer() {}
 
 // UnsafeEventServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -88,6 +151,50 @@ func _EventService_SendScoreEvent_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _EventService_SendScoreEvents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SendScoreEventsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventServiceServer).SendScoreEvents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/event.EventService/SendScoreEvents",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventServiceServer).SendScoreEvents(ctx, req.(*SendScoreEventsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EventService_StreamScoreEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(EventServiceServer).StreamScoreEvents(&eventServiceStreamScoreEventsServer{stream})
+}
+
+type EventService_StreamScoreEventsServer interface {
+	SendAndClose(*SendScoreEventsResponse) error
+	Recv() (*ScoreEventRequest, error)
+	grpc.ServerStream
+}
+
+type eventServiceStreamScoreEventsServer struct {
+	grpc.ServerStream
+}
+
+func (x *eventServiceStreamScoreEventsServer) SendAndClose(m *SendScoreEventsResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *eventServiceStreamScoreEventsServer) Recv() (*ScoreEventRequest, error) {
+	m := new(ScoreEventRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // EventService_ServiceDesc is the grpc.ServiceDesc for EventService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -99,7 +206,17 @@ var EventService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "SendScoreEvent",
 			Handler:    _EventService_SendScoreEvent_Handler,
 		},
+		{
+			MethodName: "SendScoreEvents",
+			Handler:    _EventService_SendScoreEvents_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamScoreEvents",
+			Handler:       _EventService_StreamScoreEvents_Handler,
+			ClientStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "pkg/event/proto/event.proto",
 }
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/emorenkov/scorehub/pkg/event"
+	"github.com/redis/go-redis/v9"
+)
+
+// IdempotencyRecord is what is remembered about an event_id during the dedupe window.
+type IdempotencyRecord struct {
+	// Fingerprint identifies the payload first sent with the event_id.
+	Fingerprint string `json:"fingerprint"`
+	// Ack is nil while the first request is still being processed.
+	Ack *event.EventAck `json:"ack,omitempty"`
+}
+
+// IdempotencyStore dedupes score events by event_id.
+type IdempotencyStore interface {
+	// Claim reserves key for window. When the key is already taken it returns the
+	// existing record and false.
+	Claim(ctx context.Context, key, fingerprint string, window time.Duration) (*IdempotencyRecord, bool, error)
+	// Complete stores the ack for a claimed key, keeping its expiry.
+	Complete(ctx context.Context, key string, rec *IdempotencyRecord) error
+	// Release forgets a claimed key so the request can be retried.
+	Release(ctx context.Context, key string) error
+}
+
+const idempotencyKeyPrefix = "idempotency:score-event:"
+
+// RedisIdempotencyStore keeps idempotency records in Redis with the dedupe window as TTL.
+type RedisIdempotencyStore struct {
+	client *redis.Client
+}
+
+// NewRedisIdempotencyStore returns nil when client is nil, which disables deduplication.
+func NewRedisIdempotencyStore(client *redis.Client) *RedisIdempotencyStore {
+	if client == nil {
+		return nil
+	}
+	return &RedisIdempotencyStore{client: client}
+}
+
+func (s *RedisIdempotencyStore) Claim(ctx context.Context, key, fingerprint string, window time.Duration) (*IdempotencyRecord, bool, error) {
+	rec := &IdempotencyRecord{Fingerprint: fingerprint}
+	payload, err := json.Marshal(rec)
+	if err != nil {
+		return nil, false, err
+	}
+	ok, err := s.client.SetNX(ctx, idempotencyKeyPrefix+key, payload, window).Result()
+	if err != nil {
+		return nil, false, err
+	}
+	if ok {
+		return rec, true, nil
+	}
+
+	raw, err := s.client.Get(ctx, idempotencyKeyPrefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		// Expired between SETNX and GET; claim again.
+		return s.Claim(ctx, key, fingerprint, window)
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	var existing IdempotencyRecord
+	if err := json.Unmarshal(raw, &existing); err != nil {
+		return nil, false, err
+	}
+	return &existing, false, nil
+}
+
+func (s *RedisIdempotencyStore) Complete(ctx context.Context, key string, rec *IdempotencyRecord) error {
+	payload, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	err = s.client.SetArgs(ctx, idempotencyKeyPrefix+key, payload, redis.SetArgs{Mode: "XX", KeepTTL: true}).Err()
+	if errors.Is(err, redis.Nil) {
+		// The window already expired; there is nothing left to complete.
+		return nil
+	}
+	return err
+}
+
+func (s *RedisIdempotencyStore) Release(ctx context.Context, key string) error {
+	return s.client.Del(ctx, idempotencyKeyPrefix+key).Err()
+}
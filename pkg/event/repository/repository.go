@@ -2,16 +2,21 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"time"
 
 	ckafka "github.com/emorenkov/scorehub/pkg/common/kafka"
 	"github.com/emorenkov/scorehub/pkg/event"
+	"github.com/segmentio/kafka-go"
 )
 
 type Publisher interface {
 	PublishScoreEvent(ctx context.Context, ev *event.ScoreEvent) error
+	// PublishScoreEvents writes evs in one batch. It returns nil when all succeed,
+	// otherwise a slice aligned with evs holding each event's error (nil on success).
+	PublishScoreEvents(ctx context.Context, evs []*event.ScoreEvent) []error
 	Close() error
 }
 
@@ -36,6 +41,31 @@ func (p *KafkaPublisher) PublishScoreEvent(ctx context.Context, ev *event.ScoreE
 	return p.producer.SendMessage(ctx, keyForUser(ev.UserID), ev)
 }
 
+func (p *KafkaPublisher) PublishScoreEvents(ctx context.Context, evs []*event.ScoreEvent) []error {
+	if len(evs) == 0 {
+		return nil
+	}
+	msgs := make([]ckafka.Message, 0, len(evs))
+	for _, ev := range evs {
+		msgs = append(msgs, ckafka.Message{Key: keyForUser(ev.UserID), Value: ev})
+	}
+	err := p.producer.SendMessages(ctx, msgs)
+	if err == nil {
+		return nil
+	}
+
+	errs := make([]error, len(evs))
+	var writeErrs kafka.WriteErrors
+	if errors.As(err, &writeErrs) && len(writeErrs) == len(evs) {
+		copy(errs, writeErrs)
+		return errs
+	}
+	for i := range errs {
+		errs[i] = err
+	}
+	return errs
+}
+
 func (p *KafkaPublisher) Close() error {
 	return p.producer.Close()
 }
@@ -0,0 +1,69 @@
+package rest
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/emorenkov/scorehub/pkg/common/gateway"
+	eventpb "github.com/emorenkov/scorehub/pkg/event/proto"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+)
+
+// MaxBodyBytes caps REST request bodies, in practice score-events:batch.
+const MaxBodyBytes = 16 << 20 // 16MB
+
+// GatewayOptions lets POST /api/v1/score-events:batch take NDJSON (one event per line)
+// when the Content-Type is application/x-ndjson or application/jsonl, besides the JSON
+// array the gateway accepts by default.
+func GatewayOptions() []runtime.ServeMuxOption {
+	m := &ndjsonMarshaler{JSONPb: gateway.JSONMarshaler()}
+	return []runtime.ServeMuxOption{
+		runtime.WithMarshalerOption("application/x-ndjson", m),
+		runtime.WithMarshalerOption("application/jsonl", m),
+	}
+}
+
+// ndjsonMarshaler decodes a list of score events from NDJSON and otherwise behaves like
+// the gateway's JSON marshaler, so responses are still JSON.
+type ndjsonMarshaler struct {
+	*runtime.JSONPb
+}
+
+func (m *ndjsonMarshaler) NewDecoder(r io.Reader) runtime.Decoder {
+	return runtime.DecoderFunc(func(v any) error {
+		events, ok := v.(*[]*eventpb.ScoreEventRequest)
+		if !ok {
+			return m.JSONPb.NewDecoder(r).Decode(v)
+		}
+		return m.decodeEvents(r, events)
+	})
+}
+
+// decodeEvents parses one JSON object per line, skipping blank lines.
+func (m *ndjsonMarshaler) decodeEvents(r io.Reader, events *[]*eventpb.ScoreEventRequest) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), MaxBodyBytes)
+	line := 0
+	for sc.Scan() {
+		line++
+		b := bytes.TrimSpace(sc.Bytes())
+		if len(b) == 0 {
+			continue
+		}
+		ev := &eventpb.ScoreEventRequest{}
+		if err := m.Unmarshal(b, ev); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+		*events = append(*events, ev)
+	}
+	if err := sc.Err(); err != nil {
+		return err
+	}
+	if len(*events) == 0 {
+		return errors.New("no events")
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package rest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/emorenkov/scorehub/pkg/common/gateway"
+	eventpb "github.com/emorenkov/scorehub/pkg/event/proto"
+)
+
+func TestNDJSONDecodesOneEventPerLine(t *testing.T) {
+	m := &ndjsonMarshaler{JSONPb: gateway.JSONMarshaler()}
+	body := "{\"user_id\":1,\"new_score\":700,\"change\":5}\n\n{\"user_id\":\"2\",\"new_score\":640,\"change\":-12,\"unknown\":true}\n"
+
+	var events []*eventpb.ScoreEventRequest
+	if err := m.NewDecoder(strings.NewReader(body)).Decode(&events); err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want 2", len(events))
+	}
+	if events[0].GetUserId() != 1 || events[1].GetUserId() != 2 || events[1].GetChange() != -12 {
+		t.Fatalf("unexpected events %v", events)
+	}
+}
+
+func TestNDJSONRejectsBadInput(t *testing.T) {
+	m := &ndjsonMarshaler{JSONPb: gateway.JSONMarshaler()}
+	for name, body := range map[string]string{
+		"empty":    "\n \n",
+		"bad line": "{\"user_id\":1}\n{oops}\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			var events []*eventpb.ScoreEventRequest
+			if err := m.NewDecoder(strings.NewReader(body)).Decode(&events); err == nil {
+				t.Fatal("Decode succeeded")
+			}
+		})
+	}
+}
+
+func TestNDJSONFallsBackToJSONForOtherMessages(t *testing.T) {
+	m := &ndjsonMarshaler{JSONPb: gateway.JSONMarshaler()}
+	var ev eventpb.ScoreEventRequest
+	if err := m.NewDecoder(strings.NewReader(`{"user_id":3}`)).Decode(&ev); err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if ev.GetUserId() != 3 {
+		t.Fatalf("user_id = %d, want 3", ev.GetUserId())
+	}
+}
@@ -2,44 +2,266 @@ package service
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
 	apperrors "github.com/emorenkov/scorehub/pkg/common/errors"
+	logpkg "github.com/emorenkov/scorehub/pkg/common/logger"
 	"github.com/emorenkov/scorehub/pkg/event"
 	"github.com/emorenkov/scorehub/pkg/event/repository"
 	userpb "github.com/emorenkov/scorehub/pkg/user/models/proto"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
+const maxEventIDLength = 128
+
 type Event interface {
 	Send(ctx context.Context, ev *event.ScoreEvent) (*event.EventAck, error)
+	// SendBatch validates and publishes evs with one user lookup and one Kafka write.
+	// Per-event failures are reported in the results; the error is reserved for
+	// requests that cannot be processed at all.
+	SendBatch(ctx context.Context, evs []*event.ScoreEvent) (*event.BatchAck, error)
 }
 
 type eventService struct {
-	pub        repository.Publisher
-	userClient userpb.UserServiceClient
+	pub          repository.Publisher
+	userClient   userpb.UserServiceClient
+	dedupe       repository.IdempotencyStore
+	window       time.Duration
+	maxBatchSize int
 }
 
-func NewEvent(pub repository.Publisher, userClient userpb.UserServiceClient) Event {
-	return &eventService{pub: pub, userClient: userClient}
+// NewEvent constructs the service. When dedupe is non-nil, events carrying an event_id
+// are published at most once per window and replays return the original ack. Batches
+// larger than maxBatchSize are rejected.
+func NewEvent(pub repository.Publisher, userClient userpb.UserServiceClient, dedupe repository.IdempotencyStore, window time.Duration, maxBatchSize int) Event {
+	return &eventService{pub: pub, userClient: userClient, dedupe: dedupe, window: window, maxBatchSize: maxBatchSize}
 }
 
 func (s *eventService) Send(ctx context.Context, ev *event.ScoreEvent) (*event.EventAck, error) {
-	if ev == nil {
-		return nil, apperrors.NewStatusError(http.StatusBadRequest, "event is required")
+	if err := normalize(ev); err != nil {
+		return nil, err
 	}
-	if ev.UserID <= 0 {
-		return nil, apperrors.NewStatusError(http.StatusBadRequest, "user_id must be positive")
+
+	if s.userClient == nil {
+		return nil, apperrors.NewStatusError(http.StatusInternalServerError, "user client not configured")
 	}
-	if ev.NewScore < 0 {
-		return nil, apperrors.NewStatusError(http.StatusBadRequest, "new_score must be non-negative")
+
+	if ev.EventID == "" || s.dedupe == nil || s.window <= 0 {
+		if ev.EventID == "" {
+			// Consumers dedupe on event_id, so every published event carries one.
+			ev.EventID = uuid.NewString()
+		}
+		return s.publish(ctx, ev)
 	}
 
+	rec, claimed, err := s.dedupe.Claim(ctx, ev.EventID, fingerprint(ev), s.window)
+	if err != nil {
+		return nil, apperrors.WrapStatus(err, http.StatusServiceUnavailable, "check idempotency key")
+	}
+	if !claimed {
+		return replay(ev, rec)
+	}
+
+	ack, err := s.publish(ctx, ev)
+	if err != nil {
+		// Let the client retry with the same event_id.
+		_ = s.dedupe.Release(context.WithoutCancel(ctx), ev.EventID)
+		return nil, err
+	}
+	rec.Ack = ack
+	s.complete(ctx, ev.EventID, rec)
+	return ack, nil
+}
+
+func (s *eventService) SendBatch(ctx context.Context, evs []*event.ScoreEvent) (*event.BatchAck, error) {
+	if len(evs) == 0 {
+		return nil, apperrors.NewStatusError(http.StatusBadRequest, "events are required")
+	}
+	if s.maxBatchSize > 0 && len(evs) > s.maxBatchSize {
+		return nil, apperrors.NewStatusError(http.StatusBadRequest, fmt.Sprintf("at most %d events per batch", s.maxBatchSize))
+	}
 	if s.userClient == nil {
 		return nil, apperrors.NewStatusError(http.StatusInternalServerError, "user client not configured")
 	}
 
+	b := &batch{results: make([]event.ScoreEventResult, len(evs))}
+	for i, ev := range evs {
+		b.results[i].Index = i
+		if err := normalize(ev); err != nil {
+			b.fail(i, err)
+			continue
+		}
+		if !s.claim(ctx, b, i, ev) {
+			continue
+		}
+		b.pending = append(b.pending, i)
+	}
+
+	s.validateUsers(ctx, b, evs)
+
+	if len(b.pending) > 0 {
+		toPublish := make([]*event.ScoreEvent, 0, len(b.pending))
+		for _, i := range b.pending {
+			toPublish = append(toPublish, evs[i])
+		}
+		errs := s.pub.PublishScoreEvents(ctx, toPublish)
+		for j, i := range b.pending {
+			if errs != nil && errs[j] != nil {
+				b.fail(i, apperrors.WrapStatus(errs[j], http.StatusInternalServerError, "publish score event"))
+				continue
+			}
+			ack := &event.EventAck{Status: "ok", EventID: evs[i].EventID}
+			if rec, ok := b.claims[i]; ok {
+				rec.Ack = ack
+				s.complete(ctx, evs[i].EventID, rec)
+			}
+			b.succeed(i, ack)
+		}
+	}
+
+	// Items that were never published may be retried with the same event_id.
+	for i, rec := range b.claims {
+		if rec.Ack == nil {
+			_ = s.dedupe.Release(context.WithoutCancel(ctx), evs[i].EventID)
+		}
+	}
+	return b.ack(), nil
+}
+
+// complete stores the ack of a published event under its claimed event_id. The event is
+// already on Kafka, so a failure is logged rather than returned, and the claim is
+// released instead of being left pending until the window ends: a retry then publishes
+// the event again, which consumers dedupe by event_id.
+func (s *eventService) complete(ctx context.Context, eventID string, rec *repository.IdempotencyRecord) {
+	ctx = context.WithoutCancel(ctx)
+	err := s.dedupe.Complete(ctx, eventID, rec)
+	if err == nil {
+		return
+	}
+	log := logpkg.FromContext(ctx)
+	log.Warn("store idempotency record failed, releasing the claim", zap.Error(err), zap.String("event_id", eventID))
+	if err := s.dedupe.Release(ctx, eventID); err != nil {
+		log.Error("release idempotency claim failed", zap.Error(err), zap.String("event_id", eventID))
+	}
+}
+
+// batch tracks per-event state while a batch is processed.
+type batch struct {
+	results []event.ScoreEventResult
+	pending []int
+	claims  map[int]*repository.IdempotencyRecord
+}
+
+func (b *batch) fail(i int, err error) {
+	r := &b.results[i]
+	r.Status = "error"
+	r.Code = http.StatusInternalServerError
+	r.Error = err.Error()
+	if se, ok := apperrors.AsStatusError(err); ok {
+		r.Code = se.Status
+		r.Error = se.Message
+	}
+}
+
+func (b *batch) succeed(i int, ack *event.EventAck) {
+	r := &b.results[i]
+	r.Status = ack.Status
+	r.Code = http.StatusOK
+	r.EventID = ack.EventID
+	r.Duplicate = ack.Duplicate
+	r.Error = ""
+}
+
+func (b *batch) ack() *event.BatchAck {
+	out := &event.BatchAck{Results: b.results}
+	for _, r := range b.results {
+		if r.Status == "ok" {
+			out.Accepted++
+		} else {
+			out.Rejected++
+		}
+	}
+	return out
+}
+
+// claim reserves ev's event_id for the batch item i. It returns false when the item is
+// already answered (a replay or an error) and must not be published.
+func (s *eventService) claim(ctx context.Context, b *batch, i int, ev *event.ScoreEvent) bool {
+	if ev.EventID == "" || s.dedupe == nil || s.window <= 0 {
+		if ev.EventID == "" {
+			ev.EventID = uuid.NewString()
+		}
+		return true
+	}
+	rec, claimed, err := s.dedupe.Claim(ctx, ev.EventID, fingerprint(ev), s.window)
+	if err != nil {
+		b.fail(i, apperrors.WrapStatus(err, http.StatusServiceUnavailable, "check idempotency key"))
+		return false
+	}
+	if !claimed {
+		ack, err := replay(ev, rec)
+		if err != nil {
+			b.fail(i, err)
+		} else {
+			b.succeed(i, ack)
+		}
+		return false
+	}
+	if b.claims == nil {
+		b.claims = make(map[int]*repository.IdempotencyRecord)
+	}
+	b.claims[i] = rec
+	return true
+}
+
+// validateUsers checks every pending item's user with a single BatchGetUsers call and
+// drops items whose user does not exist.
+func (s *eventService) validateUsers(ctx context.Context, b *batch, evs []*event.ScoreEvent) {
+	if len(b.pending) == 0 {
+		return
+	}
+	seen := make(map[int64]struct{}, len(b.pending))
+	ids := make([]int64, 0, len(b.pending))
+	for _, i := range b.pending {
+		if _, ok := seen[evs[i].UserID]; !ok {
+			seen[evs[i].UserID] = struct{}{}
+			ids = append(ids, evs[i].UserID)
+		}
+	}
+
+	resp, err := s.userClient.BatchGetUsers(ctx, &userpb.BatchGetUsersRequest{Ids: ids})
+	if err != nil {
+		for _, i := range b.pending {
+			b.fail(i, apperrors.WrapStatus(err, http.StatusInternalServerError, "validate users"))
+		}
+		b.pending = nil
+		return
+	}
+	missing := make(map[int64]struct{}, len(resp.GetMissingIds()))
+	for _, id := range resp.GetMissingIds() {
+		missing[id] = struct{}{}
+	}
+
+	kept := b.pending[:0]
+	for _, i := range b.pending {
+		if _, ok := missing[evs[i].UserID]; ok {
+			b.fail(i, apperrors.NewStatusError(http.StatusNotFound, "not found"))
+			continue
+		}
+		kept = append(kept, i)
+	}
+	b.pending = kept
+}
+
+func (s *eventService) publish(ctx context.Context, ev *event.ScoreEvent) (*event.EventAck, error) {
 	if _, err := s.userClient.GetUser(ctx, &userpb.GetUserRequest{Id: ev.UserID}); err != nil {
 		if status.Code(err) == codes.NotFound {
 			return nil, apperrors.NewStatusError(http.StatusNotFound, "not found")
@@ -50,5 +272,45 @@ func (s *eventService) Send(ctx context.Context, ev *event.ScoreEvent) (*event.E
 	if err := s.pub.PublishScoreEvent(ctx, ev); err != nil {
 		return nil, apperrors.WrapStatus(err, http.StatusInternalServerError, "publish score event")
 	}
-	return &event.EventAck{Status: "ok"}, nil
+	return &event.EventAck{Status: "ok", EventID: ev.EventID}, nil
+}
+
+// normalize validates ev and fills in defaults.
+func normalize(ev *event.ScoreEvent) error {
+	if ev == nil {
+		return apperrors.NewStatusError(http.StatusBadRequest, "event is required")
+	}
+	if ev.UserID <= 0 {
+		return apperrors.NewStatusError(http.StatusBadRequest, "user_id must be positive")
+	}
+	if ev.NewScore < 0 {
+		return apperrors.NewStatusError(http.StatusBadRequest, "new_score must be non-negative")
+	}
+	if ev.Type = strings.TrimSpace(ev.Type); ev.Type == "" {
+		ev.Type = event.EventTypeScoreUpdate
+	}
+	ev.EventID = strings.TrimSpace(ev.EventID)
+	if len(ev.EventID) > maxEventIDLength {
+		return apperrors.NewStatusError(http.StatusBadRequest, fmt.Sprintf("event_id must be at most %d characters", maxEventIDLength))
+	}
+	return nil
+}
+
+// replay answers a request whose event_id was already claimed within the window.
+func replay(ev *event.ScoreEvent, rec *repository.IdempotencyRecord) (*event.EventAck, error) {
+	if rec.Fingerprint != fingerprint(ev) {
+		return nil, apperrors.NewStatusError(http.StatusUnprocessableEntity, "event_id was already used with a different payload")
+	}
+	if rec.Ack == nil {
+		return nil, apperrors.NewStatusError(http.StatusConflict, "an event with this event_id is still being processed")
+	}
+	ack := *rec.Ack
+	ack.Duplicate = true
+	return &ack, nil
+}
+
+// fingerprint identifies the payload of ev so reuse of an event_id for a different event is detected.
+func fingerprint(ev *event.ScoreEvent) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%d|%d|%d|%s", ev.UserID, ev.NewScore, ev.Change, ev.Type)))
+	return hex.EncodeToString(sum[:])
 }
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	apperrors "github.com/emorenkov/scorehub/pkg/common/errors"
+	"github.com/emorenkov/scorehub/pkg/event"
+	"github.com/emorenkov/scorehub/pkg/event/repository"
+	userpb "github.com/emorenkov/scorehub/pkg/user/models/proto"
+	"google.golang.org/grpc"
+)
+
+type memoryStore struct {
+	mu          sync.Mutex
+	recs        map[string]repository.IdempotencyRecord
+	completeErr error
+}
+
+func (s *memoryStore) Claim(_ context.Context, key, fingerprint string, _ time.Duration) (*repository.IdempotencyRecord, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if rec, ok := s.recs[key]; ok {
+		return &rec, false, nil
+	}
+	s.recs[key] = repository.IdempotencyRecord{Fingerprint: fingerprint}
+	return &repository.IdempotencyRecord{Fingerprint: fingerprint}, true, nil
+}
+
+func (s *memoryStore) Complete(_ context.Context, key string, rec *repository.IdempotencyRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.completeErr != nil {
+		return s.completeErr
+	}
+	s.recs[key] = *rec
+	return nil
+}
+
+func (s *memoryStore) Release(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.recs, key)
+	return nil
+}
+
+type publisher struct {
+	published []string
+	err       error
+}
+
+func (p *publisher) PublishScoreEvent(_ context.Context, ev *event.ScoreEvent) error {
+	if p.err != nil {
+		return p.err
+	}
+	p.published = append(p.published, ev.EventID)
+	return nil
+}
+
+func (p *publisher) PublishScoreEvents(ctx context.Context, evs []*event.ScoreEvent) []error {
+	errs := make([]error, len(evs))
+	failed := false
+	for i, ev := range evs {
+		if errs[i] = p.PublishScoreEvent(ctx, ev); errs[i] != nil {
+			failed = true
+		}
+	}
+	if !failed {
+		return nil
+	}
+	return errs
+}
+
+func (p *publisher) Close() error { return nil }
+
+type users struct {
+	userpb.UserServiceClient
+}
+
+func (users) GetUser(context.Context, *userpb.GetUserRequest, ...grpc.CallOption) (*userpb.UserResponse, error) {
+	return &userpb.UserResponse{}, nil
+}
+
+func (users) BatchGetUsers(context.Context, *userpb.BatchGetUsersRequest, ...grpc.CallOption) (*userpb.BatchGetUsersResponse, error) {
+	return &userpb.BatchGetUsersResponse{}, nil
+}
+
+func newTestService(store *memoryStore, pub *publisher) Event {
+	return NewEvent(pub, users{}, store, time.Hour, 100)
+}
+
+func statusOf(err error) int {
+	if se, ok := apperrors.AsStatusError(err); ok {
+		return se.Status
+	}
+	return 0
+}
+
+func TestSendDedupesByEventID(t *testing.T) {
+	store := &memoryStore{recs: map[string]repository.IdempotencyRecord{}}
+	pub := &publisher{}
+	svc := newTestService(store, pub)
+	ctx := context.Background()
+
+	ack, err := svc.Send(ctx, &event.ScoreEvent{EventID: "e1", UserID: 1, NewScore: 700})
+	if err != nil || ack.Duplicate {
+		t.Fatalf("first send: ack=%+v err=%v", ack, err)
+	}
+	ack, err = svc.Send(ctx, &event.ScoreEvent{EventID: "e1", UserID: 1, NewScore: 700})
+	if err != nil || !ack.Duplicate {
+		t.Fatalf("replay: ack=%+v err=%v", ack, err)
+	}
+	if len(pub.published) != 1 {
+		t.Fatalf("published %d times, want 1", len(pub.published))
+	}
+	_, err = svc.Send(ctx, &event.ScoreEvent{EventID: "e1", UserID: 1, NewScore: 650})
+	if statusOf(err) != http.StatusUnprocessableEntity {
+		t.Fatalf("reuse with another payload: %v", err)
+	}
+}
+
+func TestSendReleasesClaimWhenPublishFails(t *testing.T) {
+	store := &memoryStore{recs: map[string]repository.IdempotencyRecord{}}
+	pub := &publisher{err: errors.New("kafka down")}
+	svc := newTestService(store, pub)
+
+	if _, err := svc.Send(context.Background(), &event.ScoreEvent{EventID: "e1", UserID: 1}); err == nil {
+		t.Fatal("expected the publish error")
+	}
+	if _, ok := store.recs["e1"]; ok {
+		t.Fatal("claim was not released")
+	}
+	pub.err = nil
+	if ack, err := svc.Send(context.Background(), &event.ScoreEvent{EventID: "e1", UserID: 1}); err != nil || ack.Duplicate {
+		t.Fatalf("retry: ack=%+v err=%v", ack, err)
+	}
+}
+
+func TestSendSucceedsWhenCompleteFails(t *testing.T) {
+	store := &memoryStore{recs: map[string]repository.IdempotencyRecord{}, completeErr: errors.New("redis down")}
+	pub := &publisher{}
+	svc := newTestService(store, pub)
+
+	ack, err := svc.Send(context.Background(), &event.ScoreEvent{EventID: "e1", UserID: 1})
+	if err != nil || ack.Status != "ok" {
+		t.Fatalf("send: ack=%+v err=%v", ack, err)
+	}
+	// The claim must not be left pending, or retries would get 409 for the whole window.
+	if _, ok := store.recs["e1"]; ok {
+		t.Fatal("claim was left pending")
+	}
+
+	res, err := svc.SendBatch(context.Background(), []*event.ScoreEvent{{EventID: "e2", UserID: 1}})
+	if err != nil || res.Accepted != 1 {
+		t.Fatalf("batch: res=%+v err=%v", res, err)
+	}
+	if _, ok := store.recs["e2"]; ok {
+		t.Fatal("batch claim was left pending")
+	}
+}
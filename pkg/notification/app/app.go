@@ -4,29 +4,57 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net"
+	"time"
 
+	"github.com/emorenkov/scorehub/pkg/common/apikeys"
+	"github.com/emorenkov/scorehub/pkg/common/auth"
 	"github.com/emorenkov/scorehub/pkg/common/db"
+	"github.com/emorenkov/scorehub/pkg/common/dlq"
+	"github.com/emorenkov/scorehub/pkg/common/gateway"
+	"github.com/emorenkov/scorehub/pkg/common/grpcx"
+	"github.com/emorenkov/scorehub/pkg/common/health"
 	ckafka "github.com/emorenkov/scorehub/pkg/common/kafka"
 	logpkg "github.com/emorenkov/scorehub/pkg/common/logger"
+	"github.com/emorenkov/scorehub/pkg/common/ratelimit"
+	"github.com/emorenkov/scorehub/pkg/common/tlsx"
 	"github.com/emorenkov/scorehub/pkg/notification"
 	"github.com/emorenkov/scorehub/pkg/notification/config"
+	grpcserver "github.com/emorenkov/scorehub/pkg/notification/grpc"
+	"github.com/emorenkov/scorehub/pkg/notification/outbox"
 	"github.com/emorenkov/scorehub/pkg/notification/producer"
+	notificationpb "github.com/emorenkov/scorehub/pkg/notification/proto"
 	"github.com/emorenkov/scorehub/pkg/notification/repository"
 	"github.com/emorenkov/scorehub/pkg/notification/rest"
 	"github.com/emorenkov/scorehub/pkg/notification/service"
+	userpb "github.com/emorenkov/scorehub/pkg/user/models/proto"
+	"github.com/redis/go-redis/v9"
+	"github.com/segmentio/kafka-go"
 	"go.uber.org/zap"
 	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc"
 	"gorm.io/gorm"
 )
 
 type App struct {
-	cfg        *config.Config
-	db         *gorm.DB
-	restServer *rest.Server
-	consumer   *ckafka.Consumer
-	publisher  producer.Publisher
-	svc        service.Notification
-	cancel     context.CancelFunc
+	cfg          *config.Config
+	db           *gorm.DB
+	redis        *redis.Client
+	userConn     *grpc.ClientConn
+	repo         *repository.GormRepository
+	listener     *repository.Listener
+	restServer   *rest.Server
+	gateway      *gateway.Handler
+	grpcServer   *grpc.Server
+	grpcListener net.Listener
+	grpcHealth   *health.GRPCServer
+	consumer     *ckafka.Consumer
+	deadLetters  *dlq.Forwarder
+	dlqAdmin     *dlq.Admin
+	publisher    producer.Publisher
+	relay        *outbox.Relay
+	svc          service.Notification
+	cancel       context.CancelFunc
 }
 
 func New(cfg *config.Config) (*App, error) {
@@ -35,25 +63,142 @@ func New(cfg *config.Config) (*App, error) {
 		return nil, fmt.Errorf("init db: %w", err)
 	}
 
+	userTLS, err := tlsx.Client(cfg.UserServiceTLS)
+	if err != nil {
+		return nil, fmt.Errorf("init user service tls: %w", err)
+	}
+	userConn, err := grpc.Dial(cfg.UserServiceAddr, grpcx.DialOptions(grpcx.ClientConfig{
+		APIKey:  cfg.UserServiceAPIKey,
+		Timeout: cfg.GRPC.ClientTimeout,
+		TLS:     userTLS,
+	})...)
+	if err != nil {
+		return nil, fmt.Errorf("dial user service: %w", err)
+	}
+	users := repository.NewUserDirectory(userpb.NewUserServiceClient(userConn), cfg.UserCacheSize, cfg.UserCacheTTL, cfg.UserLookupTimeout)
+
+	sqlDB, err := dbConn.DB()
+	if err != nil {
+		return nil, fmt.Errorf("get sql db: %w", err)
+	}
+	listener := repository.NewListener(sqlDB)
+
 	repo := repository.NewGormRepository(dbConn)
 	pub := producer.NewKafkaPublisher(cfg.KafkaBrokers, cfg.NotificationsTopic)
-	svc := service.NewNotification(repo, pub)
+	svc := service.NewNotification(repo, repo, users, listener, cfg.NotificationsTopic)
+	rulesSvc := service.NewRules(repo)
+	relay := outbox.NewRelay(outbox.Config{
+		Topic:        cfg.NotificationsTopic,
+		PollInterval: cfg.OutboxPollInterval,
+		BatchSize:    cfg.OutboxBatchSize,
+		MaxAttempts:  cfg.OutboxMaxAttempts,
+	}, repo, pub, logpkg.Log)
+
+	checks := health.NewRegistry(0)
+	checks.Add("postgres", health.DB(dbConn))
+	checks.Add("kafka", health.Kafka(cfg.KafkaBrokers))
+	checks.Add("user_service", health.GRPCConn(userConn))
+
+	dlqAdmin := dlq.NewAdmin(cfg.KafkaBrokers)
+	grpcTLS, err := tlsx.Server(cfg.GRPC.TLS)
+	if err != nil {
+		return nil, fmt.Errorf("init grpc tls: %w", err)
+	}
+	gatewayTLS, err := tlsx.SelfClient(cfg.GRPC.TLS)
+	if err != nil {
+		return nil, fmt.Errorf("init gateway tls: %w", err)
+	}
+	httpTLS, err := tlsx.Server(cfg.HTTPTLS)
+	if err != nil {
+		return nil, fmt.Errorf("init http tls: %w", err)
+	}
+
+	var keys auth.KeyLookup
+	var keysHandler *apikeys.Handler
+	if cfg.APIKeys.Enabled {
+		cache, h := apikeys.New(dbConn, cfg.APIKeys)
+		keys, keysHandler = cache, h
+	}
+	authn, err := auth.FromConfig(context.Background(), cfg.APIKey, keys, cfg.Auth)
+	if err != nil {
+		return nil, fmt.Errorf("init auth: %w", err)
+	}
+
+	var redisClient *redis.Client
+	if cfg.RateLimit.Enabled && cfg.RedisConfig.RedisAddr != "" {
+		redisClient = redis.NewClient(&redis.Options{
+			Addr:     cfg.RedisConfig.RedisAddr,
+			Password: cfg.RedisConfig.RedisPassword,
+			DB:       cfg.RedisConfig.RedisDB,
+		})
+	}
+	limiter, err := ratelimit.New(cfg.RateLimit, redisClient)
+	if err != nil {
+		return nil, fmt.Errorf("init rate limiter: %w", err)
+	}
+
+	gw, err := gateway.New(gateway.Config{
+		GRPCAddr: "localhost:" + cfg.GRPCPort,
+		GRPCTLS:  gatewayTLS,
+		Options:  rest.GatewayOptions(),
+	}, notificationpb.RegisterNotificationServiceHandlerFromEndpoint)
+	if err != nil {
+		return nil, fmt.Errorf("init gateway: %w", err)
+	}
+	restServer := rest.NewServer(rest.Deps{
+		Config:      cfg,
+		Gateway:     gw,
+		Service:     svc,
+		Rules:       rulesSvc,
+		DeadLetters: dlq.NewHandler(dlqAdmin, []string{cfg.ScoreEventsTopic}),
+		Keys:        keysHandler,
+		Authn:       authn,
+		Limiter:     limiter,
+		Checks:      checks,
+		TLS:         httpTLS,
+		Log:         logpkg.Log,
+	})
+
+	grpcSrv := grpcx.NewServer(grpcx.ServerConfig{
+		Auth:           grpcx.Auth(authn),
+		Scopes:         grpcserver.MethodScopes,
+		RateLimit:      limiter,
+		DefaultTimeout: cfg.GRPC.DefaultTimeout,
+		TLS:            grpcTLS,
+	})
+	notificationpb.RegisterNotificationServiceServer(grpcSrv, grpcserver.NewServer(svc))
+	grpcHealth := health.NewGRPCServer(grpcSrv, checks, notificationpb.NotificationService_ServiceDesc.ServiceName)
+	grpcAddr := ":" + cfg.GRPCPort
+	lis, err := net.Listen("tcp", grpcAddr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", grpcAddr, err)
+	}
 
-	restServer := rest.NewServer(cfg, svc, logpkg.Log)
 	consumer := ckafka.NewConsumerWithBrokers(cfg.KafkaBrokers, cfg.ScoreEventsTopic, cfg.KafkaGroupID)
 
 	return &App{
-		cfg:        cfg,
-		db:         dbConn,
-		restServer: restServer,
-		consumer:   consumer,
-		publisher:  pub,
-		svc:        svc,
+		cfg:          cfg,
+		db:           dbConn,
+		redis:        redisClient,
+		userConn:     userConn,
+		repo:         repo,
+		listener:     listener,
+		restServer:   restServer,
+		gateway:      gw,
+		grpcServer:   grpcSrv,
+		grpcListener: lis,
+		grpcHealth:   grpcHealth,
+		consumer:     consumer,
+		deadLetters:  dlq.NewForwarder(cfg.KafkaBrokers, cfg.KafkaGroupID),
+		dlqAdmin:     dlqAdmin,
+		publisher:    pub,
+		relay:        relay,
+		svc:          svc,
 	}, nil
 }
 
 func (a *App) Run() <-chan error {
-	errCh := make(chan error, 2)
+	errCh := make(chan error, 5)
 	ctx, cancel := context.WithCancel(context.Background())
 	a.cancel = cancel
 
@@ -64,45 +209,115 @@ func (a *App) Run() <-chan error {
 	}()
 
 	go func() {
-		logpkg.Log.Info("starting score events consumer", zap.String("topic", a.cfg.ScoreEventsTopic))
-		for {
-			msg, err := a.consumer.ReadMessage(ctx)
-			if err != nil {
-				if ctx.Err() != nil {
-					return
-				}
-				errCh <- fmt.Errorf("consume score event: %w", err)
-				return
-			}
-			var ev notification.ScoreEvent
-			if err := json.Unmarshal(msg.Value, &ev); err != nil {
-				logpkg.Log.Error("failed to unmarshal score event", zap.Error(err))
-				continue
-			}
-			if _, err := a.svc.ProcessScoreEvent(ctx, &ev); err != nil {
-				logpkg.Log.Error("process score event failed", zap.Error(err))
-			}
+		logpkg.FromContext(ctx).Info("starting gRPC server", zap.String("addr", ":"+a.cfg.GRPCPort))
+		if err := a.grpcServer.Serve(a.grpcListener); err != nil {
+			errCh <- fmt.Errorf("grpc server: %w", err)
+		}
+	}()
+
+	go func() {
+		if err := a.relay.Run(ctx); err != nil {
+			errCh <- fmt.Errorf("outbox relay: %w", err)
+		}
+	}()
+
+	go func() {
+		if err := a.listener.Run(ctx); err != nil {
+			errCh <- fmt.Errorf("notification listener: %w", err)
+		}
+	}()
+
+	go a.purgeProcessedEvents(ctx)
+	go a.grpcHealth.Run(ctx, 0)
+
+	go func() {
+		logpkg.FromContext(ctx).Info("starting score events consumer", zap.String("topic", a.cfg.ScoreEventsTopic))
+		err := a.consumer.Run(ctx, a.handleScoreEvent, ckafka.RunOptions{
+			Retry:     ckafka.RetryPolicyFromConfig(a.cfg.ConsumerRetry),
+			OnFailure: a.deadLetters.FailureHandler(),
+		})
+		if err != nil {
+			errCh <- fmt.Errorf("consume score event: %w", err)
 		}
 	}()
 
 	return errCh
 }
 
+func (a *App) handleScoreEvent(ctx context.Context, msg kafka.Message) error {
+	var ev notification.ScoreEvent
+	if err := json.Unmarshal(msg.Value, &ev); err != nil {
+		return ckafka.Permanent(fmt.Errorf("unmarshal score event: %w", err))
+	}
+	_, err := a.svc.ProcessScoreEvent(ctx, &ev)
+	return err
+}
+
+// purgeProcessedEvents periodically drops event IDs older than the retention window;
+// redeliveries older than that are no longer deduplicated.
+func (a *App) purgeProcessedEvents(ctx context.Context) {
+	if a.cfg.ProcessedEventsRetention <= 0 {
+		return
+	}
+	log := logpkg.FromContext(ctx)
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+	for {
+		n, err := a.repo.PurgeProcessedEvents(ctx, time.Now().Add(-a.cfg.ProcessedEventsRetention))
+		if err != nil && ctx.Err() == nil {
+			log.Error("purge processed events failed", zap.Error(err))
+		} else if n > 0 {
+			log.Info("purged processed events", zap.Int64("count", n))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
 func (a *App) Shutdown(ctx context.Context) error {
 	if a.cancel != nil {
 		a.cancel()
 	}
+	a.grpcHealth.Shutdown()
 
 	g, ctx := errgroup.WithContext(ctx)
 
 	g.Go(func() error {
+		defer a.gateway.Close()
 		return a.restServer.Shutdown(ctx)
 	})
 
+	g.Go(func() error {
+		done := make(chan struct{})
+		go func() {
+			a.grpcServer.GracefulStop()
+			close(done)
+		}()
+
+		select {
+		case <-done:
+			return nil
+		case <-ctx.Done():
+			a.grpcServer.Stop()
+			return ctx.Err()
+		}
+	})
+
 	g.Go(func() error {
 		return a.consumer.Close()
 	})
 
+	g.Go(func() error {
+		return a.deadLetters.Close()
+	})
+
+	g.Go(func() error {
+		return a.dlqAdmin.Close()
+	})
+
 	g.Go(func() error {
 		if a.publisher != nil {
 			return a.publisher.Close()
@@ -110,6 +325,17 @@ func (a *App) Shutdown(ctx context.Context) error {
 		return nil
 	})
 
+	g.Go(func() error {
+		return a.userConn.Close()
+	})
+
+	g.Go(func() error {
+		if a.redis != nil {
+			return a.redis.Close()
+		}
+		return nil
+	})
+
 	g.Go(func() error {
 		sqlDB, err := a.db.DB()
 		if err != nil {
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"strings"
+	"time"
 
 	"github.com/emorenkov/scorehub/pkg/common/models"
 )
@@ -10,24 +11,70 @@ import (
 type Config struct {
 	ServiceName        string
 	HTTPPort           string
+	GRPCPort           string
 	APIKey             string
 	KafkaBrokers       []string
 	KafkaGroupID       string
 	ScoreEventsTopic   string
 	NotificationsTopic string
-	DbConfig           *models.PostgresConfig
+	ConsumerRetry      *models.ConsumerRetryConfig
+	// Outbox relay tuning
+	OutboxPollInterval time.Duration
+	OutboxBatchSize    int
+	OutboxMaxAttempts  int
+	// UserServiceAddr is where recipients' names, emails and preferences are looked up.
+	UserServiceAddr string
+	// UserServiceAPIKey is sent as x-api-key on calls to user-service.
+	UserServiceAPIKey string
+	// UserServiceTLS secures the connection to user-service; a certificate enables mutual TLS.
+	UserServiceTLS *models.TLSConfig
+	// UserCacheTTL and UserCacheSize bound the recipient cache; UserLookupTimeout bounds each call.
+	UserCacheTTL      time.Duration
+	UserCacheSize     int
+	UserLookupTimeout time.Duration
+	// ProcessedEventsRetention bounds how long consumed event IDs are kept for deduplication.
+	ProcessedEventsRetention time.Duration
+	DbConfig                 *models.PostgresConfig
+	// RedisConfig shares rate limit counters between replicas.
+	RedisConfig *models.RedisConfig
+	RateLimit   *models.RateLimitConfig
+	Tracing     *models.TracingConfig
+	// HTTPTLS serves the REST API over HTTPS when a certificate is set.
+	HTTPTLS *models.TLSConfig
+	Auth    *models.AuthConfig
+	APIKeys *models.APIKeysConfig
+	GRPC    *models.GRPCConfig
 }
 
 func Load() *Config {
 	return &Config{
-		ServiceName:        getEnv("SERVICE_NAME", "notification-service"),
-		HTTPPort:           getEnv("HTTP_PORT", "8083"),
-		APIKey:             getEnv("API_KEY", ""),
-		KafkaBrokers:       splitAndTrim(getEnv("KAFKA_BROKERS", "localhost:9092")),
-		KafkaGroupID:       getEnv("KAFKA_GROUP_ID", "scorehub-group"),
-		ScoreEventsTopic:   getEnv("SCORE_EVENTS_TOPIC", "score_events"),
-		NotificationsTopic: getEnv("NOTIFICATIONS_TOPIC", "notifications"),
-		DbConfig:           models.LoadPostgresConfig(),
+		ServiceName:              getEnv("SERVICE_NAME", "notification-service"),
+		HTTPPort:                 getEnv("HTTP_PORT", "8083"),
+		GRPCPort:                 getEnv("GRPC_PORT", "50053"),
+		APIKey:                   getEnv("API_KEY", ""),
+		KafkaBrokers:             splitAndTrim(getEnv("KAFKA_BROKERS", "localhost:9092")),
+		KafkaGroupID:             getEnv("KAFKA_GROUP_ID", "scorehub-group"),
+		ScoreEventsTopic:         getEnv("SCORE_EVENTS_TOPIC", "score_events"),
+		NotificationsTopic:       getEnv("NOTIFICATIONS_TOPIC", "notifications"),
+		ConsumerRetry:            models.LoadConsumerRetryConfig(),
+		OutboxPollInterval:       time.Duration(models.GetEnvAsInt("OUTBOX_POLL_INTERVAL_MS", 1000)) * time.Millisecond,
+		OutboxBatchSize:          models.GetEnvAsInt("OUTBOX_BATCH_SIZE", 100),
+		OutboxMaxAttempts:        models.GetEnvAsInt("OUTBOX_MAX_ATTEMPTS", 10),
+		UserServiceAddr:          getEnv("USER_SERVICE_ADDR", "localhost:50051"),
+		UserServiceAPIKey:        getEnv("USER_SERVICE_API_KEY", ""),
+		UserServiceTLS:           models.LoadTLSConfig("USER_SERVICE_"),
+		UserCacheTTL:             time.Duration(models.GetEnvAsInt("USER_CACHE_TTL_SECONDS", 300)) * time.Second,
+		UserCacheSize:            models.GetEnvAsInt("USER_CACHE_SIZE", 10000),
+		UserLookupTimeout:        time.Duration(models.GetEnvAsInt("USER_LOOKUP_TIMEOUT_MS", 2000)) * time.Millisecond,
+		ProcessedEventsRetention: time.Duration(models.GetEnvAsInt("PROCESSED_EVENTS_RETENTION_HOURS", 168)) * time.Hour,
+		DbConfig:                 models.LoadPostgresConfig(),
+		RedisConfig:              models.LoadRedisConfig(),
+		RateLimit:                models.LoadRateLimitConfig(),
+		Tracing:                  models.LoadTracingConfig(),
+		HTTPTLS:                  models.LoadTLSConfig("HTTP_"),
+		Auth:                     models.LoadAuthConfig(),
+		APIKeys:                  models.LoadAPIKeysConfig(),
+		GRPC:                     models.LoadGRPCConfig(),
 	}
 }
 
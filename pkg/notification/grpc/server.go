@@ -0,0 +1,212 @@
+package grpcserver
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/emorenkov/scorehub/pkg/common/auth"
+	apperrors "github.com/emorenkov/scorehub/pkg/common/errors"
+	logpkg "github.com/emorenkov/scorehub/pkg/common/logger"
+	"github.com/emorenkov/scorehub/pkg/notification"
+	notificationpb "github.com/emorenkov/scorehub/pkg/notification/proto"
+	"github.com/emorenkov/scorehub/pkg/notification/service"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Server implements the generated gRPC interface and forwards calls to the domain service.
+type Server struct {
+	notificationpb.UnimplementedNotificationServiceServer
+	svc service.Notification
+}
+
+func NewServer(svc service.Notification) *Server {
+	return &Server{svc: svc}
+}
+
+// MethodScopes lists the scopes each RPC requires, for grpcx.ServerConfig.Scopes.
+var MethodScopes = map[string][]string{
+	"/notification.NotificationService/CreateNotification": {auth.ScopeNotificationsWrite},
+	"/notification.NotificationService/GetNotification":    {auth.ScopeNotificationsRead},
+	"/notification.NotificationService/ListNotifications":  {auth.ScopeNotificationsRead},
+	// The inbox calls change only read and archive state, and the service limits end users
+	// to their own notifications, so they need the read scope as over REST. The write
+	// scope creates notifications for any user and is not granted to end-user tokens.
+	"/notification.NotificationService/MarkNotificationRead":   {auth.ScopeNotificationsRead},
+	"/notification.NotificationService/UpdateNotification":     {auth.ScopeNotificationsRead},
+	"/notification.NotificationService/MarkAllRead":            {auth.ScopeNotificationsRead},
+	"/notification.NotificationService/GetUnreadCount":         {auth.ScopeNotificationsRead},
+	"/notification.NotificationService/SubscribeNotifications": {auth.ScopeNotificationsRead},
+}
+
+func (s *Server) CreateNotification(ctx context.Context, req *notificationpb.CreateNotificationRequest) (*notificationpb.Notification, error) {
+	log := logpkg.FromContext(ctx)
+	n, err := s.svc.Create(ctx, req.GetUserId(), req.GetMessage())
+	if err != nil {
+		log.Error("grpc CreateNotification failed", zap.Error(err), zap.Int64("user_id", req.GetUserId()))
+		return nil, mapError(err)
+	}
+	log.Info("grpc CreateNotification succeeded", zap.Int64("notification_id", n.ID), zap.Int64("user_id", n.UserID))
+	return toProtoNotification(n), nil
+}
+
+func (s *Server) GetNotification(ctx context.Context, req *notificationpb.GetNotificationRequest) (*notificationpb.Notification, error) {
+	log := logpkg.FromContext(ctx)
+	n, err := s.svc.Get(ctx, req.GetId())
+	if err != nil {
+		log.Error("grpc GetNotification failed", zap.Error(err), zap.Int64("notification_id", req.GetId()))
+		return nil, mapError(err)
+	}
+	log.Info("grpc GetNotification succeeded", zap.Int64("notification_id", n.ID))
+	return toProtoNotification(n), nil
+}
+
+func (s *Server) ListNotifications(ctx context.Context, req *notificationpb.ListNotificationsRequest) (*notificationpb.ListNotificationsResponse, error) {
+	log := logpkg.FromContext(ctx)
+	since, err := parseTime(req.GetSince())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid since: %v", err)
+	}
+	until, err := parseTime(req.GetUntil())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid until: %v", err)
+	}
+	notifications, next, err := s.svc.List(ctx, notification.ListQuery{
+		UserID:   req.GetUserId(),
+		Status:   req.GetStatus(),
+		Type:     req.GetType(),
+		Priority: req.GetPriority(),
+		Since:    since,
+		Until:    until,
+		PageSize: int(req.GetPageSize()),
+		Cursor:   req.GetCursor(),
+	})
+	if err != nil {
+		log.Error("grpc ListNotifications failed", zap.Error(err), zap.Int64("user_id", req.GetUserId()))
+		return nil, mapError(err)
+	}
+	resp := &notificationpb.ListNotificationsResponse{
+		Notifications: make([]*notificationpb.Notification, 0, len(notifications)),
+		NextCursor:    next,
+	}
+	for i := range notifications {
+		resp.Notifications = append(resp.Notifications, toProtoNotification(&notifications[i]))
+	}
+	log.Info("grpc ListNotifications succeeded", zap.Int("count", len(resp.Notifications)), zap.Int64("user_id", req.GetUserId()))
+	return resp, nil
+}
+
+func (s *Server) MarkNotificationRead(ctx context.Context, req *notificationpb.MarkNotificationReadRequest) (*notificationpb.Notification, error) {
+	log := logpkg.FromContext(ctx)
+	read := !req.GetUnread()
+	n, err := s.svc.Update(ctx, req.GetId(), &read, nil)
+	if err != nil {
+		log.Error("grpc MarkNotificationRead failed", zap.Error(err), zap.Int64("notification_id", req.GetId()))
+		return nil, mapError(err)
+	}
+	log.Info("grpc MarkNotificationRead succeeded", zap.Int64("notification_id", n.ID), zap.Bool("read", read))
+	return toProtoNotification(n), nil
+}
+
+func (s *Server) UpdateNotification(ctx context.Context, req *notificationpb.UpdateNotificationRequest) (*notificationpb.Notification, error) {
+	log := logpkg.FromContext(ctx)
+	n, err := s.svc.Update(ctx, req.GetId(), req.Read, req.Archived)
+	if err != nil {
+		log.Error("grpc UpdateNotification failed", zap.Error(err), zap.Int64("notification_id", req.GetId()))
+		return nil, mapError(err)
+	}
+	log.Info("grpc UpdateNotification succeeded", zap.Int64("notification_id", n.ID))
+	return toProtoNotification(n), nil
+}
+
+func (s *Server) MarkAllRead(ctx context.Context, req *notificationpb.MarkAllReadRequest) (*notificationpb.MarkAllReadResponse, error) {
+	log := logpkg.FromContext(ctx)
+	updated, err := s.svc.MarkAllRead(ctx, req.GetUserId())
+	if err != nil {
+		log.Error("grpc MarkAllRead failed", zap.Error(err), zap.Int64("user_id", req.GetUserId()))
+		return nil, mapError(err)
+	}
+	log.Info("grpc MarkAllRead succeeded", zap.Int64("user_id", req.GetUserId()), zap.Int64("updated", updated))
+	return &notificationpb.MarkAllReadResponse{Updated: updated}, nil
+}
+
+func (s *Server) GetUnreadCount(ctx context.Context, req *notificationpb.GetUnreadCountRequest) (*notificationpb.GetUnreadCountResponse, error) {
+	log := logpkg.FromContext(ctx)
+	count, err := s.svc.UnreadCount(ctx, req.GetUserId())
+	if err != nil {
+		log.Error("grpc GetUnreadCount failed", zap.Error(err), zap.Int64("user_id", req.GetUserId()))
+		return nil, mapError(err)
+	}
+	return &notificationpb.GetUnreadCountResponse{UserId: req.GetUserId(), Unread: count}, nil
+}
+
+func (s *Server) SubscribeNotifications(req *notificationpb.SubscribeNotificationsRequest, stream notificationpb.NotificationService_SubscribeNotificationsServer) error {
+	ctx := stream.Context()
+	log := logpkg.FromContext(ctx)
+	events, err := s.svc.Subscribe(ctx, req.GetUserId(), req.GetLastEventId())
+	if err != nil {
+		log.Error("grpc SubscribeNotifications failed", zap.Error(err), zap.Int64("user_id", req.GetUserId()))
+		return mapError(err)
+	}
+	log.Info("grpc SubscribeNotifications started", zap.Int64("user_id", req.GetUserId()), zap.Int64("last_event_id", req.GetLastEventId()))
+	for n := range events {
+		if err := stream.Send(toProtoNotification(&n)); err != nil {
+			return err
+		}
+	}
+	if ctx.Err() != nil {
+		return nil
+	}
+	// The service stopped streaming, e.g. on shutdown; clients resume elsewhere.
+	return status.Error(codes.Unavailable, "notification stream closed")
+}
+
+func toProtoNotification(n *notification.Notification) *notificationpb.Notification {
+	return &notificationpb.Notification{
+		Id:         n.ID,
+		UserId:     n.UserID,
+		Message:    n.Message,
+		Channel:    n.Channel,
+		Priority:   n.Priority,
+		Type:       n.Type,
+		CreatedAt:  n.CreatedAt.UTC().Format(time.RFC3339),
+		ReadAt:     formatTime(n.ReadAt),
+		ArchivedAt: formatTime(n.ArchivedAt),
+	}
+}
+
+func parseTime(v string) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, v)
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func mapError(err error) error {
+	if se, ok := apperrors.AsStatusError(err); ok {
+		switch se.Status {
+		case http.StatusBadRequest:
+			return status.Error(codes.InvalidArgument, se.Message)
+		case http.StatusUnauthorized:
+			return status.Error(codes.Unauthenticated, se.Message)
+		case http.StatusForbidden:
+			return status.Error(codes.PermissionDenied, se.Message)
+		case http.StatusNotFound:
+			return status.Error(codes.NotFound, se.Message)
+		case http.StatusServiceUnavailable:
+			return status.Error(codes.Unavailable, se.Message)
+		default:
+			return status.Error(codes.Internal, se.Error())
+		}
+	}
+	return status.Error(codes.Internal, err.Error())
+}
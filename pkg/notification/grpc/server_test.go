@@ -0,0 +1,295 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	apperrors "github.com/emorenkov/scorehub/pkg/common/errors"
+	"github.com/emorenkov/scorehub/pkg/common/grpcx"
+	"github.com/emorenkov/scorehub/pkg/notification"
+	notificationpb "github.com/emorenkov/scorehub/pkg/notification/proto"
+	"github.com/emorenkov/scorehub/pkg/notification/service"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+func TestMethodScopesCoverEveryRPC(t *testing.T) {
+	grpcx.CheckScopes(t, notificationpb.NotificationService_ServiceDesc, MethodScopes)
+}
+
+// notificationService answers every call with n, or err when set, and records the
+// arguments it was called with.
+type notificationService struct {
+	service.Notification
+	n   notification.Notification
+	err error
+
+	query          notification.ListQuery
+	read, archived *bool
+	stream         chan notification.Notification
+	subscribed     chan context.Context
+}
+
+func (s *notificationService) result() (*notification.Notification, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	n := s.n
+	return &n, nil
+}
+
+func (s *notificationService) Create(context.Context, int64, string) (*notification.Notification, error) {
+	return s.result()
+}
+
+func (s *notificationService) Get(context.Context, int64) (*notification.Notification, error) {
+	return s.result()
+}
+
+func (s *notificationService) List(_ context.Context, q notification.ListQuery) ([]notification.Notification, string, error) {
+	s.query = q
+	if s.err != nil {
+		return nil, "", s.err
+	}
+	return []notification.Notification{s.n}, "next", nil
+}
+
+func (s *notificationService) Update(_ context.Context, _ int64, read, archived *bool) (*notification.Notification, error) {
+	s.read, s.archived = read, archived
+	return s.result()
+}
+
+func (s *notificationService) MarkAllRead(context.Context, int64) (int64, error) {
+	return 3, s.err
+}
+
+func (s *notificationService) UnreadCount(context.Context, int64) (int64, error) {
+	return 2, s.err
+}
+
+func (s *notificationService) Subscribe(ctx context.Context, _, _ int64) (<-chan notification.Notification, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	s.subscribed <- ctx
+	return s.stream, nil
+}
+
+// dial serves svc on a local port and returns a client for it.
+func dial(t *testing.T, svc *notificationService) notificationpb.NotificationServiceClient {
+	t.Helper()
+	gs := grpc.NewServer()
+	notificationpb.RegisterNotificationServiceServer(gs, NewServer(svc))
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	go func() { _ = gs.Serve(lis) }()
+	t.Cleanup(gs.Stop)
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return notificationpb.NewNotificationServiceClient(conn)
+}
+
+func TestMapError(t *testing.T) {
+	for name, tc := range map[string]struct {
+		err  error
+		want codes.Code
+	}{
+		"bad request":      {apperrors.NewStatusError(http.StatusBadRequest, "bad"), codes.InvalidArgument},
+		"unauthenticated":  {apperrors.NewStatusError(http.StatusUnauthorized, "who"), codes.Unauthenticated},
+		"forbidden":        {apperrors.NewStatusError(http.StatusForbidden, "no"), codes.PermissionDenied},
+		"not found":        {apperrors.NewStatusError(http.StatusNotFound, "gone"), codes.NotFound},
+		"unavailable":      {apperrors.NewStatusError(http.StatusServiceUnavailable, "down"), codes.Unavailable},
+		"internal":         {apperrors.WrapStatus(errors.New("db"), http.StatusInternalServerError, "list"), codes.Internal},
+		"other status":     {apperrors.NewStatusError(http.StatusUnprocessableEntity, "odd"), codes.Internal},
+		"without a status": {errors.New("boom"), codes.Internal},
+	} {
+		if got := status.Code(mapError(tc.err)); got != tc.want {
+			t.Errorf("%s: code %v, want %v", name, got, tc.want)
+		}
+	}
+}
+
+// TestRPCsMapServiceErrors checks that every RPC returns the service's error as a status
+// with its message.
+func TestRPCsMapServiceErrors(t *testing.T) {
+	svc := &notificationService{err: apperrors.NewStatusError(http.StatusNotFound, "notification not found")}
+	client := dial(t, svc)
+	ctx := context.Background()
+
+	for name, call := range map[string]func() error{
+		"CreateNotification": func() error {
+			_, err := client.CreateNotification(ctx, &notificationpb.CreateNotificationRequest{UserId: 7, Message: "hi"})
+			return err
+		},
+		"GetNotification": func() error {
+			_, err := client.GetNotification(ctx, &notificationpb.GetNotificationRequest{Id: 1})
+			return err
+		},
+		"ListNotifications": func() error {
+			_, err := client.ListNotifications(ctx, &notificationpb.ListNotificationsRequest{UserId: 7})
+			return err
+		},
+		"MarkNotificationRead": func() error {
+			_, err := client.MarkNotificationRead(ctx, &notificationpb.MarkNotificationReadRequest{Id: 1})
+			return err
+		},
+		"UpdateNotification": func() error {
+			_, err := client.UpdateNotification(ctx, &notificationpb.UpdateNotificationRequest{Id: 1})
+			return err
+		},
+		"MarkAllRead": func() error {
+			_, err := client.MarkAllRead(ctx, &notificationpb.MarkAllReadRequest{UserId: 7})
+			return err
+		},
+		"GetUnreadCount": func() error {
+			_, err := client.GetUnreadCount(ctx, &notificationpb.GetUnreadCountRequest{UserId: 7})
+			return err
+		},
+		"SubscribeNotifications": func() error {
+			stream, err := client.SubscribeNotifications(ctx, &notificationpb.SubscribeNotificationsRequest{UserId: 7})
+			if err != nil {
+				return err
+			}
+			_, err = stream.Recv()
+			return err
+		},
+	} {
+		err := call()
+		if st, _ := status.FromError(err); st.Code() != codes.NotFound || st.Message() != "notification not found" {
+			t.Errorf("%s: got %v, want NotFound", name, err)
+		}
+	}
+}
+
+func TestListNotifications(t *testing.T) {
+	created := time.Date(2026, 3, 1, 12, 0, 0, 0, time.FixedZone("CET", 3600))
+	svc := &notificationService{n: notification.Notification{ID: 5, UserID: 7, Message: "hi", Priority: "high", CreatedAt: created}}
+	client := dial(t, svc)
+
+	resp, err := client.ListNotifications(context.Background(), &notificationpb.ListNotificationsRequest{
+		UserId: 7, Status: "unread", PageSize: 20, Cursor: "c", Type: "manual", Priority: "high",
+		Since: "2026-03-01T00:00:00Z", Until: "2026-03-02T00:00:00Z",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := svc.query
+	if q.UserID != 7 || q.Status != "unread" || q.PageSize != 20 || q.Cursor != "c" || q.Type != "manual" || q.Priority != "high" ||
+		!q.Since.Equal(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)) || !q.Until.Equal(time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("query %+v", q)
+	}
+	if resp.GetNextCursor() != "next" || len(resp.GetNotifications()) != 1 {
+		t.Fatalf("response %v", resp)
+	}
+	got := resp.GetNotifications()[0]
+	if got.GetId() != 5 || got.GetCreatedAt() != "2026-03-01T11:00:00Z" || got.GetReadAt() != "" || got.GetArchivedAt() != "" {
+		t.Fatalf("notification %v", got)
+	}
+
+	for _, req := range []*notificationpb.ListNotificationsRequest{{Since: "yesterday"}, {Until: "2026-03-02"}} {
+		svc.query = notification.ListQuery{}
+		_, err := client.ListNotifications(context.Background(), req)
+		if status.Code(err) != codes.InvalidArgument {
+			t.Fatalf("%v: got %v, want InvalidArgument", req, err)
+		}
+		if svc.query != (notification.ListQuery{}) {
+			t.Fatal("listed with an invalid time")
+		}
+	}
+}
+
+func TestReadStateRPCs(t *testing.T) {
+	svc := &notificationService{n: notification.Notification{ID: 1, UserID: 7}}
+	client := dial(t, svc)
+	ctx := context.Background()
+
+	if _, err := client.MarkNotificationRead(ctx, &notificationpb.MarkNotificationReadRequest{Id: 1, Unread: true}); err != nil {
+		t.Fatal(err)
+	}
+	if svc.read == nil || *svc.read || svc.archived != nil {
+		t.Fatalf("MarkNotificationRead unread: read=%v archived=%v", svc.read, svc.archived)
+	}
+	archived := true
+	if _, err := client.UpdateNotification(ctx, &notificationpb.UpdateNotificationRequest{Id: 1, Archived: &archived}); err != nil {
+		t.Fatal(err)
+	}
+	if svc.read != nil || svc.archived == nil || !*svc.archived {
+		t.Fatalf("UpdateNotification archived: read=%v archived=%v", svc.read, svc.archived)
+	}
+	if resp, err := client.MarkAllRead(ctx, &notificationpb.MarkAllReadRequest{UserId: 7}); err != nil || resp.GetUpdated() != 3 {
+		t.Fatalf("MarkAllRead: %v, %v", resp, err)
+	}
+	if resp, err := client.GetUnreadCount(ctx, &notificationpb.GetUnreadCountRequest{UserId: 7}); err != nil || resp.GetUnread() != 2 || resp.GetUserId() != 7 {
+		t.Fatalf("GetUnreadCount: %v, %v", resp, err)
+	}
+}
+
+func newStreamingService() *notificationService {
+	return &notificationService{stream: make(chan notification.Notification), subscribed: make(chan context.Context, 1)}
+}
+
+// subscribe opens a stream and waits until the service saw it, returning the server-side
+// context of the call.
+func subscribe(t *testing.T, ctx context.Context, client notificationpb.NotificationServiceClient, svc *notificationService) (notificationpb.NotificationService_SubscribeNotificationsClient, context.Context) {
+	t.Helper()
+	stream, err := client.SubscribeNotifications(ctx, &notificationpb.SubscribeNotificationsRequest{UserId: 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case serverCtx := <-svc.subscribed:
+		return stream, serverCtx
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscription did not reach the service")
+		return nil, nil
+	}
+}
+
+func TestSubscribeEndsWithUnavailableWhenTheServiceStops(t *testing.T) {
+	svc := newStreamingService()
+	client := dial(t, svc)
+	stream, _ := subscribe(t, context.Background(), client, svc)
+
+	for _, id := range []int64{8, 6} {
+		svc.stream <- notification.Notification{ID: id, UserID: 7}
+		n, err := stream.Recv()
+		if err != nil || n.GetId() != id {
+			t.Fatalf("got %v, %v, want notification %d", n, err, id)
+		}
+	}
+	close(svc.stream)
+	if _, err := stream.Recv(); status.Code(err) != codes.Unavailable {
+		t.Fatalf("got %v, want Unavailable so the client resumes elsewhere", err)
+	}
+}
+
+func TestSubscribeEndsWhenTheClientCancels(t *testing.T) {
+	svc := newStreamingService()
+	client := dial(t, svc)
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, serverCtx := subscribe(t, ctx, client, svc)
+
+	cancel()
+	select {
+	case <-serverCtx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("the service's subscription was not cancelled")
+	}
+	// The service closes its channel once its context ends, as the real one does.
+	close(svc.stream)
+	if _, err := stream.Recv(); status.Code(err) != codes.Canceled {
+		t.Fatalf("got %v, want Canceled", err)
+	}
+}
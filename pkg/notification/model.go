@@ -4,22 +4,161 @@ import "time"
 
 // Notification represents a notification persisted in Postgres.
 type Notification struct {
-	ID        int64     `gorm:"primaryKey;autoIncrement"`
-	UserID    int64     `gorm:"index;not null"`
-	Message   string    `gorm:"type:text;not null"`
+	ID       int64  `gorm:"primaryKey;autoIncrement"`
+	UserID   int64  `gorm:"index;not null"`
+	Message  string `gorm:"type:text;not null"`
+	Channel  string `gorm:"size:32;not null;default:in_app"`
+	Priority string `gorm:"size:16;not null;default:normal"`
+	// Type is the score event type that triggered the notification, or TypeManual.
+	Type      string `gorm:"type:text;not null;default:manual"`
+	RuleID    *int64
 	CreatedAt time.Time `gorm:"autoCreateTime"`
+	// ReadAt and ArchivedAt are set when the user reads or archives the notification.
+	ReadAt     *time.Time
+	ArchivedAt *time.Time
+	// StreamSeq is the notification's position among its user's notifications in commit
+	// order; streams resume by it.
+	StreamSeq int64
+}
+
+// TypeManual is the type of notifications created through the API rather than by a score event.
+const TypeManual = "manual"
+
+// Statuses accepted by ListQuery.Status.
+const (
+	// StatusAll lists every notification; it is the default.
+	StatusAll = "all"
+	// StatusInbox lists notifications that are not archived.
+	StatusInbox    = "inbox"
+	StatusUnread   = "unread"
+	StatusRead     = "read"
+	StatusArchived = "archived"
+)
+
+// ValidStatus reports whether s is a known list status; empty means StatusAll.
+func ValidStatus(s string) bool {
+	switch s {
+	case "", StatusAll, StatusInbox, StatusUnread, StatusRead, StatusArchived:
+		return true
+	}
+	return false
+}
+
+// ListQuery carries pagination and filters for listing notifications, newest first.
+// Unread and read notifications are the ones not archived.
+type ListQuery struct {
+	// UserID restricts the listing to one user; zero lists every user's notifications.
+	UserID   int64
+	Status   string
+	Type     string
+	Priority string
+	// Since (inclusive) and Until (exclusive) bound created_at.
+	Since    time.Time
+	Until    time.Time
+	PageSize int
+	Cursor   string
+}
+
+// ListCursor is the keyset position of the last notification returned on a page.
+type ListCursor struct {
+	CreatedAt time.Time `json:"created_at"`
+	ID        int64     `json:"id"`
+}
+
+// ListFilter is the repository-level form of ListQuery with a decoded cursor.
+type ListFilter struct {
+	UserID   int64
+	Status   string
+	Type     string
+	Priority string
+	Since    time.Time
+	Until    time.Time
+	After    *ListCursor
+	Limit    int
 }
 
 // ScoreEvent is the incoming event payload from Kafka.
 type ScoreEvent struct {
-	UserID   int64 `json:"user_id"`
-	NewScore int64 `json:"new_score"`
-	Change   int32 `json:"change"`
+	UserID   int64  `json:"user_id"`
+	NewScore int64  `json:"new_score"`
+	Change   int32  `json:"change"`
+	Type     string `json:"type,omitempty"`
+	EventID  string `json:"event_id,omitempty"`
+}
+
+// EventTypeScoreUpdate is the type of score events published without an explicit one.
+const EventTypeScoreUpdate = "score_update"
+
+// OldScore returns the score before the event was applied.
+func (e *ScoreEvent) OldScore() int64 {
+	return e.NewScore - int64(e.Change)
+}
+
+// EventType returns the event type, defaulting to EventTypeScoreUpdate.
+func (e *ScoreEvent) EventType() string {
+	if e.Type == "" {
+		return EventTypeScoreUpdate
+	}
+	return e.Type
 }
 
 // NotificationMessage is emitted to Kafka for downstream consumers (e.g., email).
 type NotificationMessage struct {
-	UserID    int64     `json:"user_id"`
-	Message   string    `json:"message"`
+	UserID   int64  `json:"user_id"`
+	Message  string `json:"message"`
+	Channel  string `json:"channel,omitempty"`
+	Priority string `json:"priority,omitempty"`
+	// Email is the recipient's address as known to user-service when the message was created.
+	Email     string    `json:"email,omitempty"`
 	CreatedAt time.Time `json:"created_at"`
 }
+
+// Recipient is the user a notification is addressed to, as resolved from user-service.
+type Recipient struct {
+	UserID      int64
+	Name        string
+	Email       string
+	EmailOptOut bool
+}
+
+// Outbox statuses.
+const (
+	OutboxPending = "pending"
+	OutboxSent    = "sent"
+	OutboxFailed  = "failed"
+)
+
+// OutboxMessage is a Kafka message written in the same transaction as its notification
+// and published asynchronously by the outbox relay.
+type OutboxMessage struct {
+	ID            int64     `gorm:"primaryKey;autoIncrement"`
+	Topic         string    `gorm:"size:255;not null"`
+	MessageKey    string    `gorm:"size:255;not null"`
+	Payload       []byte    `gorm:"type:jsonb;not null"`
+	Status        string    `gorm:"size:16;not null;default:pending"`
+	Attempts      int       `gorm:"not null;default:0"`
+	LastError     string    `gorm:"type:text"`
+	NextAttemptAt time.Time `gorm:"not null"`
+	// TraceParent and RequestID link the relay's publish to the request that created the message.
+	TraceParent string    `gorm:"size:64"`
+	RequestID   string    `gorm:"size:128"`
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+	SentAt      *time.Time
+}
+
+// ProcessedEvent records a score event that produced notifications, so redeliveries
+// of the same event_id are skipped.
+type ProcessedEvent struct {
+	EventID     string    `gorm:"primaryKey;size:128"`
+	ProcessedAt time.Time `gorm:"autoCreateTime"`
+}
+
+// TableName matches the SQL schema.
+func (ProcessedEvent) TableName() string {
+	return "processed_events"
+}
+
+// TableName keeps the outbox table name singular to match the SQL schema.
+func (OutboxMessage) TableName() string {
+	return "outbox"
+}
@@ -0,0 +1,28 @@
+package outbox
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	resultSent   = "sent"
+	resultRetry  = "retry"
+	resultFailed = "failed"
+)
+
+var (
+	outboxDepth = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "scorehub",
+		Subsystem: "outbox",
+		Name:      "pending_messages",
+		Help:      "Number of outbox messages waiting to be published.",
+	}, []string{"topic"})
+
+	outboxPublished = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "scorehub",
+		Subsystem: "outbox",
+		Name:      "publish_attempts_total",
+		Help:      "Outbox publish attempts by result (sent, retry, failed).",
+	}, []string{"topic", "result"})
+)
@@ -0,0 +1,161 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	logpkg "github.com/emorenkov/scorehub/pkg/common/logger"
+	"github.com/emorenkov/scorehub/pkg/common/requestid"
+	"github.com/emorenkov/scorehub/pkg/common/tracing"
+	"github.com/emorenkov/scorehub/pkg/notification"
+	"github.com/emorenkov/scorehub/pkg/notification/producer"
+	"github.com/emorenkov/scorehub/pkg/notification/repository"
+	"go.uber.org/zap"
+)
+
+// Config controls polling and retry behaviour of the relay.
+type Config struct {
+	Topic        string
+	PollInterval time.Duration
+	BatchSize    int
+	MaxAttempts  int
+	// Retry delay starts at BaseBackoff and doubles per attempt up to MaxBackoff.
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+}
+
+// Relay publishes pending outbox messages to Kafka and marks them sent.
+type Relay struct {
+	cfg       Config
+	repo      repository.OutboxRepository
+	publisher producer.Publisher
+	log       *zap.Logger
+}
+
+func NewRelay(cfg Config, repo repository.OutboxRepository, publisher producer.Publisher, log *zap.Logger) *Relay {
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = time.Second
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 10
+	}
+	if cfg.BaseBackoff <= 0 {
+		cfg.BaseBackoff = time.Second
+	}
+	if cfg.MaxBackoff < cfg.BaseBackoff {
+		cfg.MaxBackoff = 5 * time.Minute
+	}
+	return &Relay{cfg: cfg, repo: repo, publisher: publisher, log: log}
+}
+
+// Run polls the outbox until ctx is cancelled.
+func (r *Relay) Run(ctx context.Context) error {
+	r.log.Info("starting outbox relay", zap.String("topic", r.cfg.Topic), zap.Duration("poll_interval", r.cfg.PollInterval))
+	ticker := time.NewTicker(r.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		r.drain(ctx)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// drain publishes due batches until the outbox has nothing more to send right now.
+func (r *Relay) drain(ctx context.Context) {
+	for ctx.Err() == nil {
+		n, err := r.repo.ProcessPending(ctx, r.cfg.Topic, r.cfg.BatchSize, r.publish)
+		if err != nil {
+			if ctx.Err() == nil {
+				r.log.Error("outbox relay batch failed", zap.Error(err))
+			}
+			break
+		}
+		if n < r.cfg.BatchSize {
+			break
+		}
+	}
+
+	if depth, err := r.repo.CountPending(ctx, r.cfg.Topic); err == nil {
+		outboxDepth.WithLabelValues(r.cfg.Topic).Set(float64(depth))
+	}
+}
+
+// publish writes the decodable messages of batch in one Kafka call, each carrying the
+// trace context and request ID of the request that created it, and maps the outcome of
+// every message to a result.
+func (r *Relay) publish(ctx context.Context, batch []notification.OutboxMessage) []repository.OutboxResult {
+	results := make([]repository.OutboxResult, len(batch))
+	msgs := make([]producer.Message, 0, len(batch))
+	indexes := make([]int, 0, len(batch))
+	for i := range batch {
+		m := &batch[i]
+		msgCtx := tracing.WithTraceParent(ctx, m.TraceParent)
+		if m.RequestID != "" {
+			msgCtx = requestid.NewContext(msgCtx, m.RequestID)
+		}
+		var msg notification.NotificationMessage
+		if err := json.Unmarshal(m.Payload, &msg); err != nil {
+			// A payload we cannot decode will never succeed; fail it immediately.
+			logpkg.FromContext(msgCtx).Error("outbox message undecodable", zap.Error(err), zap.Int64("outbox_id", m.ID))
+			outboxPublished.WithLabelValues(r.cfg.Topic, resultFailed).Inc()
+			results[i] = repository.OutboxResult{Err: err}
+			continue
+		}
+		msgs = append(msgs, producer.Message{Ctx: msgCtx, Notification: &msg})
+		indexes = append(indexes, i)
+	}
+	if len(msgs) == 0 {
+		return results
+	}
+
+	errs := r.publisher.PublishBatch(ctx, msgs)
+	for j, i := range indexes {
+		var err error
+		if errs != nil {
+			err = errs[j]
+		}
+		results[i] = r.result(msgs[j].Ctx, &batch[i], err)
+	}
+	return results
+}
+
+// result schedules a retry for a failed publish of m, or fails m once it has used up its
+// attempts.
+func (r *Relay) result(ctx context.Context, m *notification.OutboxMessage, err error) repository.OutboxResult {
+	if err == nil {
+		outboxPublished.WithLabelValues(r.cfg.Topic, resultSent).Inc()
+		return repository.OutboxResult{}
+	}
+
+	log := logpkg.FromContext(ctx)
+	attempts := m.Attempts + 1
+	if attempts >= r.cfg.MaxAttempts {
+		log.Error("outbox message exhausted retries", zap.Error(err), zap.Int64("outbox_id", m.ID), zap.Int("attempts", attempts))
+		outboxPublished.WithLabelValues(r.cfg.Topic, resultFailed).Inc()
+		return repository.OutboxResult{Err: err}
+	}
+
+	retryAt := time.Now().Add(r.backoff(attempts))
+	log.Warn("outbox publish failed, will retry", zap.Error(err), zap.Int64("outbox_id", m.ID), zap.Int("attempts", attempts), zap.Time("retry_at", retryAt))
+	outboxPublished.WithLabelValues(r.cfg.Topic, resultRetry).Inc()
+	return repository.OutboxResult{Err: err, RetryAt: retryAt}
+}
+
+func (r *Relay) backoff(attempts int) time.Duration {
+	d := r.cfg.BaseBackoff
+	for i := 1; i < attempts; i++ {
+		d *= 2
+		if d >= r.cfg.MaxBackoff {
+			return r.cfg.MaxBackoff
+		}
+	}
+	return d
+}
@@ -0,0 +1,183 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/emorenkov/scorehub/pkg/common/requestid"
+	"github.com/emorenkov/scorehub/pkg/common/tracing"
+	"github.com/emorenkov/scorehub/pkg/notification"
+	"github.com/emorenkov/scorehub/pkg/notification/producer"
+	"github.com/emorenkov/scorehub/pkg/notification/repository"
+	"go.uber.org/zap"
+)
+
+const testTraceParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+// batchRepo hands out its pending messages in batches of the requested size and records
+// the results.
+type batchRepo struct {
+	repository.OutboxRepository
+	pending []notification.OutboxMessage
+	batches int
+	results map[int64]repository.OutboxResult
+}
+
+func (r *batchRepo) ProcessPending(ctx context.Context, _ string, limit int, handle func(context.Context, []notification.OutboxMessage) []repository.OutboxResult) (int, error) {
+	n := min(limit, len(r.pending))
+	if n == 0 {
+		return 0, nil
+	}
+	batch := r.pending[:n]
+	r.pending = r.pending[n:]
+	r.batches++
+	for i, res := range handle(ctx, batch) {
+		if r.results == nil {
+			r.results = map[int64]repository.OutboxResult{}
+		}
+		r.results[batch[i].ID] = res
+	}
+	return n, nil
+}
+
+func (r *batchRepo) CountPending(context.Context, string) (int64, error) {
+	return int64(len(r.pending)), nil
+}
+
+// batchPublisher records every PublishBatch call and fails the users in fail.
+type batchPublisher struct {
+	producer.Publisher
+	calls [][]producer.Message
+	fail  map[int64]error
+	err   error
+}
+
+func (p *batchPublisher) PublishBatch(_ context.Context, msgs []producer.Message) []error {
+	p.calls = append(p.calls, msgs)
+	if p.err != nil {
+		errs := make([]error, len(msgs))
+		for i := range errs {
+			errs[i] = p.err
+		}
+		return errs
+	}
+	if len(p.fail) == 0 {
+		return nil
+	}
+	errs := make([]error, len(msgs))
+	for i, m := range msgs {
+		errs[i] = p.fail[m.Notification.UserID]
+	}
+	return errs
+}
+
+func outboxMessage(t *testing.T, id, userID int64, attempts int) notification.OutboxMessage {
+	t.Helper()
+	payload, err := json.Marshal(notification.NotificationMessage{UserID: userID, Message: "hi"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return notification.OutboxMessage{ID: id, Payload: payload, Attempts: attempts}
+}
+
+func newTestRelay(repo repository.OutboxRepository, pub producer.Publisher, batchSize int) *Relay {
+	return NewRelay(Config{Topic: "notifications", BatchSize: batchSize, MaxAttempts: 3, BaseBackoff: time.Second, MaxBackoff: time.Minute}, repo, pub, zap.NewNop())
+}
+
+func TestRelayPublishesEachBatchInOneCall(t *testing.T) {
+	repo := &batchRepo{}
+	for id := int64(1); id <= 5; id++ {
+		repo.pending = append(repo.pending, outboxMessage(t, id, id, 0))
+	}
+	pub := &batchPublisher{}
+	newTestRelay(repo, pub, 2).drain(context.Background())
+
+	if len(pub.calls) != 3 || len(pub.calls[0]) != 2 || len(pub.calls[2]) != 1 {
+		t.Fatalf("publish calls %d, want batches of 2, 2 and 1", len(pub.calls))
+	}
+	if len(repo.results) != 5 {
+		t.Fatalf("%d results, want 5", len(repo.results))
+	}
+	for id, res := range repo.results {
+		if res.Err != nil {
+			t.Errorf("message %d: %v", id, res.Err)
+		}
+	}
+}
+
+func TestRelayMapsPerMessageErrors(t *testing.T) {
+	brokerDown := errors.New("leader not available")
+	repo := &batchRepo{pending: []notification.OutboxMessage{
+		outboxMessage(t, 1, 10, 0),
+		outboxMessage(t, 2, 20, 0),
+		outboxMessage(t, 3, 30, 2), // last attempt
+		{ID: 4, Payload: []byte("{not json")},
+	}}
+	pub := &batchPublisher{fail: map[int64]error{20: brokerDown, 30: brokerDown}}
+	start := time.Now()
+	newTestRelay(repo, pub, 10).drain(context.Background())
+
+	if len(pub.calls) != 1 || len(pub.calls[0]) != 3 {
+		t.Fatalf("publish calls %v, want the three decodable messages in one call", pub.calls)
+	}
+	if res := repo.results[1]; res.Err != nil {
+		t.Fatalf("message 1: %v, want sent", res.Err)
+	}
+	if res := repo.results[2]; !errors.Is(res.Err, brokerDown) || res.RetryAt.Before(start.Add(time.Second)) {
+		t.Fatalf("message 2: %+v, want a retry after the base backoff", res)
+	}
+	if res := repo.results[3]; res.Err == nil || !res.RetryAt.IsZero() {
+		t.Fatalf("message 3: %+v, want failed after its last attempt", res)
+	}
+	if res := repo.results[4]; res.Err == nil || !res.RetryAt.IsZero() {
+		t.Fatalf("message 4: %+v, want an undecodable payload failed", res)
+	}
+}
+
+func TestRelayRetriesWholeBatchOnWriteError(t *testing.T) {
+	repo := &batchRepo{pending: []notification.OutboxMessage{outboxMessage(t, 1, 10, 0), outboxMessage(t, 2, 20, 1)}}
+	pub := &batchPublisher{err: errors.New("dial tcp: connection refused")}
+	newTestRelay(repo, pub, 10).drain(context.Background())
+
+	first, second := repo.results[1], repo.results[2]
+	if first.Err == nil || first.RetryAt.IsZero() || second.Err == nil || second.RetryAt.IsZero() {
+		t.Fatalf("results %+v, %+v; want both retried", first, second)
+	}
+	if !second.RetryAt.After(first.RetryAt) {
+		t.Fatalf("second attempt retries at %v, not after the first attempt's %v", second.RetryAt, first.RetryAt)
+	}
+}
+
+func TestRelayPublishesWithTheCreatingRequestContext(t *testing.T) {
+	m := outboxMessage(t, 1, 10, 0)
+	m.TraceParent = testTraceParent
+	m.RequestID = "req-1"
+	plain := outboxMessage(t, 2, 20, 0)
+	repo := &batchRepo{pending: []notification.OutboxMessage{m, plain}}
+	pub := &batchPublisher{}
+	newTestRelay(repo, pub, 10).drain(context.Background())
+
+	msgs := pub.calls[0]
+	if got := tracing.TraceParent(msgs[0].Ctx); got != testTraceParent {
+		t.Fatalf("traceparent = %q, want %q", got, testTraceParent)
+	}
+	if got := requestid.FromContext(msgs[0].Ctx); got != "req-1" {
+		t.Fatalf("request id = %q, want req-1", got)
+	}
+	if got := tracing.TraceParent(msgs[1].Ctx); got != "" {
+		t.Fatalf("message without a trace got traceparent %q", got)
+	}
+}
+
+func TestBackoffDoublesUpToMax(t *testing.T) {
+	r := NewRelay(Config{BaseBackoff: time.Second, MaxBackoff: 5 * time.Second}, nil, nil, zap.NewNop())
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, w := range want {
+		if got := r.backoff(i + 1); got != w {
+			t.Errorf("backoff(%d) = %v, want %v", i+1, got, w)
+		}
+	}
+}
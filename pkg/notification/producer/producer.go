@@ -2,14 +2,25 @@ package producer
 
 import (
 	"context"
+	"errors"
 	"strconv"
 
 	ckafka "github.com/emorenkov/scorehub/pkg/common/kafka"
 	"github.com/emorenkov/scorehub/pkg/notification"
+	"github.com/segmentio/kafka-go"
 )
 
+// Message is a notification to publish together with the context of the request that
+// created it, which supplies the trace context and request ID headers.
+type Message struct {
+	Ctx          context.Context
+	Notification *notification.NotificationMessage
+}
+
 type Publisher interface {
-	Publish(ctx context.Context, msg *notification.NotificationMessage) error
+	// PublishBatch writes msgs in one call and returns one error per message, nil for
+	// the messages that were written. A nil slice means every message was written.
+	PublishBatch(ctx context.Context, msgs []Message) []error
 	Close() error
 }
 
@@ -23,8 +34,30 @@ func NewKafkaPublisher(brokers []string, topic string) *KafkaPublisher {
 	}
 }
 
-func (p *KafkaPublisher) Publish(ctx context.Context, msg *notification.NotificationMessage) error {
-	return p.writer.SendMessage(ctx, keyForUser(msg.UserID), msg)
+func (p *KafkaPublisher) PublishBatch(ctx context.Context, msgs []Message) []error {
+	batch := make([]ckafka.Message, 0, len(msgs))
+	for _, m := range msgs {
+		batch = append(batch, ckafka.Message{
+			Key:     keyForUser(m.Notification.UserID),
+			Value:   m.Notification,
+			Context: m.Ctx,
+		})
+	}
+	err := p.writer.SendMessages(ctx, batch)
+	if err == nil {
+		return nil
+	}
+	errs := make([]error, len(msgs))
+	var writeErrs kafka.WriteErrors
+	switch {
+	case errors.As(err, &writeErrs) && len(writeErrs) == len(msgs):
+		copy(errs, writeErrs)
+	default:
+		for i := range errs {
+			errs[i] = err
+		}
+	}
+	return errs
 }
 
 func (p *KafkaPublisher) Close() error {
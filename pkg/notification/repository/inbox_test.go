@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/emorenkov/scorehub/pkg/notification"
+	"gorm.io/gorm"
+)
+
+// TestInboxStatePostgres walks notifications through read and archive transitions and
+// checks the unread count and the status listings after each.
+func TestInboxStatePostgres(t *testing.T) {
+	db := testDB(t)
+	repo := NewGormRepository(db)
+	ctx := context.Background()
+	userID := createTestUser(t, db)
+
+	var ids []int64
+	for i := range 4 {
+		n := &notification.Notification{UserID: userID, Message: fmt.Sprintf("n%d", i)}
+		if err := repo.Create(ctx, n, nil); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, n.ID)
+	}
+	yes, no := true, false
+	expectUnread := func(want int64) {
+		t.Helper()
+		if got, err := repo.CountUnread(ctx, userID); err != nil || got != want {
+			t.Fatalf("unread count %d, %v, want %d", got, err, want)
+		}
+	}
+	expectStatus := func(status string, want ...int64) {
+		t.Helper()
+		ns, err := repo.List(ctx, notification.ListFilter{UserID: userID, Status: status})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []int64
+		for _, n := range ns {
+			got = append(got, n.ID)
+		}
+		slices.Sort(got)
+		if !slices.Equal(got, want) {
+			t.Fatalf("%s: got %v, want %v", status, got, want)
+		}
+	}
+	expectUnread(4)
+
+	read, err := repo.SetState(ctx, ids[0], &yes, nil)
+	if err != nil || read.ReadAt == nil || read.ArchivedAt != nil {
+		t.Fatalf("read: %+v, %v", read, err)
+	}
+	again, err := repo.SetState(ctx, ids[0], &yes, nil)
+	if err != nil || !again.ReadAt.Equal(*read.ReadAt) {
+		t.Fatalf("reading again moved read_at from %v to %v (%v)", read.ReadAt, again.ReadAt, err)
+	}
+	archived, err := repo.SetState(ctx, ids[1], nil, &yes)
+	if err != nil || archived.ArchivedAt == nil || archived.ReadAt != nil {
+		t.Fatalf("archive: %+v, %v", archived, err)
+	}
+	expectUnread(2)
+	expectStatus(notification.StatusUnread, ids[2], ids[3])
+	expectStatus(notification.StatusRead, ids[0])
+	expectStatus(notification.StatusArchived, ids[1])
+	expectStatus(notification.StatusInbox, ids[0], ids[2], ids[3])
+
+	// Archived notifications stay unread.
+	if n, err := repo.MarkAllRead(ctx, userID); err != nil || n != 2 {
+		t.Fatalf("marked %d, %v, want 2", n, err)
+	}
+	expectUnread(0)
+	expectStatus(notification.StatusRead, ids[0], ids[2], ids[3])
+	if n, err := repo.GetByID(ctx, ids[1]); err != nil || n.ReadAt != nil {
+		t.Fatalf("archived notification: %+v, %v", n, err)
+	}
+	if n, err := repo.MarkAllRead(ctx, userID); err != nil || n != 0 {
+		t.Fatalf("marked %d again, %v, want 0", n, err)
+	}
+
+	restored, err := repo.SetState(ctx, ids[1], &no, &no)
+	if err != nil || restored.ReadAt != nil || restored.ArchivedAt != nil {
+		t.Fatalf("unarchive: %+v, %v", restored, err)
+	}
+	expectUnread(1)
+	expectStatus(notification.StatusAll, ids...)
+
+	if unchanged, err := repo.SetState(ctx, ids[0], nil, nil); err != nil || unchanged.ReadAt == nil {
+		t.Fatalf("no changes: %+v, %v", unchanged, err)
+	}
+	if _, err := repo.SetState(ctx, ids[3]+1000000, &yes, nil); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("missing notification: got %v, want ErrRecordNotFound", err)
+	}
+}
@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	logpkg "github.com/emorenkov/scorehub/pkg/common/logger"
+	"github.com/jackc/pgx/v5/stdlib"
+	"go.uber.org/zap"
+)
+
+// NotifyChannel is the Postgres channel notified, with the user ID as payload, when
+// notifications for that user are committed.
+const NotifyChannel = "notifications_created"
+
+const maxListenBackoff = 30 * time.Second
+
+// Events wakes subscribers when notifications are created for a user.
+type Events interface {
+	// Subscribe returns a channel that receives a value after notifications for userID
+	// are committed, and a function that ends the subscription. Wake-ups are coalesced,
+	// and the channel is closed when the source stops.
+	Subscribe(userID int64) (<-chan struct{}, func())
+}
+
+// Listener implements Events with Postgres LISTEN/NOTIFY. Every replica runs one, so
+// notifications created through any replica wake subscribers connected to all of them.
+type Listener struct {
+	db     *sql.DB
+	mu     sync.Mutex
+	subs   map[int64]map[chan struct{}]struct{}
+	closed bool
+}
+
+// NewListener returns a listener that takes one connection from db while running.
+func NewListener(db *sql.DB) *Listener {
+	return &Listener{db: db, subs: map[int64]map[chan struct{}]struct{}{}}
+}
+
+func (l *Listener) Subscribe(userID int64) (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		close(ch)
+		return ch, func() {}
+	}
+	if l.subs[userID] == nil {
+		l.subs[userID] = map[chan struct{}]struct{}{}
+	}
+	l.subs[userID][ch] = struct{}{}
+	return ch, func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if _, ok := l.subs[userID][ch]; !ok {
+			return
+		}
+		delete(l.subs[userID], ch)
+		if len(l.subs[userID]) == 0 {
+			delete(l.subs, userID)
+		}
+		close(ch)
+	}
+}
+
+// Run listens until ctx is done, reconnecting with backoff, and then closes every
+// subscription. Subscribers are woken after each (re)connect, since notifications
+// sent while disconnected are lost.
+func (l *Listener) Run(ctx context.Context) error {
+	defer l.close()
+	backoff := time.Second
+	for {
+		connected, err := l.listen(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if connected {
+			backoff = time.Second
+		}
+		logpkg.FromContext(ctx).Warn("notification listener disconnected",
+			zap.Error(err), zap.Duration("retry_in", backoff))
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxListenBackoff)
+	}
+}
+
+func (l *Listener) listen(ctx context.Context) (bool, error) {
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+	// The session is left listening, so keep it out of the pool.
+	defer conn.Raw(func(any) error { return driver.ErrBadConn })
+
+	if _, err := conn.ExecContext(ctx, "LISTEN "+NotifyChannel); err != nil {
+		return false, err
+	}
+	l.wakeAll()
+	return true, conn.Raw(func(dc any) error {
+		pc, ok := dc.(*stdlib.Conn)
+		if !ok {
+			return fmt.Errorf("unexpected driver connection %T", dc)
+		}
+		for {
+			n, err := pc.Conn().WaitForNotification(ctx)
+			if err != nil {
+				return err
+			}
+			userID, err := strconv.ParseInt(n.Payload, 10, 64)
+			if err != nil {
+				continue
+			}
+			l.wake(userID)
+		}
+	})
+}
+
+func (l *Listener) wake(userID int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for ch := range l.subs[userID] {
+		notify(ch)
+	}
+}
+
+func (l *Listener) wakeAll() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, subs := range l.subs {
+		for ch := range subs {
+			notify(ch)
+		}
+	}
+}
+
+func (l *Listener) close() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.closed = true
+	for userID, subs := range l.subs {
+		for ch := range subs {
+			close(ch)
+		}
+		delete(l.subs, userID)
+	}
+}
+
+func notify(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
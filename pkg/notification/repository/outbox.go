@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/emorenkov/scorehub/pkg/notification"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// OutboxResult is the outcome of handling a single outbox message.
+type OutboxResult struct {
+	Err error
+	// RetryAt schedules the next attempt when Err is set; a zero value marks the message failed.
+	RetryAt time.Time
+}
+
+// OutboxRepository gives the relay access to pending outbox messages.
+type OutboxRepository interface {
+	// ProcessPending locks up to limit due messages for topic, passes them to handle in one
+	// call and records the results, which handle returns aligned with the batch. Rows
+	// locked by another relay are skipped.
+	ProcessPending(ctx context.Context, topic string, limit int, handle func(ctx context.Context, batch []notification.OutboxMessage) []OutboxResult) (int, error)
+	CountPending(ctx context.Context, topic string) (int64, error)
+}
+
+func (r *GormRepository) ProcessPending(ctx context.Context, topic string, limit int, handle func(ctx context.Context, batch []notification.OutboxMessage) []OutboxResult) (int, error) {
+	var processed int
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var batch []notification.OutboxMessage
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("topic = ? AND status = ? AND next_attempt_at <= ?", topic, notification.OutboxPending, time.Now()).
+			Order("id").
+			Limit(limit).
+			Find(&batch).Error; err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+
+		results := handle(ctx, batch)
+		if len(results) != len(batch) {
+			return fmt.Errorf("outbox handler returned %d results for %d messages", len(results), len(batch))
+		}
+		// Sent messages, normally the whole batch, are marked with one statement.
+		var sent []int64
+		for i := range batch {
+			m, res := &batch[i], results[i]
+			if res.Err == nil {
+				sent = append(sent, m.ID)
+				continue
+			}
+			updates := map[string]any{"attempts": m.Attempts + 1, "last_error": res.Err.Error()}
+			if res.RetryAt.IsZero() {
+				updates["status"] = notification.OutboxFailed
+			} else {
+				updates["next_attempt_at"] = res.RetryAt
+			}
+			if err := tx.Model(m).Updates(updates).Error; err != nil {
+				return err
+			}
+		}
+		if len(sent) > 0 {
+			if err := tx.Model(&notification.OutboxMessage{}).Where("id IN ?", sent).Updates(map[string]any{
+				"status":     notification.OutboxSent,
+				"sent_at":    time.Now(),
+				"attempts":   gorm.Expr("attempts + 1"),
+				"last_error": nil,
+			}).Error; err != nil {
+				return err
+			}
+		}
+		processed = len(batch)
+		return nil
+	})
+	return processed, err
+}
+
+func (r *GormRepository) CountPending(ctx context.Context, topic string) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&notification.OutboxMessage{}).
+		Where("topic = ? AND status = ?", topic, notification.OutboxPending).
+		Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/emorenkov/scorehub/pkg/notification"
+	"gorm.io/gorm"
+)
+
+// seedOutbox inserts n due messages on a topic of their own and returns it.
+func seedOutbox(t *testing.T, db *gorm.DB, n int) string {
+	t.Helper()
+	topic := fmt.Sprintf("test-%s-%d", t.Name(), time.Now().UnixNano())
+	for i := 0; i < n; i++ {
+		m := &notification.OutboxMessage{
+			Topic:         topic,
+			MessageKey:    "user:1",
+			Payload:       []byte(`{"user_id":1}`),
+			Status:        notification.OutboxPending,
+			NextAttemptAt: time.Now().Add(-time.Second),
+		}
+		if err := db.Create(m).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Cleanup(func() { db.Where("topic = ?", topic).Delete(&notification.OutboxMessage{}) })
+	return topic
+}
+
+func TestProcessPendingRecordsResults(t *testing.T) {
+	db := testDB(t)
+	repo := NewGormRepository(db)
+	topic := seedOutbox(t, db, 3)
+	retryAt := time.Now().Add(time.Hour).Truncate(time.Microsecond)
+
+	n, err := repo.ProcessPending(context.Background(), topic, 10, func(_ context.Context, batch []notification.OutboxMessage) []OutboxResult {
+		if len(batch) != 3 {
+			t.Fatalf("batch of %d, want 3", len(batch))
+		}
+		return []OutboxResult{{}, {Err: errors.New("timeout"), RetryAt: retryAt}, {Err: errors.New("too large")}}
+	})
+	if err != nil || n != 3 {
+		t.Fatalf("ProcessPending = %d, %v", n, err)
+	}
+
+	var rows []notification.OutboxMessage
+	if err := db.Where("topic = ?", topic).Order("id").Find(&rows).Error; err != nil {
+		t.Fatal(err)
+	}
+	if rows[0].Status != notification.OutboxSent || rows[0].SentAt == nil || rows[0].Attempts != 1 {
+		t.Errorf("sent row %+v", rows[0])
+	}
+	if rows[1].Status != notification.OutboxPending || !rows[1].NextAttemptAt.Equal(retryAt) || rows[1].LastError != "timeout" || rows[1].Attempts != 1 {
+		t.Errorf("retried row %+v", rows[1])
+	}
+	if rows[2].Status != notification.OutboxFailed || rows[2].LastError != "too large" {
+		t.Errorf("failed row %+v", rows[2])
+	}
+
+	// The retried row is not due yet and the others are done.
+	n, err = repo.ProcessPending(context.Background(), topic, 10, func(context.Context, []notification.OutboxMessage) []OutboxResult {
+		t.Fatal("handler called without due messages")
+		return nil
+	})
+	if err != nil || n != 0 {
+		t.Fatalf("second ProcessPending = %d, %v", n, err)
+	}
+}
+
+func TestProcessPendingSkipsLockedRows(t *testing.T) {
+	db := testDB(t)
+	repo := NewGormRepository(db)
+	topic := seedOutbox(t, db, 2)
+
+	locked := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		_, err := repo.ProcessPending(context.Background(), topic, 1, func(_ context.Context, batch []notification.OutboxMessage) []OutboxResult {
+			close(locked)
+			<-release
+			return make([]OutboxResult, len(batch))
+		})
+		done <- err
+	}()
+	<-locked
+
+	var seen []int64
+	n, err := repo.ProcessPending(context.Background(), topic, 10, func(_ context.Context, batch []notification.OutboxMessage) []OutboxResult {
+		for _, m := range batch {
+			seen = append(seen, m.ID)
+		}
+		return make([]OutboxResult, len(batch))
+	})
+	close(release)
+	if err != nil || n != 1 || len(seen) != 1 {
+		t.Fatalf("concurrent ProcessPending = %d, %v, saw %v; want only the unlocked row", n, err, seen)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestProcessPendingRejectsMisalignedResults(t *testing.T) {
+	db := testDB(t)
+	repo := NewGormRepository(db)
+	topic := seedOutbox(t, db, 2)
+
+	_, err := repo.ProcessPending(context.Background(), topic, 10, func(context.Context, []notification.OutboxMessage) []OutboxResult {
+		return []OutboxResult{{}}
+	})
+	if err == nil {
+		t.Fatal("ProcessPending accepted one result for two messages")
+	}
+	var pending int64
+	db.Model(&notification.OutboxMessage{}).Where("topic = ? AND status = ?", topic, notification.OutboxPending).Count(&pending)
+	if pending != 2 {
+		t.Fatalf("%d pending, want the batch rolled back", pending)
+	}
+}
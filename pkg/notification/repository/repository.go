@@ -2,15 +2,42 @@ package repository
 
 import (
 	"context"
+	"maps"
+	"slices"
+	"strconv"
+	"time"
 
 	"github.com/emorenkov/scorehub/pkg/notification"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type Repository interface {
-	Create(ctx context.Context, n *notification.Notification) error
+	// Create persists the notification and, when out is non-nil, its outbox message in one transaction.
+	Create(ctx context.Context, n *notification.Notification, out *notification.OutboxMessage) error
+	// CreateForEvent persists several notifications with their outbox messages (aligned by
+	// index, nil entries skipped) in one transaction. A non-empty eventID is recorded in the
+	// same transaction; when it was already processed nothing is written and duplicate is true.
+	// Each notification gets its user's next stream position, and committed notifications
+	// are announced on NotifyChannel.
+	CreateForEvent(ctx context.Context, eventID string, ns []*notification.Notification, outs []*notification.OutboxMessage) (duplicate bool, err error)
+	// PurgeProcessedEvents forgets processed event IDs recorded before cutoff.
+	PurgeProcessedEvents(ctx context.Context, cutoff time.Time) (int64, error)
 	GetByID(ctx context.Context, id int64) (*notification.Notification, error)
-	List(ctx context.Context, userID int64) ([]notification.Notification, error)
+	List(ctx context.Context, filter notification.ListFilter) ([]notification.Notification, error)
+	// SetState marks notification id read or unread and archived or not; nil leaves that
+	// state unchanged, and setting a state that is already set keeps its timestamp.
+	SetState(ctx context.Context, id int64, read, archived *bool) (*notification.Notification, error)
+	// MarkAllRead marks every unread notification of userID read and returns how many changed.
+	MarkAllRead(ctx context.Context, userID int64) (int64, error)
+	// CountUnread counts userID's notifications that are neither read nor archived.
+	CountUnread(ctx context.Context, userID int64) (int64, error)
+	// ListStream returns up to limit of userID's notifications with a stream position
+	// above afterSeq, in commit order.
+	ListStream(ctx context.Context, userID, afterSeq int64, limit int) ([]notification.Notification, error)
+	// StreamSeq returns the stream position of userID's notification afterID, or of the
+	// user's latest notification when afterID is zero.
+	StreamSeq(ctx context.Context, userID, afterID int64) (int64, error)
 }
 
 type GormRepository struct {
@@ -21,8 +48,57 @@ func NewGormRepository(db *gorm.DB) *GormRepository {
 	return &GormRepository{db: db}
 }
 
-func (r *GormRepository) Create(ctx context.Context, n *notification.Notification) error {
-	return r.db.WithContext(ctx).Create(n).Error
+func (r *GormRepository) Create(ctx context.Context, n *notification.Notification, out *notification.OutboxMessage) error {
+	_, err := r.CreateForEvent(ctx, "", []*notification.Notification{n}, []*notification.OutboxMessage{out})
+	return err
+}
+
+func (r *GormRepository) CreateForEvent(ctx context.Context, eventID string, ns []*notification.Notification, outs []*notification.OutboxMessage) (bool, error) {
+	var duplicate bool
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if eventID != "" {
+			res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&notification.ProcessedEvent{EventID: eventID})
+			if res.Error != nil {
+				return res.Error
+			}
+			if res.RowsAffected == 0 {
+				duplicate = true
+				return nil
+			}
+		}
+		if err := reserveStreamSeqs(tx, ns); err != nil {
+			return err
+		}
+		for i, n := range ns {
+			if err := tx.Create(n).Error; err != nil {
+				return err
+			}
+			if i >= len(outs) || outs[i] == nil {
+				continue
+			}
+			if err := tx.Create(outs[i]).Error; err != nil {
+				return err
+			}
+		}
+		// Delivered on commit to every replica's Listener.
+		notified := map[int64]bool{}
+		for _, n := range ns {
+			if notified[n.UserID] {
+				continue
+			}
+			notified[n.UserID] = true
+			if err := tx.Exec("SELECT pg_notify(?, ?)", NotifyChannel, strconv.FormatInt(n.UserID, 10)).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return duplicate, err
+}
+
+func (r *GormRepository) PurgeProcessedEvents(ctx context.Context, cutoff time.Time) (int64, error) {
+	res := r.db.WithContext(ctx).Where("processed_at < ?", cutoff).Delete(&notification.ProcessedEvent{})
+	return res.RowsAffected, res.Error
 }
 
 func (r *GormRepository) GetByID(ctx context.Context, id int64) (*notification.Notification, error) {
@@ -33,14 +109,145 @@ func (r *GormRepository) GetByID(ctx context.Context, id int64) (*notification.N
 	return &n, nil
 }
 
-func (r *GormRepository) List(ctx context.Context, userID int64) ([]notification.Notification, error) {
+func (r *GormRepository) List(ctx context.Context, filter notification.ListFilter) ([]notification.Notification, error) {
 	var notifications []notification.Notification
 	query := r.db.WithContext(ctx)
-	if userID > 0 {
-		query = query.Where("user_id = ?", userID)
+	if filter.UserID > 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	switch filter.Status {
+	case notification.StatusInbox:
+		query = query.Where("archived_at IS NULL")
+	case notification.StatusUnread:
+		query = query.Where("read_at IS NULL AND archived_at IS NULL")
+	case notification.StatusRead:
+		query = query.Where("read_at IS NOT NULL AND archived_at IS NULL")
+	case notification.StatusArchived:
+		query = query.Where("archived_at IS NOT NULL")
+	}
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	if filter.Priority != "" {
+		query = query.Where("priority = ?", filter.Priority)
+	}
+	if !filter.Since.IsZero() {
+		query = query.Where("created_at >= ?", filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		query = query.Where("created_at < ?", filter.Until)
 	}
-	if err := query.Order("created_at DESC").Find(&notifications).Error; err != nil {
+	// Newest first, keyed on (created_at, id) so pages stay stable as rows are added;
+	// idx_notifications_user_created_at serves the per-user case.
+	if filter.After != nil {
+		query = query.Where("(created_at, id) < (?, ?)", filter.After.CreatedAt, filter.After.ID)
+	}
+	query = query.Order("created_at DESC").Order("id DESC")
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	if err := query.Find(&notifications).Error; err != nil {
 		return nil, err
 	}
 	return notifications, nil
 }
+
+func (r *GormRepository) SetState(ctx context.Context, id int64, read, archived *bool) (*notification.Notification, error) {
+	updates := map[string]any{}
+	if read != nil {
+		updates["read_at"] = stateValue(*read, "read_at")
+	}
+	if archived != nil {
+		updates["archived_at"] = stateValue(*archived, "archived_at")
+	}
+	if len(updates) > 0 {
+		res := r.db.WithContext(ctx).Model(&notification.Notification{}).Where("id = ?", id).Updates(updates)
+		if res.Error != nil {
+			return nil, res.Error
+		}
+		if res.RowsAffected == 0 {
+			return nil, gorm.ErrRecordNotFound
+		}
+	}
+	return r.GetByID(ctx, id)
+}
+
+// stateValue sets a timestamp column, keeping an existing value, or clears it.
+func stateValue(set bool, column string) any {
+	if !set {
+		return nil
+	}
+	return gorm.Expr("COALESCE(" + column + ", NOW())")
+}
+
+func (r *GormRepository) MarkAllRead(ctx context.Context, userID int64) (int64, error) {
+	res := r.db.WithContext(ctx).Model(&notification.Notification{}).
+		Where("user_id = ? AND read_at IS NULL AND archived_at IS NULL", userID).
+		Update("read_at", gorm.Expr("NOW()"))
+	return res.RowsAffected, res.Error
+}
+
+func (r *GormRepository) CountUnread(ctx context.Context, userID int64) (int64, error) {
+	var n int64
+	err := r.db.WithContext(ctx).Model(&notification.Notification{}).
+		Where("user_id = ? AND read_at IS NULL AND archived_at IS NULL", userID).
+		Count(&n).Error
+	return n, err
+}
+
+func (r *GormRepository) ListStream(ctx context.Context, userID, afterSeq int64, limit int) ([]notification.Notification, error) {
+	var notifications []notification.Notification
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND stream_seq > ?", userID, afterSeq).
+		Order("stream_seq ASC").
+		Limit(limit).
+		Find(&notifications).Error
+	return notifications, err
+}
+
+func (r *GormRepository) StreamSeq(ctx context.Context, userID, afterID int64) (int64, error) {
+	var seq int64
+	var err error
+	if afterID == 0 {
+		err = r.db.WithContext(ctx).
+			Raw("SELECT COALESCE(MAX(seq), 0) FROM notification_streams WHERE user_id = ?", userID).
+			Scan(&seq).Error
+	} else {
+		// A notification that no longer exists resumes after the ones created before it.
+		err = r.db.WithContext(ctx).Raw(`SELECT COALESCE(
+			(SELECT stream_seq FROM notifications WHERE user_id = ? AND id = ?),
+			(SELECT MAX(stream_seq) FROM notifications WHERE user_id = ? AND id <= ?),
+			0)`, userID, afterID, userID, afterID).
+			Scan(&seq).Error
+	}
+	return seq, err
+}
+
+// reserveStreamSeqs assigns ns the next stream positions of their users. tx holds the
+// users' notification_streams rows locked until it commits, so positions are handed out
+// in commit order. Users are locked in ID order, so concurrent transactions cannot
+// deadlock.
+func reserveStreamSeqs(tx *gorm.DB, ns []*notification.Notification) error {
+	counts := map[int64]int64{}
+	for _, n := range ns {
+		counts[n.UserID]++
+	}
+	for _, userID := range slices.Sorted(maps.Keys(counts)) {
+		var last int64
+		err := tx.Raw(`INSERT INTO notification_streams (user_id, seq) VALUES (?, ?)
+			ON CONFLICT (user_id) DO UPDATE SET seq = notification_streams.seq + EXCLUDED.seq
+			RETURNING seq`, userID, counts[userID]).
+			Scan(&last).Error
+		if err != nil {
+			return err
+		}
+		next := last - counts[userID]
+		for _, n := range ns {
+			if n.UserID == userID {
+				next++
+				n.StreamSeq = next
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/emorenkov/scorehub/pkg/notification"
+	"gorm.io/gorm"
+)
+
+// RuleRepository stores notification rules.
+type RuleRepository interface {
+	CreateRule(ctx context.Context, r *notification.Rule) error
+	GetRule(ctx context.Context, id int64) (*notification.Rule, error)
+	// ListRules returns rules in evaluation order; enabledOnly restricts to the active set.
+	ListRules(ctx context.Context, enabledOnly bool) ([]notification.Rule, error)
+	UpdateRule(ctx context.Context, r *notification.Rule) error
+	DeleteRule(ctx context.Context, id int64) error
+}
+
+func (r *GormRepository) CreateRule(ctx context.Context, rule *notification.Rule) error {
+	return r.db.WithContext(ctx).Create(rule).Error
+}
+
+func (r *GormRepository) GetRule(ctx context.Context, id int64) (*notification.Rule, error) {
+	var rule notification.Rule
+	if err := r.db.WithContext(ctx).First(&rule, id).Error; err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (r *GormRepository) ListRules(ctx context.Context, enabledOnly bool) ([]notification.Rule, error) {
+	var rules []notification.Rule
+	query := r.db.WithContext(ctx)
+	if enabledOnly {
+		query = query.Where("enabled = ?", true)
+	}
+	if err := query.Order("position ASC, id ASC").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// UpdateRule saves every field of rule, including zero values such as Enabled=false.
+func (r *GormRepository) UpdateRule(ctx context.Context, rule *notification.Rule) error {
+	res := r.db.WithContext(ctx).Model(rule).Select("name", "description", "enabled", "position", "conditions", "action", "updated_at").Updates(rule)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *GormRepository) DeleteRule(ctx context.Context, id int64) error {
+	res := r.db.WithContext(ctx).Delete(&notification.Rule{}, id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
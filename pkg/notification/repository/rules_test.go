@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/emorenkov/scorehub/pkg/common/db/migrate"
+	"github.com/emorenkov/scorehub/pkg/notification"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// dryRunDB returns a DB that builds statements without connecting.
+func dryRunDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost", PreferSimpleProtocol: true}),
+		&gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// testDB connects to SCOREHUB_TEST_POSTGRES_DSN and applies the migrations, skipping the
+// test when it is unset.
+func testDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := os.Getenv("SCOREHUB_TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("SCOREHUB_TEST_POSTGRES_DSN is not set")
+	}
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: dsn, PreferSimpleProtocol: true}), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	m, err := migrate.New(sqlDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Up(context.Background(), 0); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// createTestUser inserts a user for notifications to reference.
+func createTestUser(t *testing.T, db *gorm.DB) int64 {
+	t.Helper()
+	var id int64
+	err := db.Raw("INSERT INTO users (name, email) VALUES (?, ?) RETURNING id",
+		"Anna", fmt.Sprintf("notifications-%d@example.com", time.Now().UnixNano())).Scan(&id).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		db.Exec("DELETE FROM notifications WHERE user_id = ?", id)
+		db.Exec("DELETE FROM notification_streams WHERE user_id = ?", id)
+		db.Exec("DELETE FROM users WHERE id = ?", id)
+	})
+	return id
+}
+
+func TestCreateRuleKeepsDisabled(t *testing.T) {
+	stmt := dryRunDB(t).Create(&notification.Rule{Name: "off", Enabled: false}).Statement
+	if !strings.Contains(stmt.SQL.String(), `"enabled"`) {
+		t.Fatalf("INSERT omits enabled, so the column default applies: %s", stmt.SQL.String())
+	}
+	for _, v := range stmt.Vars {
+		if v == true {
+			t.Fatalf("INSERT binds true for a disabled rule: %v", stmt.Vars)
+		}
+	}
+}
+
+func TestDisabledRuleRoundTrip(t *testing.T) {
+	db := testDB(t)
+	repo := NewGormRepository(db)
+	ctx := context.Background()
+
+	rule := &notification.Rule{
+		Name:   "disabled-round-trip",
+		Action: notification.RuleAction{Template: "x", Channel: notification.ChannelInApp, Priority: notification.PriorityLow},
+	}
+	if err := repo.CreateRule(ctx, rule); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = repo.DeleteRule(ctx, rule.ID) })
+
+	got, err := repo.GetRule(ctx, rule.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Enabled {
+		t.Fatal("rule created disabled was stored enabled")
+	}
+	active, err := repo.ListRules(ctx, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range active {
+		if r.ID == rule.ID {
+			t.Fatal("disabled rule is listed as active")
+		}
+	}
+}
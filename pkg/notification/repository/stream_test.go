@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/emorenkov/scorehub/pkg/notification"
+)
+
+// TestStreamSeqFollowsCommitOrder holds a transaction open after it reserved a position:
+// a second notification for the same user waits for it and is positioned after it, even
+// though its ID is lower.
+func TestStreamSeqFollowsCommitOrder(t *testing.T) {
+	db := testDB(t)
+	repo := NewGormRepository(db)
+	ctx := context.Background()
+	userID := createTestUser(t, db)
+
+	second := &notification.Notification{UserID: userID, Message: "second"}
+	tx := db.Begin()
+	defer tx.Rollback()
+	first := &notification.Notification{UserID: userID, Message: "first"}
+	if err := reserveStreamSeqs(tx, []*notification.Notification{first}); err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- repo.Create(ctx, second, nil) }()
+	select {
+	case err := <-done:
+		t.Fatalf("second transaction committed while the first held its position: %v", err)
+	case <-time.After(200 * time.Millisecond):
+	}
+	// first is inserted last, so it gets the higher ID.
+	if err := tx.Create(first).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit().Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if second.StreamSeq != first.StreamSeq+1 {
+		t.Fatalf("positions %d then %d, want consecutive", first.StreamSeq, second.StreamSeq)
+	}
+
+	got, err := repo.ListStream(ctx, userID, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].ID != first.ID || got[1].ID != second.ID {
+		t.Fatalf("streamed %+v, want %d then %d", got, first.ID, second.ID)
+	}
+	if seq, err := repo.StreamSeq(ctx, userID, first.ID); err != nil || seq != first.StreamSeq {
+		t.Fatalf("resuming after %d: got %d, %v, want %d", first.ID, seq, err, first.StreamSeq)
+	}
+	if seq, err := repo.StreamSeq(ctx, userID, 0); err != nil || seq != second.StreamSeq {
+		t.Fatalf("latest position: got %d, %v, want %d", seq, err, second.StreamSeq)
+	}
+}
+
+// TestListenerWakesOtherReplicas creates a notification through one connection pool and
+// expects the listener of another to wake its subscriber.
+func TestListenerWakesOtherReplicas(t *testing.T) {
+	writer, reader := testDB(t), testDB(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	userID := createTestUser(t, writer)
+
+	sqlDB, err := reader.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := NewListener(sqlDB)
+	go l.Run(ctx)
+	wake, unsubscribe := l.Subscribe(userID)
+	defer unsubscribe()
+	// Run wakes every subscriber once it is listening.
+	select {
+	case <-wake:
+	case <-time.After(5 * time.Second):
+		t.Fatal("listener did not start")
+	}
+
+	n := &notification.Notification{UserID: userID, Message: "hello"}
+	if err := NewGormRepository(writer).Create(ctx, n, nil); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-wake:
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscriber was not woken")
+	}
+	got, err := NewGormRepository(reader).ListStream(ctx, userID, n.StreamSeq-1, 10)
+	if err != nil || len(got) != 1 || got[0].ID != n.ID {
+		t.Fatalf("got %+v, %v", got, err)
+	}
+}
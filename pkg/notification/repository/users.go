@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	apperrors "github.com/emorenkov/scorehub/pkg/common/errors"
+	"github.com/emorenkov/scorehub/pkg/notification"
+	userpb "github.com/emorenkov/scorehub/pkg/user/models/proto"
+	"github.com/hashicorp/golang-lru/v2/expirable"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultUserCacheSize     = 10000
+	defaultUserCacheTTL      = 5 * time.Minute
+	defaultUserLookupTimeout = 2 * time.Second
+)
+
+// Recipients resolves the user a notification is addressed to.
+type Recipients interface {
+	// Lookup returns the recipient for userID, a 404 StatusError when the user does not
+	// exist (or was deleted), or a 503 StatusError when user-service cannot be reached.
+	Lookup(ctx context.Context, userID int64) (*notification.Recipient, error)
+}
+
+// UserDirectory looks recipients up in user-service, caching them for a TTL so bursts
+// of events for the same user cost one call. Name, email and preference changes take
+// effect within the TTL.
+type UserDirectory struct {
+	client  userpb.UserServiceClient
+	timeout time.Duration
+	entries *expirable.LRU[int64, *notification.Recipient]
+}
+
+// NewUserDirectory returns a directory over client. Each lookup is bounded by timeout;
+// non-positive size, ttl and timeout select defaults.
+func NewUserDirectory(client userpb.UserServiceClient, size int, ttl, timeout time.Duration) *UserDirectory {
+	if size <= 0 {
+		size = defaultUserCacheSize
+	}
+	if ttl <= 0 {
+		ttl = defaultUserCacheTTL
+	}
+	if timeout <= 0 {
+		timeout = defaultUserLookupTimeout
+	}
+	return &UserDirectory{
+		client:  client,
+		timeout: timeout,
+		entries: expirable.NewLRU[int64, *notification.Recipient](size, nil, ttl),
+	}
+}
+
+// Lookup implements Recipients. An unreachable or slow user-service is reported as 503,
+// other failures as 500.
+func (d *UserDirectory) Lookup(ctx context.Context, userID int64) (*notification.Recipient, error) {
+	if r, ok := d.entries.Get(userID); ok {
+		return r, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, d.timeout)
+	defer cancel()
+	resp, err := d.client.GetUser(ctx, &userpb.GetUserRequest{Id: userID})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, apperrors.NewStatusError(http.StatusNotFound, "user not found")
+		}
+		if unavailable(err) {
+			return nil, apperrors.WrapStatus(err, http.StatusServiceUnavailable, "look up user")
+		}
+		return nil, apperrors.WrapStatus(err, http.StatusInternalServerError, "look up user")
+	}
+	u := resp.GetUser()
+	r := &notification.Recipient{
+		UserID:      userID,
+		Name:        u.GetName(),
+		Email:       u.GetEmail(),
+		EmailOptOut: u.GetPreferences().GetEmailOptOut(),
+	}
+	d.entries.Add(userID, r)
+	return r, nil
+}
+
+// unavailable reports whether err means user-service could not answer in time.
+func unavailable(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return true
+	}
+	return errors.Is(err, context.DeadlineExceeded)
+}
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	apperrors "github.com/emorenkov/scorehub/pkg/common/errors"
+	"github.com/emorenkov/scorehub/pkg/notification"
+	userpb "github.com/emorenkov/scorehub/pkg/user/models/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// userClient answers GetUser with a fixed user, or err, and counts the calls. A blocking
+// client waits for the call's deadline.
+type userClient struct {
+	userpb.UserServiceClient
+	err   error
+	block bool
+	calls int
+}
+
+func (c *userClient) GetUser(ctx context.Context, in *userpb.GetUserRequest, _ ...grpc.CallOption) (*userpb.UserResponse, error) {
+	c.calls++
+	if c.block {
+		<-ctx.Done()
+		return nil, status.FromContextError(ctx.Err()).Err()
+	}
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &userpb.UserResponse{User: &userpb.User{
+		Id:          in.GetId(),
+		Name:        "Anna Smith",
+		Email:       "anna@example.com",
+		Preferences: &userpb.UserPreferences{EmailOptOut: true},
+	}}, nil
+}
+
+func TestUserDirectoryLookup(t *testing.T) {
+	d := NewUserDirectory(&userClient{}, 0, 0, 0)
+	r, err := d.Lookup(context.Background(), 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := notification.Recipient{UserID: 7, Name: "Anna Smith", Email: "anna@example.com", EmailOptOut: true}
+	if *r != want {
+		t.Fatalf("got %+v, want %+v", *r, want)
+	}
+}
+
+func TestUserDirectoryCachesLookups(t *testing.T) {
+	client := &userClient{}
+	d := NewUserDirectory(client, 0, 0, 0)
+	for range 3 {
+		if _, err := d.Lookup(context.Background(), 7); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := d.Lookup(context.Background(), 8); err != nil {
+		t.Fatal(err)
+	}
+	if client.calls != 2 {
+		t.Fatalf("made %d calls, want one per user", client.calls)
+	}
+}
+
+func TestUserDirectoryExpiresEntries(t *testing.T) {
+	client := &userClient{}
+	d := NewUserDirectory(client, 0, 20*time.Millisecond, 0)
+	if _, err := d.Lookup(context.Background(), 7); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if _, err := d.Lookup(context.Background(), 7); err != nil {
+		t.Fatal(err)
+	}
+	if client.calls != 2 {
+		t.Fatalf("made %d calls, want the expired entry looked up again", client.calls)
+	}
+}
+
+func TestUserDirectoryDoesNotCacheFailures(t *testing.T) {
+	client := &userClient{err: status.Error(codes.Unavailable, "down")}
+	d := NewUserDirectory(client, 0, 0, 0)
+	if _, err := d.Lookup(context.Background(), 7); err == nil {
+		t.Fatal("lookup succeeded")
+	}
+	client.err = nil
+	if _, err := d.Lookup(context.Background(), 7); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUserDirectoryTimeout(t *testing.T) {
+	d := NewUserDirectory(&userClient{block: true}, 0, 0, 20*time.Millisecond)
+	start := time.Now()
+	_, err := d.Lookup(context.Background(), 7)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("lookup took %v", elapsed)
+	}
+	if se, ok := apperrors.AsStatusError(err); !ok || se.Status != http.StatusServiceUnavailable {
+		t.Fatalf("got %v, want 503", err)
+	}
+}
+
+func TestUserDirectoryErrors(t *testing.T) {
+	for name, tc := range map[string]struct {
+		err  error
+		want int
+	}{
+		"not found":         {status.Error(codes.NotFound, "x"), http.StatusNotFound},
+		"unavailable":       {status.Error(codes.Unavailable, "x"), http.StatusServiceUnavailable},
+		"deadline exceeded": {status.Error(codes.DeadlineExceeded, "x"), http.StatusServiceUnavailable},
+		"context deadline":  {context.DeadlineExceeded, http.StatusServiceUnavailable},
+		"permission denied": {status.Error(codes.PermissionDenied, "x"), http.StatusInternalServerError},
+		"other":             {errors.New("x"), http.StatusInternalServerError},
+	} {
+		_, err := NewUserDirectory(&userClient{err: tc.err}, 0, 0, 0).Lookup(context.Background(), 7)
+		if se, ok := apperrors.AsStatusError(err); !ok || se.Status != tc.want {
+			t.Errorf("%s: got %v, want status %d", name, err, tc.want)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+)
+
+// GatewayOptions lets GET /api/v1/notifications take the priority filter as severity too.
+func GatewayOptions() []runtime.ServeMuxOption {
+	return []runtime.ServeMuxOption{runtime.WithMiddlewares(severityAlias)}
+}
+
+// severityAlias renames the severity query parameter to priority, unless priority is set
+// as well. The gateway ignores query parameters that match no request field, so other
+// routes are unaffected.
+func severityAlias(next runtime.HandlerFunc) runtime.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request, params map[string]string) {
+		q := r.URL.Query()
+		if q.Has("severity") {
+			if !q.Has("priority") {
+				q["priority"] = q["severity"]
+			}
+			q.Del("severity")
+			r.URL.RawQuery = q.Encode()
+		}
+		next(w, r, params)
+	}
+}
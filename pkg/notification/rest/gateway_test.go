@@ -0,0 +1,50 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	notificationpb "github.com/emorenkov/scorehub/pkg/notification/proto"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+)
+
+// listServer records the ListNotifications request the gateway built.
+type listServer struct {
+	notificationpb.UnimplementedNotificationServiceServer
+	req *notificationpb.ListNotificationsRequest
+}
+
+func (s *listServer) ListNotifications(_ context.Context, req *notificationpb.ListNotificationsRequest) (*notificationpb.ListNotificationsResponse, error) {
+	s.req = req
+	return &notificationpb.ListNotificationsResponse{}, nil
+}
+
+func TestSeverityIsAnAliasOfPriority(t *testing.T) {
+	for name, tc := range map[string]struct {
+		query string
+		want  string
+	}{
+		"priority":      {"priority=high", "high"},
+		"severity":      {"severity=low", "low"},
+		"priority wins": {"severity=low&priority=high", "high"},
+		"neither":       {"type=manual", ""},
+	} {
+		t.Run(name, func(t *testing.T) {
+			srv := &listServer{}
+			mux := runtime.NewServeMux(GatewayOptions()...)
+			if err := notificationpb.RegisterNotificationServiceHandlerServer(context.Background(), mux, srv); err != nil {
+				t.Fatal(err)
+			}
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/notifications?"+tc.query, nil))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status %d: %s", rec.Code, rec.Body)
+			}
+			if srv.req.GetPriority() != tc.want {
+				t.Fatalf("priority %q, want %q", srv.req.GetPriority(), tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package rest
+
+import (
+	"net/http"
+
+	logpkg "github.com/emorenkov/scorehub/pkg/common/logger"
+	"github.com/emorenkov/scorehub/pkg/notification"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+type ruleRequest struct {
+	Name        string                      `json:"name"`
+	Description string                      `json:"description"`
+	Enabled     *bool                       `json:"enabled"`
+	Position    int                         `json:"position"`
+	Conditions  notification.RuleConditions `json:"conditions"`
+	Action      notification.RuleAction     `json:"action"`
+}
+
+func (r *ruleRequest) toRule() *notification.Rule {
+	enabled := true
+	if r.Enabled != nil {
+		enabled = *r.Enabled
+	}
+	return &notification.Rule{
+		Name:        r.Name,
+		Description: r.Description,
+		Enabled:     enabled,
+		Position:    r.Position,
+		Conditions:  r.Conditions,
+		Action:      r.Action,
+	}
+}
+
+func (s *Server) createRule(c echo.Context) error {
+	log := logpkg.FromContext(c.Request().Context())
+	var req ruleRequest
+	if err := c.Bind(&req); err != nil {
+		log.Error("createRule invalid json", zap.Error(err))
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid json"})
+	}
+	rule, err := s.rules.Create(c.Request().Context(), req.toRule())
+	if err != nil {
+		log.Error("createRule failed", zap.Error(err), zap.String("name", req.Name))
+		if handled := writeServiceError(c, err); handled {
+			return nil
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	log.Info("createRule succeeded", zap.Int64("rule_id", rule.ID))
+	return c.JSON(http.StatusCreated, rule)
+}
+
+func (s *Server) getRule(c echo.Context) error {
+	log := logpkg.FromContext(c.Request().Context())
+	id, ok := parseID(c)
+	if !ok {
+		log.Error("getRule invalid id")
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	rule, err := s.rules.Get(c.Request().Context(), id)
+	if err != nil {
+		log.Error("getRule failed", zap.Error(err), zap.Int64("rule_id", id))
+		if handled := writeServiceError(c, err); handled {
+			return nil
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, rule)
+}
+
+func (s *Server) listRules(c echo.Context) error {
+	log := logpkg.FromContext(c.Request().Context())
+	list, err := s.rules.List(c.Request().Context())
+	if err != nil {
+		log.Error("listRules failed", zap.Error(err))
+		if handled := writeServiceError(c, err); handled {
+			return nil
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	if list == nil {
+		list = []notification.Rule{}
+	}
+	return c.JSON(http.StatusOK, list)
+}
+
+func (s *Server) updateRule(c echo.Context) error {
+	log := logpkg.FromContext(c.Request().Context())
+	id, ok := parseID(c)
+	if !ok {
+		log.Error("updateRule invalid id")
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	var req ruleRequest
+	if err := c.Bind(&req); err != nil {
+		log.Error("updateRule invalid json", zap.Error(err))
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid json"})
+	}
+	rule, err := s.rules.Update(c.Request().Context(), id, req.toRule())
+	if err != nil {
+		log.Error("updateRule failed", zap.Error(err), zap.Int64("rule_id", id))
+		if handled := writeServiceError(c, err); handled {
+			return nil
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	log.Info("updateRule succeeded", zap.Int64("rule_id", id))
+	return c.JSON(http.StatusOK, rule)
+}
+
+func (s *Server) deleteRule(c echo.Context) error {
+	log := logpkg.FromContext(c.Request().Context())
+	id, ok := parseID(c)
+	if !ok {
+		log.Error("deleteRule invalid id")
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid id"})
+	}
+	if err := s.rules.Delete(c.Request().Context(), id); err != nil {
+		log.Error("deleteRule failed", zap.Error(err), zap.Int64("rule_id", id))
+		if handled := writeServiceError(c, err); handled {
+			return nil
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	log.Info("deleteRule succeeded", zap.Int64("rule_id", id))
+	return c.NoContent(http.StatusNoContent)
+}
+
+// dryRunRules evaluates a sample score event against the enabled rules.
+func (s *Server) dryRunRules(c echo.Context) error {
+	log := logpkg.FromContext(c.Request().Context())
+	var ev notification.ScoreEvent
+	if err := c.Bind(&ev); err != nil {
+		log.Error("dryRunRules invalid json", zap.Error(err))
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid json"})
+	}
+	matches, err := s.rules.DryRun(c.Request().Context(), &ev)
+	if err != nil {
+		log.Error("dryRunRules failed", zap.Error(err))
+		if handled := writeServiceError(c, err); handled {
+			return nil
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, map[string]any{"matches": matches})
+}
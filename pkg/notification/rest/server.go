@@ -2,8 +2,18 @@ package rest
 
 import (
 	"context"
-	"net/http"
+	"crypto/tls"
 
+	"github.com/emorenkov/scorehub/pkg/common/apikeys"
+	"github.com/emorenkov/scorehub/pkg/common/auth"
+	"github.com/emorenkov/scorehub/pkg/common/dlq"
+	"github.com/emorenkov/scorehub/pkg/common/gateway"
+	"github.com/emorenkov/scorehub/pkg/common/health"
+	"github.com/emorenkov/scorehub/pkg/common/metrics"
+	"github.com/emorenkov/scorehub/pkg/common/ratelimit"
+	"github.com/emorenkov/scorehub/pkg/common/requestid"
+	"github.com/emorenkov/scorehub/pkg/common/tlsx"
+	"github.com/emorenkov/scorehub/pkg/common/tracing"
 	"github.com/emorenkov/scorehub/pkg/notification/config"
 	"github.com/emorenkov/scorehub/pkg/notification/service"
 	"github.com/labstack/echo/v4"
@@ -12,60 +22,111 @@ import (
 )
 
 type Server struct {
-	cfg *config.Config
-	svc service.Notification
-	log *zap.Logger
-	e   *echo.Echo
+	cfg     *config.Config
+	gw      *gateway.Handler
+	svc     service.Notification
+	rules   service.Rules
+	dlq     *dlq.Handler
+	keys    *apikeys.Handler
+	authn   *auth.Authenticator
+	limiter *ratelimit.Limiter
+	checks  *health.Registry
+	tls     *tls.Config
+	log     *zap.Logger
+	e       *echo.Echo
 }
 
-func NewServer(cfg *config.Config, svc service.Notification, log *zap.Logger) *Server {
+// Deps are the collaborators of the REST server. All may be nil.
+type Deps struct {
+	Config *config.Config
+	// Gateway serves the NotificationService RPCs, which the gRPC server authenticates
+	// and rate limits.
+	Gateway *gateway.Handler
+	// Service backs the SSE and WebSocket streams and Rules the rule admin routes.
+	Service service.Notification
+	Rules   service.Rules
+	// DeadLetters and Keys mount the dead-letter and API key admin routes.
+	DeadLetters *dlq.Handler
+	Keys        *apikeys.Handler
+	// Authn guards the Echo routes under /api/v1.
+	Authn   *auth.Authenticator
+	Limiter *ratelimit.Limiter
+	// Checks backs /livez and /readyz.
+	Checks *health.Registry
+	// TLS enables HTTPS.
+	TLS *tls.Config
+	Log *zap.Logger
+}
+
+// NewServer builds the REST server from d.
+func NewServer(d Deps) *Server {
 	e := echo.New()
 	e.HideBanner = true
 	e.HidePort = true
+	e.IPExtractor = echo.ExtractIPDirect()
 
 	s := &Server{
-		cfg: cfg,
-		svc: svc,
-		log: log,
-		e:   e,
+		cfg:     d.Config,
+		gw:      d.Gateway,
+		svc:     d.Service,
+		rules:   d.Rules,
+		dlq:     d.DeadLetters,
+		keys:    d.Keys,
+		authn:   d.Authn,
+		limiter: d.Limiter,
+		checks:  d.Checks,
+		tls:     d.TLS,
+		log:     d.Log,
+		e:       e,
 	}
 
 	e.Use(echoMiddleware.Recover())
+	e.Use(requestid.EchoMiddleware())
+	e.Use(tracing.EchoMiddleware())
+	e.Use(metrics.EchoMiddleware())
 
 	s.registerRoutes()
 	return s
 }
 
 func (s *Server) registerRoutes() {
-	s.e.GET("/_health", func(c echo.Context) error {
-		return c.NoContent(http.StatusOK)
-	})
+	metrics.Register(s.e)
+	if s.checks != nil {
+		s.checks.Register(s.e)
+	}
+
+	if s.gw != nil {
+		s.gw.Register(s.e)
+	}
+
+	api := s.e.Group(gateway.Prefix,
+		s.limiter.EchoMiddleware(ratelimit.BeforeAuth), s.authn.EchoMiddleware(), s.limiter.EchoMiddleware(ratelimit.AfterAuth))
+	read := auth.Scopes(auth.ScopeNotificationsRead)
+	api.GET("/users/:id/notifications/stream", s.streamNotifications, read)
+	api.GET("/users/:id/notifications/ws", s.notificationsWebSocket, read)
 
-	api := s.e.Group("/api/v1", s.keyAuthMiddleware)
-	api.POST("/notifications", s.createNotification)
-	api.GET("/notifications", s.listNotifications)
-	api.GET("/notifications/:id", s.getNotification)
+	admin := api.Group("", auth.Scopes(auth.ScopeAdmin))
+	admin.POST("/rules", s.createRule)
+	admin.GET("/rules", s.listRules)
+	admin.POST("/rules/dry-run", s.dryRunRules)
+	admin.GET("/rules/:id", s.getRule)
+	admin.PUT("/rules/:id", s.updateRule)
+	admin.DELETE("/rules/:id", s.deleteRule)
+	if s.dlq != nil {
+		s.dlq.Register(admin)
+	}
+	if s.keys != nil {
+		s.keys.Register(admin)
+	}
 }
 
 func (s *Server) Serve() error {
 	addr := ":" + s.cfg.HTTPPort
-	s.log.Info("starting REST server", zap.String("addr", addr))
-	return s.e.Start(addr)
+	s.log.Info("starting REST server", zap.String("addr", addr), zap.Bool("tls", s.tls != nil))
+	return tlsx.StartEcho(s.e, addr, s.tls)
 }
 
 func (s *Server) Shutdown(ctx context.Context) error {
 	s.log.Info("shutting down REST server")
 	return s.e.Shutdown(ctx)
 }
-
-func (s *Server) keyAuthMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
-	if s.cfg.APIKey == "" {
-		return next
-	}
-	return func(c echo.Context) error {
-		if c.Request().Header.Get("X-API-Key") != s.cfg.APIKey {
-			return c.NoContent(http.StatusUnauthorized)
-		}
-		return next(c)
-	}
-}
@@ -0,0 +1,138 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	logpkg "github.com/emorenkov/scorehub/pkg/common/logger"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+	"golang.org/x/net/websocket"
+)
+
+// streamPingInterval keeps idle SSE connections open through proxies.
+const streamPingInterval = 15 * time.Second
+
+// streamNotifications serves a user's new notifications as Server-Sent Events, in commit
+// order. Each event ID is the notification's ID, so a reconnecting EventSource resumes
+// after the last notification it received from its Last-Event-ID header; the
+// last_event_id query parameter does the same for a first connection.
+func (s *Server) streamNotifications(c echo.Context) error {
+	log := logpkg.FromContext(c.Request().Context())
+	userID, afterID, ok := parseStreamParams(c)
+	if !ok {
+		log.Error("streamNotifications invalid user id or last event id")
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid user id or last event id"})
+	}
+	events, err := s.svc.Subscribe(c.Request().Context(), userID, afterID)
+	if err != nil {
+		log.Error("streamNotifications failed", zap.Error(err), zap.Int64("user_id", userID))
+		if handled := writeServiceError(c, err); handled {
+			return nil
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	log.Info("streamNotifications started", zap.Int64("user_id", userID), zap.Int64("last_event_id", afterID))
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set(echo.HeaderCacheControl, "no-cache")
+	res.Header().Set(echo.HeaderConnection, "keep-alive")
+	res.Header().Set("X-Accel-Buffering", "no")
+	res.WriteHeader(http.StatusOK)
+	res.Flush()
+
+	ping := time.NewTicker(streamPingInterval)
+	defer ping.Stop()
+	for {
+		select {
+		case n, ok := <-events:
+			if !ok {
+				return nil
+			}
+			data, err := json.Marshal(toDTO(&n))
+			if err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintf(res, "id: %d\nevent: notification\ndata: %s\n\n", n.ID, data); err != nil {
+				return nil
+			}
+		case <-ping.C:
+			if _, err := io.WriteString(res, ": ping\n\n"); err != nil {
+				return nil
+			}
+		}
+		res.Flush()
+	}
+}
+
+// notificationsWebSocket is the WebSocket counterpart of streamNotifications: each new
+// notification is sent as a JSON text message. Browsers cannot set Last-Event-ID on a
+// WebSocket, so clients resume with last_event_id set to the ID of the last notification
+// they received.
+func (s *Server) notificationsWebSocket(c echo.Context) error {
+	log := logpkg.FromContext(c.Request().Context())
+	userID, afterID, ok := parseStreamParams(c)
+	if !ok {
+		log.Error("notificationsWebSocket invalid user id or last event id")
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid user id or last event id"})
+	}
+	// The request context is not cancelled when a hijacked connection closes.
+	ctx, cancel := context.WithCancel(c.Request().Context())
+	defer cancel()
+	events, err := s.svc.Subscribe(ctx, userID, afterID)
+	if err != nil {
+		log.Error("notificationsWebSocket failed", zap.Error(err), zap.Int64("user_id", userID))
+		if handled := writeServiceError(c, err); handled {
+			return nil
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	log.Info("notificationsWebSocket started", zap.Int64("user_id", userID), zap.Int64("last_event_id", afterID))
+
+	websocket.Server{
+		// Callers authenticate with a bearer token or API key rather than cookies, so
+		// the origin is not checked.
+		Handshake: func(*websocket.Config, *http.Request) error { return nil },
+		Handler: func(ws *websocket.Conn) {
+			defer ws.Close()
+			// Incoming messages are ignored; a read error means the client went away.
+			go func() {
+				defer cancel()
+				_, _ = io.Copy(io.Discard, ws)
+			}()
+			for n := range events {
+				if err := websocket.JSON.Send(ws, toDTO(&n)); err != nil {
+					return
+				}
+			}
+		},
+	}.ServeHTTP(c.Response(), c.Request())
+	return nil
+}
+
+// parseStreamParams reads the user ID and the ID to resume after, from the Last-Event-ID
+// header or the last_event_id query parameter (0 when neither is set).
+func parseStreamParams(c echo.Context) (int64, int64, bool) {
+	userID, ok := parseID(c)
+	if !ok {
+		return 0, 0, false
+	}
+	v := c.Request().Header.Get("Last-Event-ID")
+	if v == "" {
+		v = c.QueryParam("last_event_id")
+	}
+	if v == "" {
+		return userID, 0, true
+	}
+	afterID, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || afterID < 0 {
+		return 0, 0, false
+	}
+	return userID, afterID, true
+}
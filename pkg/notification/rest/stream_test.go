@@ -0,0 +1,118 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/emorenkov/scorehub/pkg/common/gateway"
+	"github.com/emorenkov/scorehub/pkg/notification"
+	"github.com/emorenkov/scorehub/pkg/notification/config"
+	"github.com/emorenkov/scorehub/pkg/notification/service"
+	"go.uber.org/zap"
+)
+
+// streamService records the ID a subscription resumes after and streams its notifications.
+type streamService struct {
+	service.Notification
+	userID, afterID int64
+	notifications   []notification.Notification
+}
+
+func (s *streamService) Subscribe(_ context.Context, userID, afterID int64) (<-chan notification.Notification, error) {
+	s.userID, s.afterID = userID, afterID
+	ch := make(chan notification.Notification, len(s.notifications))
+	for _, n := range s.notifications {
+		ch <- n
+	}
+	close(ch)
+	return ch, nil
+}
+
+func stream(t *testing.T, svc *streamService, target string, header http.Header) *httptest.ResponseRecorder {
+	t.Helper()
+	// A gateway without services: the stream routes must win over its /api/v1/* mount.
+	gw, err := gateway.New(gateway.Config{})
+	if err != nil {
+		t.Fatalf("gateway: %v", err)
+	}
+	t.Cleanup(gw.Close)
+	srv := NewServer(Deps{Config: &config.Config{}, Gateway: gw, Service: svc, Log: zap.NewNop()})
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	rec := httptest.NewRecorder()
+	srv.e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestStreamResumesFromLastEventID(t *testing.T) {
+	svc := &streamService{notifications: []notification.Notification{{ID: 7, UserID: 3}, {ID: 9, UserID: 3}}}
+	rec := stream(t, svc, "/api/v1/users/3/notifications/stream?last_event_id=2",
+		http.Header{"Last-Event-Id": {"5"}})
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("status %d, content type %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	if svc.userID != 3 || svc.afterID != 5 {
+		t.Fatalf("subscribed user %d after %d, want user 3 after the Last-Event-ID 5", svc.userID, svc.afterID)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{"id: 7\nevent: notification\ndata: {", "id: 9\nevent: notification\n"} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("body lacks %q:\n%s", want, body)
+		}
+	}
+}
+
+func TestStreamResumesFromQueryParameter(t *testing.T) {
+	svc := &streamService{}
+	if rec := stream(t, svc, "/api/v1/users/3/notifications/stream?last_event_id=4", nil); rec.Code != http.StatusOK {
+		t.Fatalf("status %d", rec.Code)
+	}
+	if svc.afterID != 4 {
+		t.Fatalf("subscribed after %d, want 4", svc.afterID)
+	}
+	if stream(t, svc, "/api/v1/users/3/notifications/stream", nil); svc.afterID != 0 {
+		t.Fatalf("subscribed after %d without a resume ID, want 0", svc.afterID)
+	}
+}
+
+// Notifications arrive in commit order, so IDs may go down; each event ID is the ID of its
+// notification, which is what a reconnect resumes after.
+func TestStreamEventIDIsTheNotificationID(t *testing.T) {
+	svc := &streamService{notifications: []notification.Notification{{ID: 8}, {ID: 6}, {ID: 10}}}
+	body := stream(t, svc, "/api/v1/users/3/notifications/stream", http.Header{"Last-Event-Id": {"5"}}).Body.String()
+	var ids []string
+	for _, line := range strings.Split(body, "\n") {
+		if id, ok := strings.CutPrefix(line, "id: "); ok {
+			ids = append(ids, id)
+		}
+	}
+	if strings.Join(ids, ",") != "8,6,10" {
+		t.Fatalf("event IDs = %v, want 8,6,10", ids)
+	}
+}
+
+func TestStreamRejectsInvalidLastEventID(t *testing.T) {
+	for _, v := range []string{"abc", "-1"} {
+		svc := &streamService{}
+		rec := stream(t, svc, "/api/v1/users/3/notifications/stream", http.Header{"Last-Event-Id": {v}})
+		if rec.Code != http.StatusBadRequest || svc.userID != 0 {
+			t.Errorf("Last-Event-ID %q: status %d, subscribed %v", v, rec.Code, svc.userID != 0)
+		}
+	}
+}
+
+func TestNeighbouringPathsReachTheGateway(t *testing.T) {
+	svc := &streamService{}
+	rec := stream(t, svc, "/api/v1/users/3/notifications/unread-count", nil)
+	if rec.Code != http.StatusNotFound || !strings.Contains(rec.Body.String(), `"code"`) {
+		t.Fatalf("status %d, body %s; want the gateway's 404", rec.Code, rec.Body)
+	}
+	if svc.userID != 0 {
+		t.Fatal("the stream handler served a gateway path")
+	}
+}
@@ -0,0 +1,85 @@
+package notification
+
+import "time"
+
+// Delivery channels a rule can route a notification to.
+const (
+	ChannelInApp = "in_app"
+	ChannelEmail = "email"
+)
+
+// Notification priorities.
+const (
+	PriorityLow    = "low"
+	PriorityNormal = "normal"
+	PriorityHigh   = "high"
+)
+
+// Band transition directions for RuleConditions.BandDirection.
+const (
+	BandDirectionAny  = "any"
+	BandDirectionUp   = "up"
+	BandDirectionDown = "down"
+)
+
+// Rule turns matching score events into notifications. Enabled rules are evaluated
+// in ascending Position (then ID) order and every matching rule fires.
+type Rule struct {
+	ID          int64  `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name        string `gorm:"size:255;not null" json:"name"`
+	Description string `gorm:"type:text" json:"description"`
+	// Enabled carries no GORM default: GORM would replace a false value with it on Create.
+	Enabled    bool           `gorm:"not null" json:"enabled"`
+	Position   int            `gorm:"not null;default:0" json:"position"`
+	Conditions RuleConditions `gorm:"type:jsonb;serializer:json;not null" json:"conditions"`
+	Action     RuleAction     `gorm:"type:jsonb;serializer:json;not null" json:"action"`
+	CreatedAt  time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt  time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName keeps the table name explicit to match the SQL schema.
+func (Rule) TableName() string {
+	return "notification_rules"
+}
+
+// RuleConditions are ANDed together; unset conditions always match.
+type RuleConditions struct {
+	MinChange   *int32 `json:"min_change,omitempty"`
+	MaxChange   *int32 `json:"max_change,omitempty"`
+	MinNewScore *int64 `json:"min_new_score,omitempty"`
+	MaxNewScore *int64 `json:"max_new_score,omitempty"`
+	// FromBands and ToBands restrict the band before and after the event.
+	FromBands []string `json:"from_bands,omitempty"`
+	ToBands   []string `json:"to_bands,omitempty"`
+	// BandDirection requires the event to cross into another band: any, up or down.
+	BandDirection string   `json:"band_direction,omitempty"`
+	EventTypes    []string `json:"event_types,omitempty"`
+}
+
+// RuleAction describes the notification produced by a matching rule. Template is a
+// text/template rendered with RuleContext.
+type RuleAction struct {
+	Template string `json:"template"`
+	Channel  string `json:"channel"`
+	Priority string `json:"priority"`
+}
+
+// RuleContext is the data available to rule templates.
+type RuleContext struct {
+	UserID    int64
+	OldScore  int64
+	NewScore  int64
+	Change    int32
+	OldBand   string
+	NewBand   string
+	EventType string
+}
+
+// RuleMatch is a rule that matched an event together with its rendered message.
+type RuleMatch struct {
+	RuleID   int64  `json:"rule_id"`
+	RuleName string `json:"rule_name"`
+	Message  string `json:"message"`
+	Channel  string `json:"channel"`
+	Priority string `json:"priority"`
+}
@@ -0,0 +1,162 @@
+// Package rules evaluates notification rules against score events.
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/emorenkov/scorehub/pkg/common/models"
+	"github.com/emorenkov/scorehub/pkg/notification"
+)
+
+// bandRank orders score bands from lowest to highest.
+var bandRank = map[string]int{
+	models.BandPoor:        0,
+	models.BandFair:        1,
+	models.BandGood:        2,
+	models.BandVeryGood:    3,
+	models.BandExceptional: 4,
+}
+
+// NewContext derives the template and matching context for ev.
+func NewContext(ev *notification.ScoreEvent) notification.RuleContext {
+	old := ev.OldScore()
+	return notification.RuleContext{
+		UserID:    ev.UserID,
+		OldScore:  old,
+		NewScore:  ev.NewScore,
+		Change:    ev.Change,
+		OldBand:   models.ScoreBand(old),
+		NewBand:   models.ScoreBand(ev.NewScore),
+		EventType: ev.EventType(),
+	}
+}
+
+// Evaluate returns a match for every rule in rules whose conditions hold for ev, in
+// the order given. Callers pass only the rules they consider active.
+func Evaluate(rules []notification.Rule, ev *notification.ScoreEvent) ([]notification.RuleMatch, error) {
+	ctx := NewContext(ev)
+	matches := make([]notification.RuleMatch, 0)
+	for i := range rules {
+		r := &rules[i]
+		if !Matches(&r.Conditions, ctx) {
+			continue
+		}
+		msg, err := Render(r.Action.Template, ctx)
+		if err != nil {
+			return nil, fmt.Errorf("rule %d: %w", r.ID, err)
+		}
+		matches = append(matches, notification.RuleMatch{
+			RuleID:   r.ID,
+			RuleName: r.Name,
+			Message:  msg,
+			Channel:  r.Action.Channel,
+			Priority: r.Action.Priority,
+		})
+	}
+	return matches, nil
+}
+
+// Matches reports whether every set condition holds for ctx.
+func Matches(c *notification.RuleConditions, ctx notification.RuleContext) bool {
+	if c.MinChange != nil && ctx.Change < *c.MinChange {
+		return false
+	}
+	if c.MaxChange != nil && ctx.Change > *c.MaxChange {
+		return false
+	}
+	if c.MinNewScore != nil && ctx.NewScore < *c.MinNewScore {
+		return false
+	}
+	if c.MaxNewScore != nil && ctx.NewScore > *c.MaxNewScore {
+		return false
+	}
+	if len(c.FromBands) > 0 && !slices.Contains(c.FromBands, ctx.OldBand) {
+		return false
+	}
+	if len(c.ToBands) > 0 && !slices.Contains(c.ToBands, ctx.NewBand) {
+		return false
+	}
+	if len(c.EventTypes) > 0 && !slices.Contains(c.EventTypes, ctx.EventType) {
+		return false
+	}
+	switch c.BandDirection {
+	case notification.BandDirectionAny:
+		return ctx.OldBand != ctx.NewBand
+	case notification.BandDirectionUp:
+		return bandRank[ctx.NewBand] > bandRank[ctx.OldBand]
+	case notification.BandDirectionDown:
+		return bandRank[ctx.NewBand] < bandRank[ctx.OldBand]
+	}
+	return true
+}
+
+// Render executes a rule template against ctx.
+func Render(tmpl string, ctx notification.RuleContext) (string, error) {
+	t, err := template.New("rule").Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return "", fmt.Errorf("parse template: %w", err)
+	}
+	var b strings.Builder
+	if err := t.Execute(&b, ctx); err != nil {
+		return "", fmt.Errorf("render template: %w", err)
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+// Validate normalizes r (defaulting channel and priority) and checks its conditions
+// and action, including that the template renders.
+func Validate(r *notification.Rule) error {
+	r.Name = strings.TrimSpace(r.Name)
+	if r.Name == "" {
+		return errors.New("name is required")
+	}
+
+	c := &r.Conditions
+	if c.MinChange != nil && c.MaxChange != nil && *c.MinChange > *c.MaxChange {
+		return errors.New("min_change must not exceed max_change")
+	}
+	if c.MinNewScore != nil && c.MaxNewScore != nil && *c.MinNewScore > *c.MaxNewScore {
+		return errors.New("min_new_score must not exceed max_new_score")
+	}
+	for _, b := range append(slices.Clone(c.FromBands), c.ToBands...) {
+		if _, ok := bandRank[b]; !ok {
+			return fmt.Errorf("unknown band %q", b)
+		}
+	}
+	switch c.BandDirection {
+	case "", notification.BandDirectionAny, notification.BandDirectionUp, notification.BandDirectionDown:
+	default:
+		return fmt.Errorf("band_direction must be one of any, up, down")
+	}
+
+	a := &r.Action
+	if strings.TrimSpace(a.Template) == "" {
+		return errors.New("action.template is required")
+	}
+	if a.Channel == "" {
+		a.Channel = notification.ChannelInApp
+	}
+	switch a.Channel {
+	case notification.ChannelInApp, notification.ChannelEmail:
+	default:
+		return fmt.Errorf("action.channel must be one of in_app, email")
+	}
+	if a.Priority == "" {
+		a.Priority = notification.PriorityNormal
+	}
+	switch a.Priority {
+	case notification.PriorityLow, notification.PriorityNormal, notification.PriorityHigh:
+	default:
+		return fmt.Errorf("action.priority must be one of low, normal, high")
+	}
+
+	sample := NewContext(&notification.ScoreEvent{UserID: 1, NewScore: 700, Change: 10})
+	if _, err := Render(a.Template, sample); err != nil {
+		return fmt.Errorf("action.template: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,221 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/emorenkov/scorehub/pkg/common/auth"
+	apperrors "github.com/emorenkov/scorehub/pkg/common/errors"
+	"github.com/emorenkov/scorehub/pkg/notification"
+	"github.com/emorenkov/scorehub/pkg/notification/repository"
+	"gorm.io/gorm"
+)
+
+// inboxRepo keeps notifications in memory with the repository's read and archive rules.
+type inboxRepo struct {
+	repository.Repository
+	ns      map[int64]*notification.Notification
+	err     error
+	updates int
+}
+
+func newInboxRepo(ns ...notification.Notification) *inboxRepo {
+	r := &inboxRepo{ns: map[int64]*notification.Notification{}}
+	for i := range ns {
+		r.ns[ns[i].ID] = &ns[i]
+	}
+	return r
+}
+
+func (r *inboxRepo) GetByID(_ context.Context, id int64) (*notification.Notification, error) {
+	n, ok := r.ns[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	cp := *n
+	return &cp, nil
+}
+
+func (r *inboxRepo) SetState(ctx context.Context, id int64, read, archived *bool) (*notification.Notification, error) {
+	r.updates++
+	if r.err != nil {
+		return nil, r.err
+	}
+	n, ok := r.ns[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	now := time.Now()
+	if read != nil {
+		n.ReadAt = stamp(*read, n.ReadAt, now)
+	}
+	if archived != nil {
+		n.ArchivedAt = stamp(*archived, n.ArchivedAt, now)
+	}
+	return r.GetByID(ctx, id)
+}
+
+// stamp mirrors stateValue: setting keeps an existing time, clearing drops it.
+func stamp(set bool, current *time.Time, now time.Time) *time.Time {
+	switch {
+	case !set:
+		return nil
+	case current != nil:
+		return current
+	default:
+		return &now
+	}
+}
+
+func (r *inboxRepo) unread(n *notification.Notification, userID int64) bool {
+	return n.UserID == userID && n.ReadAt == nil && n.ArchivedAt == nil
+}
+
+func (r *inboxRepo) MarkAllRead(_ context.Context, userID int64) (int64, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	var marked int64
+	now := time.Now()
+	for _, n := range r.ns {
+		if r.unread(n, userID) {
+			n.ReadAt = &now
+			marked++
+		}
+	}
+	return marked, nil
+}
+
+func (r *inboxRepo) CountUnread(_ context.Context, userID int64) (int64, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	var count int64
+	for _, n := range r.ns {
+		if r.unread(n, userID) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func expectStatus(t *testing.T, err error, want int) {
+	t.Helper()
+	se, ok := apperrors.AsStatusError(err)
+	if !ok || se.Status != want {
+		t.Fatalf("err = %v, want %d", err, want)
+	}
+}
+
+func TestUpdateTransitions(t *testing.T) {
+	repo := newInboxRepo(notification.Notification{ID: 1, UserID: 7})
+	svc := NewNotification(repo, nil, nil, nil, "")
+	ctx := auth.NewContext(context.Background(), &auth.Principal{Subject: "7", Scopes: []string{auth.ScopeNotificationsRead}})
+	yes, no := true, false
+
+	n, err := svc.Update(ctx, 1, &yes, nil)
+	if err != nil || n.ReadAt == nil || n.ArchivedAt != nil {
+		t.Fatalf("read: %+v, %v", n, err)
+	}
+	readAt := *n.ReadAt
+	if n, err = svc.Update(ctx, 1, &yes, &yes); err != nil || !n.ReadAt.Equal(readAt) || n.ArchivedAt == nil {
+		t.Fatalf("read and archive: %+v, %v, want read_at kept", n, err)
+	}
+	if n, err = svc.Update(ctx, 1, nil, &no); err != nil || n.ArchivedAt != nil || n.ReadAt == nil {
+		t.Fatalf("unarchive: %+v, %v", n, err)
+	}
+	if n, err = svc.Update(ctx, 1, &no, nil); err != nil || n.ReadAt != nil {
+		t.Fatalf("unread: %+v, %v", n, err)
+	}
+}
+
+func TestUpdateErrors(t *testing.T) {
+	yes := true
+	owner := auth.NewContext(context.Background(), &auth.Principal{Subject: "7"})
+	for name, tc := range map[string]struct {
+		ctx      context.Context
+		id       int64
+		read     *bool
+		repoErr  error
+		want     int
+		wantSets int
+	}{
+		"nothing to change": {ctx: owner, id: 1, want: http.StatusBadRequest},
+		"invalid id":        {ctx: owner, id: 0, read: &yes, want: http.StatusBadRequest},
+		"missing":           {ctx: owner, id: 2, read: &yes, want: http.StatusNotFound},
+		// Another user's notification is reported missing and left unchanged.
+		"another user": {
+			ctx:  auth.NewContext(context.Background(), &auth.Principal{Subject: "8"}),
+			id:   1,
+			read: &yes,
+			want: http.StatusNotFound,
+		},
+		"repository failure": {ctx: owner, id: 1, read: &yes, repoErr: errors.New("db down"), want: http.StatusInternalServerError, wantSets: 1},
+	} {
+		t.Run(name, func(t *testing.T) {
+			repo := newInboxRepo(notification.Notification{ID: 1, UserID: 7})
+			repo.err = tc.repoErr
+			_, err := NewNotification(repo, nil, nil, nil, "").Update(tc.ctx, tc.id, tc.read, nil)
+			expectStatus(t, err, tc.want)
+			if repo.updates != tc.wantSets {
+				t.Fatalf("SetState called %d times, want %d", repo.updates, tc.wantSets)
+			}
+		})
+	}
+}
+
+func TestMarkAllReadAndUnreadCount(t *testing.T) {
+	archived, read := time.Now(), time.Now()
+	repo := newInboxRepo(
+		notification.Notification{ID: 1, UserID: 7},
+		notification.Notification{ID: 2, UserID: 7},
+		notification.Notification{ID: 3, UserID: 7, ReadAt: &read},
+		notification.Notification{ID: 4, UserID: 7, ArchivedAt: &archived},
+		notification.Notification{ID: 5, UserID: 8},
+	)
+	svc := NewNotification(repo, nil, nil, nil, "")
+	ctx := auth.NewContext(context.Background(), &auth.Principal{Subject: "7"})
+
+	if n, err := svc.UnreadCount(ctx, 7); err != nil || n != 2 {
+		t.Fatalf("unread %d, %v, want 2", n, err)
+	}
+	if n, err := svc.MarkAllRead(ctx, 7); err != nil || n != 2 {
+		t.Fatalf("marked %d, %v, want 2", n, err)
+	}
+	if n, err := svc.UnreadCount(ctx, 7); err != nil || n != 0 {
+		t.Fatalf("unread %d, %v after marking all read, want 0", n, err)
+	}
+	if repo.ns[4].ReadAt != nil || repo.ns[5].ReadAt != nil {
+		t.Fatal("marked an archived or another user's notification read")
+	}
+}
+
+func TestMarkAllReadAndUnreadCountErrors(t *testing.T) {
+	other := auth.NewContext(context.Background(), &auth.Principal{Subject: "8"})
+	for name, tc := range map[string]struct {
+		ctx     context.Context
+		userID  int64
+		repoErr error
+		want    int
+	}{
+		"invalid user":       {ctx: context.Background(), userID: 0, want: http.StatusBadRequest},
+		"another user":       {ctx: other, userID: 7, want: http.StatusForbidden},
+		"repository failure": {ctx: context.Background(), userID: 7, repoErr: errors.New("db down"), want: http.StatusInternalServerError},
+	} {
+		t.Run(name, func(t *testing.T) {
+			repo := newInboxRepo(notification.Notification{ID: 1, UserID: 7})
+			repo.err = tc.repoErr
+			svc := NewNotification(repo, nil, nil, nil, "")
+			_, err := svc.MarkAllRead(tc.ctx, tc.userID)
+			expectStatus(t, err, tc.want)
+			_, err = svc.UnreadCount(tc.ctx, tc.userID)
+			expectStatus(t, err, tc.want)
+			if repo.ns[1].ReadAt != nil {
+				t.Fatal("marked the notification read")
+			}
+		})
+	}
+}
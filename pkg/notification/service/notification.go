@@ -1,33 +1,76 @@
 package service
 
 import (
+	"bytes"
 	"context"
-	"fmt"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/emorenkov/scorehub/pkg/common/auth"
 	apperrors "github.com/emorenkov/scorehub/pkg/common/errors"
+	logpkg "github.com/emorenkov/scorehub/pkg/common/logger"
+	"github.com/emorenkov/scorehub/pkg/common/requestid"
+	"github.com/emorenkov/scorehub/pkg/common/tracing"
 	"github.com/emorenkov/scorehub/pkg/notification"
-	"github.com/emorenkov/scorehub/pkg/notification/producer"
 	"github.com/emorenkov/scorehub/pkg/notification/repository"
+	"github.com/emorenkov/scorehub/pkg/notification/rules"
+	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
 type Notification interface {
 	Create(ctx context.Context, userID int64, message string) (*notification.Notification, error)
 	Get(ctx context.Context, id int64) (*notification.Notification, error)
-	List(ctx context.Context, userID int64) ([]notification.Notification, error)
-	ProcessScoreEvent(ctx context.Context, ev *notification.ScoreEvent) (*notification.Notification, error)
+	// List returns a page of notifications, newest first, and the cursor of the next page
+	// (empty on the last one).
+	List(ctx context.Context, q notification.ListQuery) ([]notification.Notification, string, error)
+	// Update marks notification id read or unread and archived or not; nil leaves that
+	// state as it is.
+	Update(ctx context.Context, id int64, read, archived *bool) (*notification.Notification, error)
+	// MarkAllRead marks all of userID's unread notifications read and returns how many it marked.
+	MarkAllRead(ctx context.Context, userID int64) (int64, error)
+	// UnreadCount returns how many of userID's notifications are neither read nor archived.
+	UnreadCount(ctx context.Context, userID int64) (int64, error)
+	// Subscribe streams userID's notifications in commit order, each once, resuming after
+	// notification afterID, or only new ones when afterID is zero: first those already
+	// stored, then each one as it is committed. IDs are assigned before commit, so they
+	// are not strictly increasing. The channel is closed when ctx ends or streaming stops.
+	Subscribe(ctx context.Context, userID, afterID int64) (<-chan notification.Notification, error)
+	// ProcessScoreEvent creates one notification per active rule matching ev, addressed to
+	// the user by first name. Events whose event_id was already processed, or whose user
+	// no longer exists, create nothing.
+	ProcessScoreEvent(ctx context.Context, ev *notification.ScoreEvent) ([]notification.Notification, error)
 }
 
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+
+	streamBatchSize = 100
+	// streamPollInterval re-checks subscribed users in case a wake-up was missed.
+	streamPollInterval = 30 * time.Second
+)
+
 type notificationService struct {
-	repo      repository.Repository
-	publisher producer.Publisher
+	repo       repository.Repository
+	rules      repository.RuleRepository
+	recipients repository.Recipients
+	events     repository.Events
+	topic      string
 }
 
-func NewNotification(repo repository.Repository, publisher producer.Publisher) Notification {
-	return &notificationService{repo: repo, publisher: publisher}
+// NewNotification constructs the service. Score events are matched against the enabled
+// rules in ruleRepo. Recipients supplies the user's name, email and preferences; a nil
+// recipients leaves messages as written and unaddressed. Events drives Subscribe, which
+// is unavailable when it is nil. Each created notification is queued in the outbox for
+// topic; an empty topic disables publishing.
+func NewNotification(repo repository.Repository, ruleRepo repository.RuleRepository, recipients repository.Recipients, events repository.Events, topic string) Notification {
+	return &notificationService{repo: repo, rules: ruleRepo, recipients: recipients, events: events, topic: topic}
 }
 
 func (s *notificationService) Create(ctx context.Context, userID int64, message string) (*notification.Notification, error) {
@@ -35,19 +78,23 @@ func (s *notificationService) Create(ctx context.Context, userID int64, message
 	if userID <= 0 || message == "" {
 		return nil, apperrors.NewStatusError(http.StatusBadRequest, "user_id and message are required")
 	}
+	r, err := s.recipient(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
 	n := &notification.Notification{
-		UserID:  userID,
-		Message: message,
+		UserID:   userID,
+		Message:  message,
+		Channel:  channelFor(r, notification.ChannelEmail),
+		Priority: notification.PriorityNormal,
+		Type:     notification.TypeManual,
 	}
-	if err := s.repo.Create(ctx, n); err != nil {
-		return nil, apperrors.WrapStatus(err, http.StatusInternalServerError, "create notification")
+	out, err := s.outboxMessage(ctx, n, r)
+	if err != nil {
+		return nil, apperrors.WrapStatus(err, http.StatusInternalServerError, "build outbox message")
 	}
-	if s.publisher != nil {
-		_ = s.publisher.Publish(ctx, &notification.NotificationMessage{
-			UserID:    n.UserID,
-			Message:   n.Message,
-			CreatedAt: time.Now().UTC(),
-		})
+	if err := s.repo.Create(ctx, n, out); err != nil {
+		return nil, apperrors.WrapStatus(err, http.StatusInternalServerError, "create notification")
 	}
 	return n, nil
 }
@@ -63,24 +110,329 @@ func (s *notificationService) Get(ctx context.Context, id int64) (*notification.
 		}
 		return nil, apperrors.WrapStatus(err, http.StatusInternalServerError, "get notification")
 	}
+	// Report another user's notification as missing rather than revealing that it exists.
+	if auth.AuthorizeUser(ctx, n.UserID) != nil {
+		return nil, apperrors.NewStatusError(http.StatusNotFound, "notification not found")
+	}
+	return n, nil
+}
+
+func (s *notificationService) List(ctx context.Context, q notification.ListQuery) ([]notification.Notification, string, error) {
+	if !notification.ValidStatus(q.Status) {
+		return nil, "", apperrors.NewStatusError(http.StatusBadRequest, "status must be one of all, inbox, unread, read, archived")
+	}
+	switch q.Priority {
+	case "", notification.PriorityLow, notification.PriorityNormal, notification.PriorityHigh:
+	default:
+		return nil, "", apperrors.NewStatusError(http.StatusBadRequest, "priority must be one of low, normal, high")
+	}
+	if !q.Since.IsZero() && !q.Until.IsZero() && !q.Since.Before(q.Until) {
+		return nil, "", apperrors.NewStatusError(http.StatusBadRequest, "since must be before until")
+	}
+	pageSize := q.PageSize
+	switch {
+	case pageSize < 0:
+		return nil, "", apperrors.NewStatusError(http.StatusBadRequest, "page_size must be non-negative")
+	case pageSize == 0:
+		pageSize = defaultPageSize
+	case pageSize > maxPageSize:
+		pageSize = maxPageSize
+	}
+
+	var err error
+	if q.UserID > 0 {
+		err = auth.AuthorizeUser(ctx, q.UserID)
+	} else {
+		err = auth.AuthorizeAllUsers(ctx)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	filter := notification.ListFilter{
+		UserID:   q.UserID,
+		Status:   q.Status,
+		Type:     strings.TrimSpace(q.Type),
+		Priority: q.Priority,
+		Since:    q.Since,
+		Until:    q.Until,
+		Limit:    pageSize + 1,
+	}
+	if q.Cursor != "" {
+		cursor, err := decodeCursor(q.Cursor)
+		if err != nil {
+			return nil, "", apperrors.NewStatusError(http.StatusBadRequest, "invalid cursor")
+		}
+		filter.After = cursor
+	}
+	notifications, err := s.repo.List(ctx, filter)
+	if err != nil {
+		return nil, "", apperrors.WrapStatus(err, http.StatusInternalServerError, "list notifications")
+	}
+
+	var next string
+	if len(notifications) > pageSize {
+		notifications = notifications[:pageSize]
+		last := notifications[len(notifications)-1]
+		next = encodeCursor(&notification.ListCursor{CreatedAt: last.CreatedAt, ID: last.ID})
+	}
+	return notifications, next, nil
+}
+
+func (s *notificationService) Update(ctx context.Context, id int64, read, archived *bool) (*notification.Notification, error) {
+	if read == nil && archived == nil {
+		return nil, apperrors.NewStatusError(http.StatusBadRequest, "read or archived is required")
+	}
+	// Get checks the notification exists and belongs to the caller.
+	if _, err := s.Get(ctx, id); err != nil {
+		return nil, err
+	}
+	n, err := s.repo.SetState(ctx, id, read, archived)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, apperrors.NewStatusError(http.StatusNotFound, "notification not found")
+		}
+		return nil, apperrors.WrapStatus(err, http.StatusInternalServerError, "update notification")
+	}
 	return n, nil
 }
 
-func (s *notificationService) List(ctx context.Context, userID int64) ([]notification.Notification, error) {
-	notifications, err := s.repo.List(ctx, userID)
+func (s *notificationService) MarkAllRead(ctx context.Context, userID int64) (int64, error) {
+	if userID <= 0 {
+		return 0, apperrors.NewStatusError(http.StatusBadRequest, "invalid user id")
+	}
+	if err := auth.AuthorizeUser(ctx, userID); err != nil {
+		return 0, err
+	}
+	n, err := s.repo.MarkAllRead(ctx, userID)
 	if err != nil {
-		return nil, apperrors.WrapStatus(err, http.StatusInternalServerError, "list notifications")
+		return 0, apperrors.WrapStatus(err, http.StatusInternalServerError, "mark notifications read")
 	}
-	return notifications, nil
+	return n, nil
 }
 
-func (s *notificationService) ProcessScoreEvent(ctx context.Context, ev *notification.ScoreEvent) (*notification.Notification, error) {
+func (s *notificationService) UnreadCount(ctx context.Context, userID int64) (int64, error) {
+	if userID <= 0 {
+		return 0, apperrors.NewStatusError(http.StatusBadRequest, "invalid user id")
+	}
+	if err := auth.AuthorizeUser(ctx, userID); err != nil {
+		return 0, err
+	}
+	n, err := s.repo.CountUnread(ctx, userID)
+	if err != nil {
+		return 0, apperrors.WrapStatus(err, http.StatusInternalServerError, "count unread notifications")
+	}
+	return n, nil
+}
+
+func (s *notificationService) ProcessScoreEvent(ctx context.Context, ev *notification.ScoreEvent) ([]notification.Notification, error) {
 	if ev == nil {
 		return nil, apperrors.NewStatusError(http.StatusBadRequest, "event is required")
 	}
-	if ev.Change <= 10 {
+	if ev.UserID <= 0 {
+		return nil, apperrors.NewStatusError(http.StatusBadRequest, "user_id must be positive")
+	}
+	active, err := s.rules.ListRules(ctx, true)
+	if err != nil {
+		return nil, apperrors.WrapStatus(err, http.StatusInternalServerError, "list rules")
+	}
+	matches, err := rules.Evaluate(active, ev)
+	if err != nil {
+		return nil, apperrors.WrapStatus(err, http.StatusInternalServerError, "evaluate rules")
+	}
+	if len(matches) == 0 {
+		return nil, nil
+	}
+	r, err := s.recipient(ctx, ev.UserID)
+	if err != nil {
+		if se, ok := apperrors.AsStatusError(err); ok && se.Status == http.StatusNotFound {
+			logpkg.FromContext(ctx).Info("skipping score event for unknown user",
+				zap.Int64("user_id", ev.UserID), zap.String("event_id", ev.EventID))
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	ns := make([]*notification.Notification, 0, len(matches))
+	outs := make([]*notification.OutboxMessage, 0, len(matches))
+	for _, m := range matches {
+		n := &notification.Notification{
+			UserID:   ev.UserID,
+			Message:  greet(r, m.Message),
+			Channel:  channelFor(r, m.Channel),
+			Priority: m.Priority,
+			Type:     ev.EventType(),
+			RuleID:   &m.RuleID,
+		}
+		out, err := s.outboxMessage(ctx, n, r)
+		if err != nil {
+			return nil, apperrors.WrapStatus(err, http.StatusInternalServerError, "build outbox message")
+		}
+		ns = append(ns, n)
+		outs = append(outs, out)
+	}
+	duplicate, err := s.repo.CreateForEvent(ctx, ev.EventID, ns, outs)
+	if err != nil {
+		return nil, apperrors.WrapStatus(err, http.StatusInternalServerError, "create notifications")
+	}
+	if duplicate {
+		return nil, nil
+	}
+
+	created := make([]notification.Notification, 0, len(ns))
+	for _, n := range ns {
+		created = append(created, *n)
+	}
+	return created, nil
+}
+
+func (s *notificationService) Subscribe(ctx context.Context, userID, afterID int64) (<-chan notification.Notification, error) {
+	if userID <= 0 || afterID < 0 {
+		return nil, apperrors.NewStatusError(http.StatusBadRequest, "invalid user id or last event id")
+	}
+	if err := auth.AuthorizeUser(ctx, userID); err != nil {
+		return nil, err
+	}
+	if s.events == nil {
+		return nil, apperrors.NewStatusError(http.StatusServiceUnavailable, "notification streaming is not available")
+	}
+	// Subscribe before reading the position so nothing committed in between is missed.
+	wake, cancel := s.events.Subscribe(userID)
+	seq, err := s.repo.StreamSeq(ctx, userID, afterID)
+	if err != nil {
+		cancel()
+		return nil, apperrors.WrapStatus(err, http.StatusInternalServerError, "get stream position")
+	}
+	out := make(chan notification.Notification)
+	go s.stream(ctx, userID, seq, wake, cancel, out)
+	return out, nil
+}
+
+// stream sends userID's notifications positioned after seq to out, reading them whenever
+// wake fires, until ctx ends or wake is closed.
+func (s *notificationService) stream(ctx context.Context, userID, seq int64, wake <-chan struct{}, cancel func(), out chan<- notification.Notification) {
+	defer close(out)
+	defer cancel()
+	poll := time.NewTicker(streamPollInterval)
+	defer poll.Stop()
+	for {
+		ns, err := s.repo.ListStream(ctx, userID, seq, streamBatchSize)
+		if err != nil && ctx.Err() == nil {
+			logpkg.FromContext(ctx).Warn("read streamed notifications failed", zap.Error(err), zap.Int64("user_id", userID))
+		}
+		for _, n := range ns {
+			select {
+			case out <- n:
+				seq = n.StreamSeq
+			case <-ctx.Done():
+				return
+			}
+		}
+		if len(ns) == streamBatchSize {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case _, ok := <-wake:
+			if !ok {
+				return
+			}
+		case <-poll.C:
+		}
+	}
+}
+
+// recipient resolves userID, returning nil when no directory is configured or when
+// user-service is unavailable: the notification is then created unpersonalized and
+// unaddressed, and email-service resolves the address and preferences when it sends it.
+// Only a missing user is an error.
+func (s *notificationService) recipient(ctx context.Context, userID int64) (*notification.Recipient, error) {
+	if s.recipients == nil {
+		return nil, nil
+	}
+	r, err := s.recipients.Lookup(ctx, userID)
+	if se, ok := apperrors.AsStatusError(err); ok && se.Status == http.StatusServiceUnavailable {
+		logpkg.FromContext(ctx).Warn("user-service unavailable, creating unpersonalized notification",
+			zap.Int64("user_id", userID), zap.Error(err))
 		return nil, nil
 	}
-	message := fmt.Sprintf("Congrats! Your score increased to %d (+%d)", ev.NewScore, ev.Change)
-	return s.Create(ctx, ev.UserID, message)
+	return r, err
+}
+
+// greet addresses msg to the recipient by first name ("Hi Anna, ...").
+func greet(r *notification.Recipient, msg string) string {
+	if r == nil {
+		return msg
+	}
+	name := strings.Fields(r.Name)
+	if len(name) == 0 {
+		return msg
+	}
+	return "Hi " + name[0] + ", " + msg
+}
+
+// channelFor delivers in-app instead of by email to users who opted out of email.
+func channelFor(r *notification.Recipient, channel string) string {
+	if r != nil && r.EmailOptOut && channel == notification.ChannelEmail {
+		return notification.ChannelInApp
+	}
+	return channel
+}
+
+// outboxMessage builds the Kafka message the relay will publish for n, addressed to r
+// when it is known.
+func (s *notificationService) outboxMessage(ctx context.Context, n *notification.Notification, r *notification.Recipient) (*notification.OutboxMessage, error) {
+	if s.topic == "" {
+		return nil, nil
+	}
+	now := time.Now().UTC()
+	msg := &notification.NotificationMessage{
+		UserID:    n.UserID,
+		Message:   n.Message,
+		Channel:   n.Channel,
+		Priority:  n.Priority,
+		CreatedAt: now,
+	}
+	if r != nil {
+		msg.Email = r.Email
+	}
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	return &notification.OutboxMessage{
+		Topic:         s.topic,
+		MessageKey:    "user:" + strconv.FormatInt(n.UserID, 10),
+		Payload:       payload,
+		Status:        notification.OutboxPending,
+		NextAttemptAt: now,
+		TraceParent:   tracing.TraceParent(ctx),
+		RequestID:     requestid.FromContext(ctx),
+	}, nil
+}
+
+// encodeCursor serialises a cursor into an opaque, URL-safe token.
+func encodeCursor(c *notification.ListCursor) string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// decodeCursor parses a token from encodeCursor, rejecting tokens that encodeCursor
+// cannot have produced.
+func decodeCursor(token string) (*notification.ListCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	var c notification.ListCursor
+	if err := dec.Decode(&c); err != nil {
+		return nil, err
+	}
+	if c.ID <= 0 || c.CreatedAt.IsZero() || dec.More() {
+		return nil, errors.New("malformed cursor")
+	}
+	return &c, nil
 }
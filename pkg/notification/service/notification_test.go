@@ -0,0 +1,249 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	apperrors "github.com/emorenkov/scorehub/pkg/common/errors"
+	"github.com/emorenkov/scorehub/pkg/notification"
+	"github.com/emorenkov/scorehub/pkg/notification/repository"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	want := &notification.ListCursor{CreatedAt: time.Date(2026, 3, 1, 12, 0, 0, 123456789, time.UTC), ID: 42}
+	got, err := decodeCursor(encodeCursor(want))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != want.ID || !got.CreatedAt.Equal(want.CreatedAt) {
+		t.Fatalf("decoded %+v, want %+v", got, want)
+	}
+}
+
+func TestDecodeCursorRejectsTampering(t *testing.T) {
+	valid := encodeCursor(&notification.ListCursor{CreatedAt: time.Now(), ID: 42})
+	encode := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+	for name, token := range map[string]string{
+		"not base64":     "!!!",
+		"padded":         valid + "==",
+		"truncated":      valid[:len(valid)-4],
+		"not json":       encode("id=42"),
+		"wrong types":    encode(`{"created_at":"2026-03-01T12:00:00Z","id":"42"}`),
+		"unknown field":  encode(`{"created_at":"2026-03-01T12:00:00Z","id":42,"user_id":7}`),
+		"no id":          encode(`{"created_at":"2026-03-01T12:00:00Z"}`),
+		"negative id":    encode(`{"created_at":"2026-03-01T12:00:00Z","id":-1}`),
+		"no created_at":  encode(`{"id":42}`),
+		"trailing value": encode(`{"created_at":"2026-03-01T12:00:00Z","id":42}{}`),
+	} {
+		if c, err := decodeCursor(token); err == nil {
+			t.Errorf("%s: decoded %+v", name, c)
+		}
+	}
+}
+
+// listRepo returns filter.Limit notifications for any filter and records the filter.
+type listRepo struct {
+	repository.Repository
+	filter notification.ListFilter
+}
+
+func (r *listRepo) List(_ context.Context, filter notification.ListFilter) ([]notification.Notification, error) {
+	r.filter = filter
+	ns := make([]notification.Notification, filter.Limit)
+	for i := range ns {
+		ns[i] = notification.Notification{ID: int64(100 - i), UserID: 7, CreatedAt: time.Now()}
+	}
+	return ns, nil
+}
+
+func TestListPagesWithCursor(t *testing.T) {
+	repo := &listRepo{}
+	svc := NewNotification(repo, nil, nil, nil, "")
+	ctx := context.Background()
+
+	page, next, err := svc.List(ctx, notification.ListQuery{UserID: 7, PageSize: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page) != 2 || next == "" {
+		t.Fatalf("got %d notifications and cursor %q", len(page), next)
+	}
+	if _, _, err := svc.List(ctx, notification.ListQuery{UserID: 7, PageSize: 2, Cursor: next}); err != nil {
+		t.Fatal(err)
+	}
+	if repo.filter.After == nil || repo.filter.After.ID != page[1].ID {
+		t.Fatalf("second page filtered after %+v, want ID %d", repo.filter.After, page[1].ID)
+	}
+
+	tampered := base64.RawURLEncoding.EncodeToString([]byte(`{"created_at":"2026-03-01T12:00:00Z","id":0}`))
+	_, _, err = svc.List(ctx, notification.ListQuery{UserID: 7, Cursor: tampered})
+	if se, ok := apperrors.AsStatusError(err); !ok || se.Status != http.StatusBadRequest {
+		t.Fatalf("tampered cursor: err = %v, want 400", err)
+	}
+}
+
+func TestListFiltersByPriority(t *testing.T) {
+	repo := &listRepo{}
+	svc := NewNotification(repo, nil, nil, nil, "")
+	ctx := context.Background()
+
+	if _, _, err := svc.List(ctx, notification.ListQuery{UserID: 7, Priority: notification.PriorityHigh}); err != nil {
+		t.Fatal(err)
+	}
+	if repo.filter.Priority != notification.PriorityHigh {
+		t.Fatalf("filtered by priority %q, want high", repo.filter.Priority)
+	}
+	_, _, err := svc.List(ctx, notification.ListQuery{UserID: 7, Priority: "urgent"})
+	if se, ok := apperrors.AsStatusError(err); !ok || se.Status != http.StatusBadRequest {
+		t.Fatalf("unknown priority: err = %v, want 400", err)
+	}
+}
+
+// createRepo records the notifications and outbox messages it is asked to persist.
+type createRepo struct {
+	repository.Repository
+	ns   []*notification.Notification
+	outs []*notification.OutboxMessage
+}
+
+func (r *createRepo) Create(_ context.Context, n *notification.Notification, out *notification.OutboxMessage) error {
+	r.ns, r.outs = append(r.ns, n), append(r.outs, out)
+	return nil
+}
+
+func (r *createRepo) CreateForEvent(_ context.Context, _ string, ns []*notification.Notification, outs []*notification.OutboxMessage) (bool, error) {
+	r.ns, r.outs = append(r.ns, ns...), append(r.outs, outs...)
+	return false, nil
+}
+
+type ruleList struct {
+	repository.RuleRepository
+	rules []notification.Rule
+}
+
+func (r *ruleList) ListRules(context.Context, bool) ([]notification.Rule, error) {
+	return r.rules, nil
+}
+
+type recipients struct {
+	r   *notification.Recipient
+	err error
+}
+
+func (d *recipients) Lookup(context.Context, int64) (*notification.Recipient, error) {
+	return d.r, d.err
+}
+
+var (
+	anna          = &notification.Recipient{UserID: 7, Name: "Anna Smith", Email: "anna@example.com"}
+	errNoUser     = apperrors.NewStatusError(http.StatusNotFound, "user not found")
+	errUnreach    = apperrors.WrapStatus(errors.New("down"), http.StatusServiceUnavailable, "look up user")
+	errPermission = apperrors.WrapStatus(errors.New("denied"), http.StatusInternalServerError, "look up user")
+	emailRule     = notification.Rule{ID: 3, Action: notification.RuleAction{
+		Template: "your score is {{.NewScore}}", Channel: notification.ChannelEmail, Priority: notification.PriorityHigh,
+	}}
+)
+
+// outboxEmail decodes the address the outbox message is sent to.
+func outboxEmail(t *testing.T, out *notification.OutboxMessage) string {
+	t.Helper()
+	var msg notification.NotificationMessage
+	if err := json.Unmarshal(out.Payload, &msg); err != nil {
+		t.Fatal(err)
+	}
+	return msg.Email
+}
+
+func TestProcessScoreEventPersonalizes(t *testing.T) {
+	for name, tc := range map[string]struct {
+		r                     *notification.Recipient
+		message, channel, out string
+	}{
+		"known user": {anna, "Hi Anna, your score is 90", notification.ChannelEmail, "anna@example.com"},
+		"opted out": {&notification.Recipient{UserID: 7, Name: "Anna", Email: "anna@example.com", EmailOptOut: true},
+			"Hi Anna, your score is 90", notification.ChannelInApp, "anna@example.com"},
+		"no name": {&notification.Recipient{UserID: 7, Email: "anna@example.com"}, "your score is 90", notification.ChannelEmail, "anna@example.com"},
+	} {
+		repo := &createRepo{}
+		svc := NewNotification(repo, &ruleList{rules: []notification.Rule{emailRule}}, &recipients{r: tc.r}, nil, "notifications")
+		created, err := svc.ProcessScoreEvent(context.Background(), &notification.ScoreEvent{UserID: 7, NewScore: 90, Change: 5})
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if len(created) != 1 || created[0].Message != tc.message || created[0].Channel != tc.channel {
+			t.Fatalf("%s: created %+v", name, created)
+		}
+		if got := outboxEmail(t, repo.outs[0]); got != tc.out {
+			t.Errorf("%s: outbox addressed to %q, want %q", name, got, tc.out)
+		}
+	}
+}
+
+func TestProcessScoreEventWithoutUserService(t *testing.T) {
+	repo := &createRepo{}
+	svc := NewNotification(repo, &ruleList{rules: []notification.Rule{emailRule}}, &recipients{err: errUnreach}, nil, "notifications")
+	created, err := svc.ProcessScoreEvent(context.Background(), &notification.ScoreEvent{UserID: 7, NewScore: 90, Change: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(created) != 1 || created[0].Message != "your score is 90" || created[0].Channel != notification.ChannelEmail {
+		t.Fatalf("created %+v, want an unpersonalized email notification", created)
+	}
+	if got := outboxEmail(t, repo.outs[0]); got != "" {
+		t.Fatalf("outbox addressed to %q, want email-service to resolve it", got)
+	}
+}
+
+func TestProcessScoreEventLookupErrors(t *testing.T) {
+	svc := NewNotification(&createRepo{}, &ruleList{rules: []notification.Rule{emailRule}}, &recipients{err: errNoUser}, nil, "")
+	if created, err := svc.ProcessScoreEvent(context.Background(), &notification.ScoreEvent{UserID: 7}); err != nil || created != nil {
+		t.Fatalf("unknown user: got %+v, %v; want the event skipped", created, err)
+	}
+	svc = NewNotification(&createRepo{}, &ruleList{rules: []notification.Rule{emailRule}}, &recipients{err: errPermission}, nil, "")
+	if _, err := svc.ProcessScoreEvent(context.Background(), &notification.ScoreEvent{UserID: 7}); err == nil {
+		t.Fatal("permission error: event processed")
+	}
+}
+
+func TestCreateAddressesRecipient(t *testing.T) {
+	for name, tc := range map[string]struct {
+		users   *recipients
+		status  int
+		channel string
+		email   string
+	}{
+		"known user":   {&recipients{r: anna}, 0, notification.ChannelEmail, "anna@example.com"},
+		"opted out":    {&recipients{r: &notification.Recipient{UserID: 7, EmailOptOut: true}}, 0, notification.ChannelInApp, ""},
+		"unavailable":  {&recipients{err: errUnreach}, 0, notification.ChannelEmail, ""},
+		"unknown user": {&recipients{err: errNoUser}, http.StatusNotFound, "", ""},
+		"other error":  {&recipients{err: errPermission}, http.StatusInternalServerError, "", ""},
+	} {
+		repo := &createRepo{}
+		n, err := NewNotification(repo, nil, tc.users, nil, "notifications").Create(context.Background(), 7, "hello")
+		if tc.status != 0 {
+			if se, ok := apperrors.AsStatusError(err); !ok || se.Status != tc.status {
+				t.Errorf("%s: got %v, want status %d", name, err, tc.status)
+			}
+			if len(repo.ns) != 0 {
+				t.Errorf("%s: persisted %+v", name, repo.ns)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		// Manual notifications keep the message as written.
+		if n.Message != "hello" || n.Channel != tc.channel {
+			t.Errorf("%s: created %+v", name, n)
+		}
+		if got := outboxEmail(t, repo.outs[0]); got != tc.email {
+			t.Errorf("%s: outbox addressed to %q, want %q", name, got, tc.email)
+		}
+	}
+}
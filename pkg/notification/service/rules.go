@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	apperrors "github.com/emorenkov/scorehub/pkg/common/errors"
+	"github.com/emorenkov/scorehub/pkg/notification"
+	"github.com/emorenkov/scorehub/pkg/notification/repository"
+	"github.com/emorenkov/scorehub/pkg/notification/rules"
+	"gorm.io/gorm"
+)
+
+// Rules manages notification rules.
+type Rules interface {
+	Create(ctx context.Context, r *notification.Rule) (*notification.Rule, error)
+	Get(ctx context.Context, id int64) (*notification.Rule, error)
+	List(ctx context.Context) ([]notification.Rule, error)
+	Update(ctx context.Context, id int64, r *notification.Rule) (*notification.Rule, error)
+	Delete(ctx context.Context, id int64) error
+	// DryRun evaluates ev against the enabled rules without creating notifications.
+	DryRun(ctx context.Context, ev *notification.ScoreEvent) ([]notification.RuleMatch, error)
+}
+
+type rulesService struct {
+	repo repository.RuleRepository
+}
+
+func NewRules(repo repository.RuleRepository) Rules {
+	return &rulesService{repo: repo}
+}
+
+func (s *rulesService) Create(ctx context.Context, r *notification.Rule) (*notification.Rule, error) {
+	if r == nil {
+		return nil, apperrors.NewStatusError(http.StatusBadRequest, "rule is required")
+	}
+	if err := rules.Validate(r); err != nil {
+		return nil, apperrors.NewStatusError(http.StatusBadRequest, err.Error())
+	}
+	r.ID = 0
+	if err := s.repo.CreateRule(ctx, r); err != nil {
+		return nil, apperrors.WrapStatus(err, http.StatusInternalServerError, "create rule")
+	}
+	return r, nil
+}
+
+func (s *rulesService) Get(ctx context.Context, id int64) (*notification.Rule, error) {
+	if id <= 0 {
+		return nil, apperrors.NewStatusError(http.StatusBadRequest, "invalid id")
+	}
+	r, err := s.repo.GetRule(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NewStatusError(http.StatusNotFound, "rule not found")
+		}
+		return nil, apperrors.WrapStatus(err, http.StatusInternalServerError, "get rule")
+	}
+	return r, nil
+}
+
+func (s *rulesService) List(ctx context.Context) ([]notification.Rule, error) {
+	list, err := s.repo.ListRules(ctx, false)
+	if err != nil {
+		return nil, apperrors.WrapStatus(err, http.StatusInternalServerError, "list rules")
+	}
+	return list, nil
+}
+
+func (s *rulesService) Update(ctx context.Context, id int64, r *notification.Rule) (*notification.Rule, error) {
+	if id <= 0 {
+		return nil, apperrors.NewStatusError(http.StatusBadRequest, "invalid id")
+	}
+	if r == nil {
+		return nil, apperrors.NewStatusError(http.StatusBadRequest, "rule is required")
+	}
+	if err := rules.Validate(r); err != nil {
+		return nil, apperrors.NewStatusError(http.StatusBadRequest, err.Error())
+	}
+	r.ID = id
+	if err := s.repo.UpdateRule(ctx, r); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NewStatusError(http.StatusNotFound, "rule not found")
+		}
+		return nil, apperrors.WrapStatus(err, http.StatusInternalServerError, "update rule")
+	}
+	return s.Get(ctx, id)
+}
+
+func (s *rulesService) Delete(ctx context.Context, id int64) error {
+	if id <= 0 {
+		return apperrors.NewStatusError(http.StatusBadRequest, "invalid id")
+	}
+	if err := s.repo.DeleteRule(ctx, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperrors.NewStatusError(http.StatusNotFound, "rule not found")
+		}
+		return apperrors.WrapStatus(err, http.StatusInternalServerError, "delete rule")
+	}
+	return nil
+}
+
+func (s *rulesService) DryRun(ctx context.Context, ev *notification.ScoreEvent) ([]notification.RuleMatch, error) {
+	if ev == nil {
+		return nil, apperrors.NewStatusError(http.StatusBadRequest, "event is required")
+	}
+	active, err := s.repo.ListRules(ctx, true)
+	if err != nil {
+		return nil, apperrors.WrapStatus(err, http.StatusInternalServerError, "list rules")
+	}
+	matches, err := rules.Evaluate(active, ev)
+	if err != nil {
+		return nil, apperrors.WrapStatus(err, http.StatusInternalServerError, "evaluate rules")
+	}
+	return matches, nil
+}
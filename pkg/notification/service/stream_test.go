@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/emorenkov/scorehub/pkg/notification"
+	"github.com/emorenkov/scorehub/pkg/notification/repository"
+)
+
+// streamRepo holds the committed notifications of one user, positioned in commit order
+// like notification_streams does.
+type streamRepo struct {
+	repository.Repository
+	mu        sync.Mutex
+	committed []notification.Notification
+	queries   int
+}
+
+func (r *streamRepo) commit(id int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.committed = append(r.committed, notification.Notification{ID: id, UserID: 7, StreamSeq: int64(len(r.committed) + 1)})
+}
+
+func (r *streamRepo) ListStream(_ context.Context, _, afterSeq int64, limit int) ([]notification.Notification, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.queries++
+	var out []notification.Notification
+	for _, n := range r.committed {
+		if n.StreamSeq > afterSeq && len(out) < limit {
+			out = append(out, n)
+		}
+	}
+	return out, nil
+}
+
+func (r *streamRepo) StreamSeq(_ context.Context, _, afterID int64) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if afterID == 0 {
+		return int64(len(r.committed)), nil
+	}
+	for _, n := range r.committed {
+		if n.ID == afterID {
+			return n.StreamSeq, nil
+		}
+	}
+	return 0, nil
+}
+
+// streamEvents wakes every subscriber through one channel.
+type streamEvents struct{ wake chan struct{} }
+
+func (e *streamEvents) Subscribe(int64) (<-chan struct{}, func()) { return e.wake, func() {} }
+
+func newStreamEvents() *streamEvents { return &streamEvents{wake: make(chan struct{}, 1)} }
+
+func (e *streamEvents) notify() {
+	select {
+	case e.wake <- struct{}{}:
+	default:
+	}
+}
+
+func receive(t *testing.T, ch <-chan notification.Notification) notification.Notification {
+	t.Helper()
+	select {
+	case n := <-ch:
+		return n
+	case <-time.After(2 * time.Second):
+		t.Fatal("no notification streamed")
+		return notification.Notification{}
+	}
+}
+
+// expectIDs receives len(want) notifications from ch and checks their IDs, then checks
+// that nothing else is pending.
+func expectIDs(t *testing.T, ch <-chan notification.Notification, want ...int64) {
+	t.Helper()
+	var got []int64
+	for range want {
+		got = append(got, receive(t, ch).ID)
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("streamed %v, want %v", got, want)
+	}
+	select {
+	case n := <-ch:
+		t.Fatalf("streamed %d again or unexpectedly", n.ID)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func TestSubscribeStreamsInCommitOrder(t *testing.T) {
+	repo := &streamRepo{}
+	repo.commit(1)
+	repo.commit(2)
+	events := newStreamEvents()
+	svc := NewNotification(repo, nil, nil, events, "")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch, err := svc.Subscribe(ctx, 7, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 4 commits before 3, which was assigned its ID earlier.
+	repo.commit(4)
+	events.notify()
+	expectIDs(t, ch, 4)
+	repo.commit(3)
+	events.notify()
+	expectIDs(t, ch, 3)
+	repo.commit(5)
+	events.notify()
+	expectIDs(t, ch, 5)
+}
+
+func TestSubscribeResumesAfterNotification(t *testing.T) {
+	repo := &streamRepo{}
+	for _, id := range []int64{1, 2, 4, 3, 5} {
+		repo.commit(id)
+	}
+	svc := NewNotification(repo, nil, nil, newStreamEvents(), "")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// The client last received 4; 3 committed after it and has not been sent yet.
+	ch, err := svc.Subscribe(ctx, 7, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectIDs(t, ch, 3, 5)
+}
+
+func TestSubscribeReadsOncePerWakeUp(t *testing.T) {
+	repo := &streamRepo{}
+	events := newStreamEvents()
+	svc := NewNotification(repo, nil, nil, events, "")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch, err := svc.Subscribe(ctx, 7, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo.commit(1)
+	events.notify()
+	expectIDs(t, ch, 1)
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	// The initial read, the one after the wake-up and none in between.
+	if repo.queries != 2 {
+		t.Fatalf("ran %d queries, want 2", repo.queries)
+	}
+}
+
+// TestSubscribeAcrossReplicas streams from two replicas sharing the database while
+// notifications are committed through both, out of ID order: both streams see every
+// notification once, in the same order.
+func TestSubscribeAcrossReplicas(t *testing.T) {
+	repo := &streamRepo{}
+	replicas := []*streamEvents{newStreamEvents(), newStreamEvents()}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var streams []<-chan notification.Notification
+	for _, events := range replicas {
+		ch, err := NewNotification(repo, nil, nil, events, "").Subscribe(ctx, 7, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		streams = append(streams, ch)
+	}
+
+	ids := []int64{11, 10, 13, 12, 14}
+	for _, id := range ids {
+		repo.commit(id)
+		// Every replica's listener is woken by the commit NOTIFY.
+		for _, events := range replicas {
+			events.notify()
+		}
+	}
+	for _, ch := range streams {
+		expectIDs(t, ch, ids...)
+	}
+}
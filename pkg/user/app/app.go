@@ -2,17 +2,31 @@ package app
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net"
 
+	"github.com/emorenkov/scorehub/pkg/common/apikeys"
+	"github.com/emorenkov/scorehub/pkg/common/auth"
 	"github.com/emorenkov/scorehub/pkg/common/db"
+	"github.com/emorenkov/scorehub/pkg/common/dlq"
+	"github.com/emorenkov/scorehub/pkg/common/gateway"
+	"github.com/emorenkov/scorehub/pkg/common/grpcx"
+	"github.com/emorenkov/scorehub/pkg/common/health"
+	ckafka "github.com/emorenkov/scorehub/pkg/common/kafka"
 	logpkg "github.com/emorenkov/scorehub/pkg/common/logger"
+	"github.com/emorenkov/scorehub/pkg/common/models"
+	"github.com/emorenkov/scorehub/pkg/common/ratelimit"
+	"github.com/emorenkov/scorehub/pkg/common/tlsx"
 	"github.com/emorenkov/scorehub/pkg/user/config"
 	grpcserver "github.com/emorenkov/scorehub/pkg/user/grpc"
+	usermodels "github.com/emorenkov/scorehub/pkg/user/models"
 	userpb "github.com/emorenkov/scorehub/pkg/user/models/proto"
 	"github.com/emorenkov/scorehub/pkg/user/repository"
 	"github.com/emorenkov/scorehub/pkg/user/rest"
 	"github.com/emorenkov/scorehub/pkg/user/service"
+	"github.com/redis/go-redis/v9"
+	"github.com/segmentio/kafka-go"
 	"go.uber.org/zap"
 	"golang.org/x/sync/errgroup"
 	"google.golang.org/grpc"
@@ -23,9 +37,17 @@ import (
 type App struct {
 	cfg          *config.UserConfig
 	db           *gorm.DB
+	redis        *redis.Client
 	restServer   *rest.Server
+	gateway      *gateway.Handler
 	grpcServer   *grpc.Server
 	grpcListener net.Listener
+	grpcHealth   *health.GRPCServer
+	consumer     *ckafka.Consumer
+	deadLetters  *dlq.Forwarder
+	dlqAdmin     *dlq.Admin
+	svc          service.User
+	cancel       context.CancelFunc
 }
 
 // New constructs the application and its dependencies.
@@ -36,32 +58,109 @@ func New(cfg *config.UserConfig) (*App, error) {
 	}
 
 	repo := repository.NewGormRepository(dbConn)
-	svc := service.NewService(repo)
+	redisClient := newRedisClient(cfg.RedisConfig)
+	var cache service.LeaderboardCache
+	if c := repository.NewRedisLeaderboardCache(redisClient, cfg.LeaderboardCacheTTL); c != nil {
+		cache = c
+	}
+	svc := service.NewService(repo, cache)
+
+	checks := health.NewRegistry(0)
+	checks.Add("postgres", health.DB(dbConn))
+	checks.Add("kafka", health.Kafka(cfg.KafkaBrokers))
+	if redisClient != nil {
+		checks.Add("redis", health.Redis(redisClient))
+	}
 
-	restServer, err := rest.NewServer(cfg, svc, logpkg.Log)
+	dlqAdmin := dlq.NewAdmin(cfg.KafkaBrokers)
+	grpcTLS, err := tlsx.Server(cfg.GRPC.TLS)
+	if err != nil {
+		return nil, fmt.Errorf("init grpc tls: %w", err)
+	}
+	gatewayTLS, err := tlsx.SelfClient(cfg.GRPC.TLS)
+	if err != nil {
+		return nil, fmt.Errorf("init gateway tls: %w", err)
+	}
+	httpTLS, err := tlsx.Server(cfg.HTTPTLS)
+	if err != nil {
+		return nil, fmt.Errorf("init http tls: %w", err)
+	}
+
+	var keys auth.KeyLookup
+	var keysHandler *apikeys.Handler
+	if cfg.APIKeys.Enabled {
+		cache, h := apikeys.New(dbConn, cfg.APIKeys)
+		keys, keysHandler = cache, h
+	}
+	authn, err := auth.FromConfig(context.Background(), cfg.APIKey, keys, cfg.Auth)
+	if err != nil {
+		return nil, fmt.Errorf("init auth: %w", err)
+	}
+
+	limiter, err := ratelimit.New(cfg.RateLimit, redisClient)
+	if err != nil {
+		return nil, fmt.Errorf("init rate limiter: %w", err)
+	}
+	gw, err := gateway.New(gateway.Config{
+		GRPCAddr: cfg.UserServiceAddr,
+		GRPCTLS:  gatewayTLS,
+	}, userpb.RegisterUserServiceHandlerFromEndpoint)
+	if err != nil {
+		return nil, fmt.Errorf("init gateway: %w", err)
+	}
+	restServer, err := rest.NewServer(rest.Deps{
+		Config:      cfg,
+		Gateway:     gw,
+		DeadLetters: dlq.NewHandler(dlqAdmin, []string{cfg.ScoreEventsTopic}),
+		Keys:        keysHandler,
+		Authn:       authn,
+		Limiter:     limiter,
+		Checks:      checks,
+		TLS:         httpTLS,
+		Log:         logpkg.Log,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("init rest server: %w", err)
 	}
-	grpcServer := grpc.NewServer()
-	userpb.RegisterUserServiceServer(grpcServer, grpcserver.NewServer(svc, logpkg.Log))
+	grpcServer := grpcx.NewServer(grpcx.ServerConfig{
+		Auth:           grpcx.Auth(authn),
+		Scopes:         grpcserver.MethodScopes,
+		RateLimit:      limiter,
+		DefaultTimeout: cfg.GRPC.DefaultTimeout,
+		TLS:            grpcTLS,
+	})
+	userpb.RegisterUserServiceServer(grpcServer, grpcserver.NewServer(svc))
+	grpcHealth := health.NewGRPCServer(grpcServer, checks, userpb.UserService_ServiceDesc.ServiceName)
 	grpcAddr := ":" + cfg.GRPCPort
 	lis, err := net.Listen("tcp", grpcAddr)
 	if err != nil {
 		return nil, fmt.Errorf("failed to listen on %s: %w", grpcAddr, err)
 	}
 
+	consumer := ckafka.NewConsumerWithBrokers(cfg.KafkaBrokers, cfg.ScoreEventsTopic, cfg.KafkaGroupID)
+
 	return &App{
 		cfg:          cfg,
 		db:           dbConn,
+		redis:        redisClient,
 		restServer:   restServer,
+		gateway:      gw,
 		grpcServer:   grpcServer,
 		grpcListener: lis,
+		grpcHealth:   grpcHealth,
+		consumer:     consumer,
+		deadLetters:  dlq.NewForwarder(cfg.KafkaBrokers, cfg.KafkaGroupID),
+		dlqAdmin:     dlqAdmin,
+		svc:          svc,
 	}, nil
 }
 
-// Run starts HTTP and gRPC servers asynchronously and returns a channel of errors.
+// Run starts HTTP and gRPC servers and the score events consumer asynchronously
+// and returns a channel of errors.
 func (a *App) Run() <-chan error {
-	errCh := make(chan error, 2)
+	errCh := make(chan error, 3)
+	ctx, cancel := context.WithCancel(context.Background())
+	a.cancel = cancel
 
 	go func() {
 		if err := a.restServer.Serve(); err != nil {
@@ -70,20 +169,50 @@ func (a *App) Run() <-chan error {
 	}()
 
 	go func() {
-		logpkg.Log.Info("starting gRPC server", zap.String("addr", ":"+a.cfg.GRPCPort))
+		logpkg.FromContext(ctx).Info("starting gRPC server", zap.String("addr", ":"+a.cfg.GRPCPort))
 		if err := a.grpcServer.Serve(a.grpcListener); err != nil {
 			errCh <- fmt.Errorf("grpc server: %w", err)
 		}
 	}()
 
+	go a.grpcHealth.Run(ctx, 0)
+
+	go func() {
+		logpkg.FromContext(ctx).Info("starting score events consumer", zap.String("topic", a.cfg.ScoreEventsTopic))
+		err := a.consumer.Run(ctx, a.handleScoreEvent, ckafka.RunOptions{
+			Retry:     ckafka.RetryPolicyFromConfig(a.cfg.ConsumerRetry),
+			OnFailure: a.deadLetters.FailureHandler(),
+		})
+		if err != nil {
+			errCh <- fmt.Errorf("consume score event: %w", err)
+		}
+	}()
+
 	return errCh
 }
 
+// handleScoreEvent applies a score event to the user row; client errors (unknown user,
+// invalid payload) are permanent and not retried.
+func (a *App) handleScoreEvent(ctx context.Context, msg kafka.Message) error {
+	var ev usermodels.ScoreEvent
+	if err := json.Unmarshal(msg.Value, &ev); err != nil {
+		return ckafka.Permanent(fmt.Errorf("unmarshal score event: %w", err))
+	}
+	_, err := a.svc.ApplyScoreEvent(ctx, &ev)
+	return err
+}
+
 // Shutdown stops servers and closes shared resources gracefully.
 func (a *App) Shutdown(ctx context.Context) error {
+	if a.cancel != nil {
+		a.cancel()
+	}
+	a.grpcHealth.Shutdown()
+
 	g, ctx := errgroup.WithContext(ctx)
 
 	g.Go(func() error {
+		defer a.gateway.Close()
 		return a.restServer.Shutdown(ctx)
 	})
 
@@ -103,6 +232,25 @@ func (a *App) Shutdown(ctx context.Context) error {
 		}
 	})
 
+	g.Go(func() error {
+		return a.consumer.Close()
+	})
+
+	g.Go(func() error {
+		return a.deadLetters.Close()
+	})
+
+	g.Go(func() error {
+		return a.dlqAdmin.Close()
+	})
+
+	g.Go(func() error {
+		if a.redis != nil {
+			return a.redis.Close()
+		}
+		return nil
+	})
+
 	g.Go(func() error {
 		sqlDB, err := a.db.DB()
 		if err != nil {
@@ -113,3 +261,14 @@ func (a *App) Shutdown(ctx context.Context) error {
 
 	return g.Wait()
 }
+
+func newRedisClient(cfg *models.RedisConfig) *redis.Client {
+	if cfg.RedisAddr == "" {
+		return nil
+	}
+	return redis.NewClient(&redis.Options{
+		Addr:     cfg.RedisAddr,
+		Password: cfg.RedisPassword,
+		DB:       cfg.RedisDB,
+	})
+}
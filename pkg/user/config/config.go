@@ -3,6 +3,8 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/emorenkov/scorehub/pkg/common/models"
 )
@@ -10,28 +12,48 @@ import (
 type UserConfig struct {
 	GRPCPort    string
 	HTTPPort    string
-	GatewayPort string
 	ServiceName string
 	// For grpc-gateway to reach the gRPC server; default is local user-service gRPC port
 	UserServiceAddr string
 	APIKey          string
-	RateLimitRPS    int
-	RateLimitBurst  int
-	RedisConfig     *models.RedisConfig
-	DbConfig        *models.PostgresConfig
+	// Score events are consumed to keep users.score and score_history up to date
+	KafkaBrokers     []string
+	KafkaGroupID     string
+	ScoreEventsTopic string
+	ConsumerRetry    *models.ConsumerRetryConfig
+	// How long leaderboard pages and ranks stay cached in Redis
+	LeaderboardCacheTTL time.Duration
+	RedisConfig         *models.RedisConfig
+	RateLimit           *models.RateLimitConfig
+	DbConfig            *models.PostgresConfig
+	Tracing             *models.TracingConfig
+	// HTTPTLS serves the REST API over HTTPS when a certificate is set.
+	HTTPTLS *models.TLSConfig
+	Auth    *models.AuthConfig
+	APIKeys *models.APIKeysConfig
+	GRPC    *models.GRPCConfig
 }
 
 func Load() *UserConfig {
 	return &UserConfig{
-		DbConfig:        models.LoadPostgresConfig(),
-		RedisConfig:     models.LoadRedisConfig(),
-		GRPCPort:        getEnv("GRPC_PORT", "50051"),
-		HTTPPort:        getEnv("HTTP_PORT", "8080"),
-		GatewayPort:     getEnv("GATEWAY_PORT", "8081"),
-		ServiceName:     getEnv("SERVICE_NAME", "user-service"),
-		UserServiceAddr: getEnv("USER_SERVICE_ADDR", "localhost:50051"),
-		APIKey:          getEnv("API_KEY", ""),
-		RateLimitRPS:    getEnvAsInt("RATE_LIMIT_RPS", 10),
+		ConsumerRetry:       models.LoadConsumerRetryConfig(),
+		DbConfig:            models.LoadPostgresConfig(),
+		RedisConfig:         models.LoadRedisConfig(),
+		RateLimit:           models.LoadRateLimitConfig(),
+		Tracing:             models.LoadTracingConfig(),
+		HTTPTLS:             models.LoadTLSConfig("HTTP_"),
+		Auth:                models.LoadAuthConfig(),
+		APIKeys:             models.LoadAPIKeysConfig(),
+		GRPC:                models.LoadGRPCConfig(),
+		GRPCPort:            getEnv("GRPC_PORT", "50051"),
+		HTTPPort:            getEnv("HTTP_PORT", "8080"),
+		ServiceName:         getEnv("SERVICE_NAME", "user-service"),
+		UserServiceAddr:     getEnv("USER_SERVICE_ADDR", "localhost:50051"),
+		APIKey:              getEnv("API_KEY", ""),
+		KafkaBrokers:        splitAndTrim(getEnv("KAFKA_BROKERS", "localhost:9092")),
+		KafkaGroupID:        getEnv("KAFKA_GROUP_ID", "user-service-scores"),
+		ScoreEventsTopic:    getEnv("SCORE_EVENTS_TOPIC", "score_events"),
+		LeaderboardCacheTTL: time.Duration(getEnvAsInt("LEADERBOARD_CACHE_TTL_SECONDS", 30)) * time.Second,
 	}
 }
 
@@ -49,3 +71,15 @@ func getEnvAsInt(key string, defaultValue int) int {
 	}
 	return defaultValue
 }
+
+func splitAndTrim(s string) []string {
+	parts := strings.Split(s, ",")
+	res := make([]string, 0, len(parts))
+	for _, p := range parts {
+		v := strings.TrimSpace(p)
+		if v != "" {
+			res = append(res, v)
+		}
+	}
+	return res
+}
@@ -6,8 +6,11 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/emorenkov/scorehub/pkg/common/auth"
 	apperrors "github.com/emorenkov/scorehub/pkg/common/errors"
+	logpkg "github.com/emorenkov/scorehub/pkg/common/logger"
 	"github.com/emorenkov/scorehub/pkg/common/models"
+	usermodels "github.com/emorenkov/scorehub/pkg/user/models"
 	userpb "github.com/emorenkov/scorehub/pkg/user/models/proto"
 	"github.com/emorenkov/scorehub/pkg/user/service"
 	"go.uber.org/zap"
@@ -19,88 +22,194 @@ import (
 type Server struct {
 	userpb.UnimplementedUserServiceServer
 	svc service.User
-	log *zap.Logger
 }
 
-func NewServer(svc service.User, log *zap.Logger) *Server {
-	return &Server{svc: svc, log: log}
+func NewServer(svc service.User) *Server {
+	return &Server{svc: svc}
+}
+
+// MethodScopes lists the scopes each RPC requires, for grpcx.ServerConfig.Scopes.
+var MethodScopes = map[string][]string{
+	"/user.UserService/CreateUser":      {auth.ScopeUsersWrite},
+	"/user.UserService/UpdateUser":      {auth.ScopeUsersWrite},
+	"/user.UserService/DeleteUser":      {auth.ScopeUsersWrite},
+	"/user.UserService/GetUser":         {auth.ScopeUsersRead},
+	"/user.UserService/BatchGetUsers":   {auth.ScopeUsersRead},
+	"/user.UserService/ListUsers":       {auth.ScopeUsersRead},
+	"/user.UserService/GetScoreHistory": {auth.ScopeUsersRead},
+	"/user.UserService/GetLeaderboard":  {auth.ScopeUsersRead},
+	"/user.UserService/GetUserRank":     {auth.ScopeUsersRead},
 }
 
 func (s *Server) CreateUser(ctx context.Context, req *userpb.CreateUserRequest) (*userpb.UserResponse, error) {
+	log := logpkg.FromContext(ctx)
 	user, err := s.svc.Create(ctx, req.GetName(), req.GetEmail())
 	if err != nil {
-		if s.log != nil {
-			s.log.Error("grpc CreateUser failed", zap.Error(err), zap.String("email", req.GetEmail()))
-		}
-		return nil, status.Errorf(codes.InvalidArgument, "create user: %v", err)
-	}
-	if s.log != nil {
-		s.log.Info("grpc CreateUser succeeded", zap.Int64("user_id", user.ID))
+		log.Error("grpc CreateUser failed", zap.Error(err), zap.String("email", req.GetEmail()))
+		return nil, mapError(err)
 	}
+	log.Info("grpc CreateUser succeeded", zap.Int64("user_id", user.ID))
 	return &userpb.UserResponse{User: toProtoUser(user)}, nil
 }
 
 func (s *Server) GetUser(ctx context.Context, req *userpb.GetUserRequest) (*userpb.UserResponse, error) {
+	log := logpkg.FromContext(ctx)
 	user, err := s.svc.Get(ctx, req.GetId())
 	if err != nil {
-		if s.log != nil {
-			s.log.Error("grpc GetUser failed", zap.Error(err), zap.Int64("user_id", req.GetId()))
-		}
+		log.Error("grpc GetUser failed", zap.Error(err), zap.Int64("user_id", req.GetId()))
 		return nil, mapError(err)
 	}
-	if s.log != nil {
-		s.log.Info("grpc GetUser succeeded", zap.Int64("user_id", user.ID))
-	}
+	log.Info("grpc GetUser succeeded", zap.Int64("user_id", user.ID))
 	return &userpb.UserResponse{User: toProtoUser(user)}, nil
 }
 
-func (s *Server) UpdateUser(ctx context.Context, req *userpb.UpdateUserRequest) (*userpb.UserResponse, error) {
-	user, err := s.svc.Update(ctx, req.GetId(), req.GetName(), req.GetEmail())
+func (s *Server) BatchGetUsers(ctx context.Context, req *userpb.BatchGetUsersRequest) (*userpb.BatchGetUsersResponse, error) {
+	log := logpkg.FromContext(ctx)
+	users, err := s.svc.GetMany(ctx, req.GetIds())
 	if err != nil {
-		if s.log != nil {
-			s.log.Error("grpc UpdateUser failed", zap.Error(err), zap.Int64("user_id", req.GetId()))
-		}
+		log.Error("grpc BatchGetUsers failed", zap.Error(err), zap.Int("count", len(req.GetIds())))
 		return nil, mapError(err)
 	}
-	if s.log != nil {
-		s.log.Info("grpc UpdateUser succeeded", zap.Int64("user_id", user.ID))
+	resp := &userpb.BatchGetUsersResponse{Users: make([]*userpb.User, 0, len(users))}
+	found := make(map[int64]struct{}, len(users))
+	for i := range users {
+		resp.Users = append(resp.Users, toProtoUser(&users[i]))
+		found[users[i].ID] = struct{}{}
+	}
+	for _, id := range req.GetIds() {
+		if _, ok := found[id]; !ok {
+			resp.MissingIds = append(resp.MissingIds, id)
+			found[id] = struct{}{}
+		}
 	}
+	log.Info("grpc BatchGetUsers succeeded", zap.Int("found", len(resp.Users)), zap.Int("missing", len(resp.MissingIds)))
+	return resp, nil
+}
+
+func (s *Server) UpdateUser(ctx context.Context, req *userpb.UpdateUserRequest) (*userpb.UserResponse, error) {
+	log := logpkg.FromContext(ctx)
+	var prefs *models.UserPreferences
+	if p := req.GetPreferences(); p != nil {
+		prefs = &models.UserPreferences{EmailOptOut: p.GetEmailOptOut()}
+	}
+	user, err := s.svc.Update(ctx, req.GetId(), req.GetName(), req.GetEmail(), prefs)
+	if err != nil {
+		log.Error("grpc UpdateUser failed", zap.Error(err), zap.Int64("user_id", req.GetId()))
+		return nil, mapError(err)
+	}
+	log.Info("grpc UpdateUser succeeded", zap.Int64("user_id", user.ID))
 	return &userpb.UserResponse{User: toProtoUser(user)}, nil
 }
 
 func (s *Server) DeleteUser(ctx context.Context, req *userpb.DeleteUserRequest) (*userpb.Empty, error) {
+	log := logpkg.FromContext(ctx)
 	if err := s.svc.Delete(ctx, req.GetId()); err != nil {
-		if s.log != nil {
-			s.log.Error("grpc DeleteUser failed", zap.Error(err), zap.Int64("user_id", req.GetId()))
-		}
+		log.Error("grpc DeleteUser failed", zap.Error(err), zap.Int64("user_id", req.GetId()))
 		return nil, mapError(err)
 	}
-	if s.log != nil {
-		s.log.Info("grpc DeleteUser succeeded", zap.Int64("user_id", req.GetId()))
-	}
+	log.Info("grpc DeleteUser succeeded", zap.Int64("user_id", req.GetId()))
 	return &userpb.Empty{}, nil
 }
 
-func (s *Server) ListUsers(ctx context.Context, _ *userpb.Empty) (*userpb.ListUsersResponse, error) {
-	users, err := s.svc.List(ctx)
+func (s *Server) ListUsers(ctx context.Context, req *userpb.ListUsersRequest) (*userpb.ListUsersResponse, error) {
+	log := logpkg.FromContext(ctx)
+	createdAfter, err := parseTime(req.GetCreatedAfter())
 	if err != nil {
-		if s.log != nil {
-			s.log.Error("grpc ListUsers failed", zap.Error(err))
-		}
-		return nil, status.Errorf(codes.Internal, "list users: %v", err)
+		return nil, status.Errorf(codes.InvalidArgument, "invalid created_after: %v", err)
+	}
+	q := &usermodels.ListUsersQuery{
+		PageSize:      int(req.GetPageSize()),
+		PageToken:     req.GetPageToken(),
+		EmailContains: req.GetEmailContains(),
+		NameContains:  req.GetNameContains(),
+		MinScore:      req.MinScore,
+		MaxScore:      req.MaxScore,
+		CreatedAfter:  createdAfter,
+		Sort:          fromProtoSort(req.GetSort()),
+	}
+	users, next, err := s.svc.List(ctx, q)
+	if err != nil {
+		log.Error("grpc ListUsers failed", zap.Error(err))
+		return nil, mapError(err)
 	}
 	resp := &userpb.ListUsersResponse{
-		Users: make([]*userpb.User, 0, len(users)),
+		Users:         make([]*userpb.User, 0, len(users)),
+		NextPageToken: next,
 	}
 	for i := range users {
 		resp.Users = append(resp.Users, toProtoUser(&users[i]))
 	}
-	if s.log != nil {
-		s.log.Info("grpc ListUsers succeeded", zap.Int("count", len(resp.Users)))
+	log.Info("grpc ListUsers succeeded", zap.Int("count", len(resp.Users)))
+	return resp, nil
+}
+
+func (s *Server) GetScoreHistory(ctx context.Context, req *userpb.GetScoreHistoryRequest) (*userpb.GetScoreHistoryResponse, error) {
+	log := logpkg.FromContext(ctx)
+	from, err := parseTime(req.GetFrom())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid from: %v", err)
+	}
+	to, err := parseTime(req.GetTo())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid to: %v", err)
+	}
+	history, err := s.svc.GetScoreHistory(ctx, req.GetUserId(), from, to)
+	if err != nil {
+		log.Error("grpc GetScoreHistory failed", zap.Error(err), zap.Int64("user_id", req.GetUserId()))
+		return nil, mapError(err)
+	}
+	resp := &userpb.GetScoreHistoryResponse{
+		Entries: make([]*userpb.ScoreHistoryEntry, 0, len(history)),
+	}
+	for i := range history {
+		resp.Entries = append(resp.Entries, toProtoScoreHistory(&history[i]))
+	}
+	log.Info("grpc GetScoreHistory succeeded", zap.Int64("user_id", req.GetUserId()), zap.Int("count", len(resp.Entries)))
+	return resp, nil
+}
+
+func (s *Server) GetLeaderboard(ctx context.Context, req *userpb.GetLeaderboardRequest) (*userpb.GetLeaderboardResponse, error) {
+	log := logpkg.FromContext(ctx)
+	lb, err := s.svc.GetLeaderboard(ctx, int(req.GetLimit()), int(req.GetOffset()))
+	if err != nil {
+		log.Error("grpc GetLeaderboard failed", zap.Error(err))
+		return nil, mapError(err)
+	}
+	resp := &userpb.GetLeaderboardResponse{
+		Entries:    make([]*userpb.LeaderboardEntry, 0, len(lb.Entries)),
+		TotalUsers: lb.TotalUsers,
 	}
+	for _, e := range lb.Entries {
+		resp.Entries = append(resp.Entries, &userpb.LeaderboardEntry{
+			Rank:   e.Rank,
+			UserId: e.UserID,
+			Name:   e.Name,
+			Score:  e.Score,
+			Band:   e.Band,
+		})
+	}
+	log.Info("grpc GetLeaderboard succeeded", zap.Int("count", len(resp.Entries)))
 	return resp, nil
 }
 
+func (s *Server) GetUserRank(ctx context.Context, req *userpb.GetUserRankRequest) (*userpb.UserRankResponse, error) {
+	log := logpkg.FromContext(ctx)
+	rank, err := s.svc.GetUserRank(ctx, req.GetUserId())
+	if err != nil {
+		log.Error("grpc GetUserRank failed", zap.Error(err), zap.Int64("user_id", req.GetUserId()))
+		return nil, mapError(err)
+	}
+	log.Info("grpc GetUserRank succeeded", zap.Int64("user_id", rank.UserID), zap.Int64("rank", rank.Rank))
+	return &userpb.UserRankResponse{
+		UserId:     rank.UserID,
+		Score:      rank.Score,
+		Rank:       rank.Rank,
+		TotalUsers: rank.TotalUsers,
+		Percentile: rank.Percentile,
+		Band:       rank.Band,
+	}, nil
+}
+
 func toProtoUser(u *models.User) *userpb.User {
 	return &userpb.User{
 		Id:        u.ID,
@@ -109,7 +218,42 @@ func toProtoUser(u *models.User) *userpb.User {
 		Score:     u.Score,
 		CreatedAt: u.CreatedAt.UTC().Format(time.RFC3339),
 		UpdatedAt: u.UpdatedAt.UTC().Format(time.RFC3339),
+		Preferences: &userpb.UserPreferences{
+			EmailOptOut: u.Preferences.EmailOptOut,
+		},
+	}
+}
+
+func toProtoScoreHistory(h *models.ScoreHistory) *userpb.ScoreHistoryEntry {
+	return &userpb.ScoreHistoryEntry{
+		Id:        h.ID,
+		UserId:    h.UserID,
+		OldScore:  h.OldScore,
+		NewScore:  h.NewScore,
+		Change:    h.Change,
+		CreatedAt: h.CreatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+func fromProtoSort(o userpb.UserSortOrder) usermodels.UserSort {
+	switch o {
+	case userpb.UserSortOrder_USER_SORT_ORDER_ID_DESC:
+		return usermodels.SortIDDesc
+	case userpb.UserSortOrder_USER_SORT_ORDER_CREATED_AT_ASC:
+		return usermodels.SortCreatedAtAsc
+	case userpb.UserSortOrder_USER_SORT_ORDER_CREATED_AT_DESC:
+		return usermodels.SortCreatedAtDesc
+	default:
+		return usermodels.SortIDAsc
+	}
+}
+
+// parseTime parses an optional RFC3339 timestamp; empty input yields the zero time.
+func parseTime(v string) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
 	}
+	return time.Parse(time.RFC3339, v)
 }
 
 func mapError(err error) error {
@@ -118,6 +262,10 @@ func mapError(err error) error {
 		switch se.Status {
 		case http.StatusBadRequest:
 			return status.Error(codes.InvalidArgument, se.Message)
+		case http.StatusUnauthorized:
+			return status.Error(codes.Unauthenticated, se.Message)
+		case http.StatusForbidden:
+			return status.Error(codes.PermissionDenied, se.Message)
 		case http.StatusNotFound:
 			return status.Error(codes.NotFound, se.Message)
 		default:
@@ -0,0 +1,12 @@
+package grpcserver
+
+import (
+	"testing"
+
+	"github.com/emorenkov/scorehub/pkg/common/grpcx"
+	userpb "github.com/emorenkov/scorehub/pkg/user/models/proto"
+)
+
+func TestMethodScopesCoverEveryRPC(t *testing.T) {
+	grpcx.CheckScopes(t, userpb.UserService_ServiceDesc, MethodScopes)
+}
@@ -0,0 +1,28 @@
+package models
+
+// LeaderboardEntry is a single ranked user on the leaderboard.
+type LeaderboardEntry struct {
+	Rank   int64  `json:"rank"`
+	UserID int64  `json:"user_id"`
+	Name   string `json:"name"`
+	Score  int64  `json:"score"`
+	Band   string `json:"band"`
+}
+
+// Leaderboard is one page of the leaderboard ordered by score descending.
+type Leaderboard struct {
+	Entries    []LeaderboardEntry `json:"entries"`
+	TotalUsers int64              `json:"total_users"`
+}
+
+// UserRank describes where a user stands among all active users.
+// Rank uses competition ranking (ties share a rank); Percentile is the share of
+// users whose score is less than or equal to the user's score.
+type UserRank struct {
+	UserID     int64   `json:"user_id"`
+	Score      int64   `json:"score"`
+	Rank       int64   `json:"rank"`
+	TotalUsers int64   `json:"total_users"`
+	Percentile float64 `json:"percentile"`
+	Band       string  `json:"band"`
+}
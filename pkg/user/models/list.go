@@ -0,0 +1,61 @@
+package models
+
+import "time"
+
+// UserSort is the ordering applied when listing users.
+type UserSort string
+
+const (
+	SortIDAsc         UserSort = "id_asc"
+	SortIDDesc        UserSort = "id_desc"
+	SortCreatedAtAsc  UserSort = "created_at_asc"
+	SortCreatedAtDesc UserSort = "created_at_desc"
+)
+
+// ParseUserSort validates a sort value; an empty string selects SortIDAsc.
+func ParseUserSort(v string) (UserSort, bool) {
+	switch s := UserSort(v); s {
+	case "":
+		return SortIDAsc, true
+	case SortIDAsc, SortIDDesc, SortCreatedAtAsc, SortCreatedAtDesc:
+		return s, true
+	default:
+		return "", false
+	}
+}
+
+// Desc reports whether the sort is descending.
+func (s UserSort) Desc() bool {
+	return s == SortIDDesc || s == SortCreatedAtDesc
+}
+
+// ListUsersQuery carries pagination, filtering and sorting options for listing users.
+type ListUsersQuery struct {
+	PageSize      int
+	PageToken     string
+	EmailContains string
+	NameContains  string
+	MinScore      *int64
+	MaxScore      *int64
+	CreatedAfter  time.Time
+	Sort          UserSort
+}
+
+// UserCursor is the keyset position of the last user returned on a page.
+type UserCursor struct {
+	ID        int64     `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	Sort      UserSort  `json:"sort"`
+}
+
+// UserFilter is the repository-level form of ListUsersQuery with a decoded cursor.
+type UserFilter struct {
+	EmailContains string
+	NameContains  string
+	MinScore      *int64
+	MaxScore      *int64
+	CreatedAfter  time.Time
+	Sort          UserSort
+	After         *UserCursor
+	Limit         int
+}
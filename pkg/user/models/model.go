@@ -1,3 +1,11 @@
 package models
 
-// User represents the user entity persisted in Postgres via GORM.
+// ScoreEvent is a score change consumed from the score_events Kafka topic. EventID, when
+// set, makes applying it idempotent.
+type ScoreEvent struct {
+	UserID   int64  `json:"user_id"`
+	NewScore int64  `json:"new_score"`
+	Change   int32  `json:"change"`
+	Type     string `json:"type,omitempty"`
+	EventID  string `json:"event_id,omitempty"`
+}
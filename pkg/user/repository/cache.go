@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	usermodels "github.com/emorenkov/scorehub/pkg/user/models"
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisLeaderboardCache caches leaderboard pages and user ranks in Redis.
+// Entries are namespaced by a generation counter, so invalidation is a single INCR
+// and stale keys simply expire.
+type RedisLeaderboardCache struct {
+	client *redis.Client
+	prefix string
+	ttl    time.Duration
+}
+
+// NewRedisLeaderboardCache returns nil when no client is configured; a nil cache is a no-op.
+func NewRedisLeaderboardCache(client *redis.Client, ttl time.Duration) *RedisLeaderboardCache {
+	if client == nil {
+		return nil
+	}
+	if ttl <= 0 {
+		ttl = 30 * time.Second
+	}
+	return &RedisLeaderboardCache{
+		client: client,
+		prefix: "leaderboard",
+		ttl:    ttl,
+	}
+}
+
+// noGeneration is returned when the generation could not be read; nothing is stored
+// under it.
+const noGeneration = -1
+
+func (c *RedisLeaderboardCache) GetLeaderboard(ctx context.Context, limit, offset int) (*usermodels.Leaderboard, int64, bool) {
+	var lb usermodels.Leaderboard
+	gen, ok := c.get(ctx, fmt.Sprintf("page:%d:%d", limit, offset), &lb)
+	if !ok {
+		return nil, gen, false
+	}
+	return &lb, gen, true
+}
+
+func (c *RedisLeaderboardCache) SetLeaderboard(ctx context.Context, gen int64, limit, offset int, lb *usermodels.Leaderboard) {
+	c.set(ctx, gen, fmt.Sprintf("page:%d:%d", limit, offset), lb)
+}
+
+func (c *RedisLeaderboardCache) GetRank(ctx context.Context, userID int64) (*usermodels.UserRank, int64, bool) {
+	var rank usermodels.UserRank
+	gen, ok := c.get(ctx, fmt.Sprintf("rank:%d", userID), &rank)
+	if !ok {
+		return nil, gen, false
+	}
+	return &rank, gen, true
+}
+
+func (c *RedisLeaderboardCache) SetRank(ctx context.Context, gen int64, rank *usermodels.UserRank) {
+	c.set(ctx, gen, fmt.Sprintf("rank:%d", rank.UserID), rank)
+}
+
+// Invalidate drops every cached page and rank by bumping the generation.
+func (c *RedisLeaderboardCache) Invalidate(ctx context.Context) error {
+	if c == nil {
+		return nil
+	}
+	return c.client.Incr(ctx, c.prefix+":gen").Err()
+}
+
+// get reads key of the current generation into dst and returns that generation.
+func (c *RedisLeaderboardCache) get(ctx context.Context, key string, dst any) (int64, bool) {
+	if c == nil {
+		return noGeneration, false
+	}
+	gen, err := c.client.Get(ctx, c.prefix+":gen").Int64()
+	if err != nil && err != redis.Nil {
+		return noGeneration, false
+	}
+	raw, err := c.client.Get(ctx, c.key(gen, key)).Bytes()
+	if err != nil {
+		return gen, false
+	}
+	return gen, json.Unmarshal(raw, dst) == nil
+}
+
+// set stores value under key of generation gen, as returned by get before the value was
+// computed.
+func (c *RedisLeaderboardCache) set(ctx context.Context, gen int64, key string, value any) {
+	if c == nil || gen == noGeneration {
+		return
+	}
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return
+	}
+	// Cache writes are best-effort; a miss only costs a database query.
+	_ = c.client.Set(ctx, c.key(gen, key), raw, c.ttl).Err()
+}
+
+func (c *RedisLeaderboardCache) key(gen int64, key string) string {
+	return fmt.Sprintf("%s:%d:%s", c.prefix, gen, key)
+}
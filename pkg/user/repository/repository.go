@@ -2,9 +2,13 @@ package repository
 
 import (
 	"context"
+	"strings"
+	"time"
 
 	"github.com/emorenkov/scorehub/pkg/common/models"
+	usermodels "github.com/emorenkov/scorehub/pkg/user/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type GormRepository struct {
@@ -27,6 +31,14 @@ func (r *GormRepository) GetByID(ctx context.Context, id int64) (*models.User, e
 	return &u, nil
 }
 
+func (r *GormRepository) GetByIDs(ctx context.Context, ids []int64) ([]models.User, error) {
+	var users []models.User
+	if err := r.db.WithContext(ctx).Where("id IN ? AND deleted = FALSE", ids).Order("id").Find(&users).Error; err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
 func (r *GormRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
 	var u models.User
 	if err := r.db.WithContext(ctx).Where("email = ? AND deleted = FALSE", email).First(&u).Error; err != nil {
@@ -35,9 +47,49 @@ func (r *GormRepository) GetByEmail(ctx context.Context, email string) (*models.
 	return &u, nil
 }
 
-func (r *GormRepository) List(ctx context.Context) ([]models.User, error) {
+// List returns up to f.Limit non-deleted users matching the filter, positioned after f.After
+// using keyset pagination on id or (created_at, id).
+func (r *GormRepository) List(ctx context.Context, f usermodels.UserFilter) ([]models.User, error) {
+	query := r.db.WithContext(ctx).Where("deleted = FALSE")
+
+	if f.EmailContains != "" {
+		query = query.Where("email ILIKE ?", likePattern(f.EmailContains))
+	}
+	if f.NameContains != "" {
+		query = query.Where("name ILIKE ?", likePattern(f.NameContains))
+	}
+	if f.MinScore != nil {
+		query = query.Where("score >= ?", *f.MinScore)
+	}
+	if f.MaxScore != nil {
+		query = query.Where("score <= ?", *f.MaxScore)
+	}
+	if !f.CreatedAfter.IsZero() {
+		query = query.Where("created_at > ?", f.CreatedAfter)
+	}
+
+	cmp, dir := ">", "ASC"
+	if f.Sort.Desc() {
+		cmp, dir = "<", "DESC"
+	}
+	switch f.Sort {
+	case usermodels.SortCreatedAtAsc, usermodels.SortCreatedAtDesc:
+		if f.After != nil {
+			query = query.Where("(created_at, id) "+cmp+" (?, ?)", f.After.CreatedAt, f.After.ID)
+		}
+		query = query.Order("created_at " + dir).Order("id " + dir)
+	default:
+		if f.After != nil {
+			query = query.Where("id "+cmp+" ?", f.After.ID)
+		}
+		query = query.Order("id " + dir)
+	}
+	if f.Limit > 0 {
+		query = query.Limit(f.Limit)
+	}
+
 	var users []models.User
-	if err := r.db.WithContext(ctx).Where("deleted = FALSE").Find(&users).Error; err != nil {
+	if err := query.Find(&users).Error; err != nil {
 		return nil, err
 	}
 	return users, nil
@@ -61,3 +113,125 @@ func (r *GormRepository) Delete(ctx context.Context, id int64) error {
 	}
 	return nil
 }
+
+// ApplyScore sets the user's score and appends a history row in a single transaction.
+// When eventID was already applied the existing history row is returned with duplicate set.
+func (r *GormRepository) ApplyScore(ctx context.Context, userID, newScore int64, change int32, eventID string) (*models.ScoreHistory, bool, error) {
+	var entry *models.ScoreHistory
+	var duplicate bool
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var u models.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("deleted = FALSE").
+			First(&u, userID).Error; err != nil {
+			return err
+		}
+
+		// Events for a user are serialized by the row lock above, so this check cannot race.
+		if eventID != "" {
+			var existing []models.ScoreHistory
+			if err := tx.Where("user_id = ? AND event_id = ?", userID, eventID).Limit(1).Find(&existing).Error; err != nil {
+				return err
+			}
+			if len(existing) > 0 {
+				entry = &existing[0]
+				duplicate = true
+				return nil
+			}
+		}
+
+		// Update writes the new score back into u, so keep the old one first.
+		oldScore := u.Score
+		if err := tx.Model(&u).Update("score", newScore).Error; err != nil {
+			return err
+		}
+
+		entry = &models.ScoreHistory{
+			UserID:   userID,
+			OldScore: oldScore,
+			NewScore: newScore,
+			Change:   change,
+			EventID:  eventID,
+		}
+		return tx.Create(entry).Error
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return entry, duplicate, nil
+}
+
+// ListScoreHistory returns the user's score history within [from, to], newest first.
+// Zero from/to values leave the corresponding bound open.
+func (r *GormRepository) ListScoreHistory(ctx context.Context, userID int64, from, to time.Time) ([]models.ScoreHistory, error) {
+	var history []models.ScoreHistory
+	query := r.db.WithContext(ctx).Where("user_id = ?", userID)
+	if !from.IsZero() {
+		query = query.Where("created_at >= ?", from)
+	}
+	if !to.IsZero() {
+		query = query.Where("created_at <= ?", to)
+	}
+	if err := query.Order("created_at DESC").Find(&history).Error; err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+// likePattern builds a case-insensitive substring pattern, escaping LIKE wildcards.
+func likePattern(v string) string {
+	v = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(v)
+	return "%" + v + "%"
+}
+
+// Leaderboard returns active users ordered by score descending with competition ranks,
+// together with the total number of active users.
+func (r *GormRepository) Leaderboard(ctx context.Context, limit, offset int) ([]usermodels.LeaderboardEntry, int64, error) {
+	var total int64
+	if err := r.db.WithContext(ctx).Model(&models.User{}).Where("deleted = FALSE").Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var entries []usermodels.LeaderboardEntry
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT RANK() OVER (ORDER BY score DESC) AS rank, id AS user_id, name, score
+		FROM users
+		WHERE deleted = FALSE
+		ORDER BY score DESC, id ASC
+		LIMIT ? OFFSET ?`, limit, offset).
+		Scan(&entries).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return entries, total, nil
+}
+
+// Rank computes the user's competition rank and how many active users score at or below them.
+func (r *GormRepository) Rank(ctx context.Context, userID int64) (*usermodels.UserRank, int64, error) {
+	var row struct {
+		Score      int64
+		Rank       int64
+		AtOrBelow  int64
+		TotalUsers int64
+	}
+	res := r.db.WithContext(ctx).Raw(`
+		SELECT u.score,
+		       (SELECT COUNT(*) FROM users WHERE deleted = FALSE AND score > u.score) + 1 AS rank,
+		       (SELECT COUNT(*) FROM users WHERE deleted = FALSE AND score <= u.score) AS at_or_below,
+		       (SELECT COUNT(*) FROM users WHERE deleted = FALSE) AS total_users
+		FROM users u
+		WHERE u.id = ? AND u.deleted = FALSE`, userID).
+		Scan(&row)
+	if res.Error != nil {
+		return nil, 0, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, 0, gorm.ErrRecordNotFound
+	}
+	return &usermodels.UserRank{
+		UserID:     userID,
+		Score:      row.Score,
+		Rank:       row.Rank,
+		TotalUsers: row.TotalUsers,
+	}, row.AtOrBelow, nil
+}
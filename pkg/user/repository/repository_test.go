@@ -0,0 +1,300 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/emorenkov/scorehub/pkg/common/db/migrate"
+	"github.com/emorenkov/scorehub/pkg/common/models"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// scriptConn is a database/sql connection that records the statements it runs and answers
+// queries on users and score_history from fixed rows, so the statements ApplyScore issues
+// can be checked without a database.
+type scriptConn struct {
+	mu      sync.Mutex
+	log     []string
+	user    []driver.Value // id, score; nil when the user does not exist
+	history []driver.Value // id, user_id, old_score, new_score, change, event_id; nil for none
+}
+
+func (c *scriptConn) Connect(context.Context) (driver.Conn, error) { return c, nil }
+func (c *scriptConn) Driver() driver.Driver                        { return nil }
+
+func (c *scriptConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("prepared statements are not supported")
+}
+func (c *scriptConn) Close() error { return nil }
+func (c *scriptConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *scriptConn) BeginTx(context.Context, driver.TxOptions) (driver.Tx, error) {
+	c.record("BEGIN")
+	return scriptTx{c}, nil
+}
+
+func (c *scriptConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.record(query, args...)
+	switch {
+	case strings.HasPrefix(query, `INSERT INTO "score_history"`):
+		return &scriptRows{cols: []string{"id"}, rows: [][]driver.Value{{int64(99)}}}, nil
+	case strings.Contains(query, `FROM "users"`) && c.user != nil:
+		return &scriptRows{cols: []string{"id", "score"}, rows: [][]driver.Value{c.user}}, nil
+	case strings.Contains(query, `FROM "score_history"`) && c.history != nil:
+		return &scriptRows{cols: []string{"id", "user_id", "old_score", "new_score", "change", "event_id"}, rows: [][]driver.Value{c.history}}, nil
+	}
+	return &scriptRows{cols: []string{"id"}}, nil
+}
+
+func (c *scriptConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.record(query, args...)
+	return driver.RowsAffected(1), nil
+}
+
+func (c *scriptConn) record(query string, args ...driver.NamedValue) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	vals := make([]string, len(args))
+	for i, a := range args {
+		vals[i] = fmt.Sprint(a.Value)
+	}
+	if len(vals) > 0 {
+		query += " " + fmt.Sprint(vals)
+	}
+	c.log = append(c.log, query)
+}
+
+type scriptTx struct{ c *scriptConn }
+
+func (t scriptTx) Commit() error   { t.c.record("COMMIT"); return nil }
+func (t scriptTx) Rollback() error { t.c.record("ROLLBACK"); return nil }
+
+type scriptRows struct {
+	cols []string
+	rows [][]driver.Value
+}
+
+func (r *scriptRows) Columns() []string { return r.cols }
+func (r *scriptRows) Close() error      { return nil }
+
+func (r *scriptRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func scriptDB(t *testing.T, c *scriptConn) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sql.OpenDB(c)}),
+		&gorm.Config{Logger: logger.Discard, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// expectStatements checks that log holds statements starting with want, in order.
+func expectStatements(t *testing.T, log []string, want ...string) {
+	t.Helper()
+	if len(log) != len(want) {
+		t.Fatalf("ran %d statements, want %d:\n%s", len(log), len(want), strings.Join(log, "\n"))
+	}
+	for i := range want {
+		if !strings.HasPrefix(log[i], want[i]) {
+			t.Fatalf("statement %d is %q, want %q:\n%s", i, log[i], want[i], strings.Join(log, "\n"))
+		}
+	}
+}
+
+func TestApplyScoreLocksUserAndRecordsHistory(t *testing.T) {
+	c := &scriptConn{user: []driver.Value{int64(7), int64(100)}}
+	entry, duplicate, err := NewGormRepository(scriptDB(t, c)).ApplyScore(context.Background(), 7, 120, 20, "ev-1")
+	if err != nil || duplicate {
+		t.Fatalf("got duplicate=%v, %v", duplicate, err)
+	}
+	if entry.ID != 99 || entry.OldScore != 100 || entry.NewScore != 120 || entry.Change != 20 || entry.EventID != "ev-1" {
+		t.Fatalf("entry %+v", entry)
+	}
+	expectStatements(t, c.log,
+		"BEGIN",
+		`SELECT * FROM "users" WHERE deleted = FALSE AND "users"."id" = $1 ORDER BY "users"."id" LIMIT 1 FOR UPDATE [7]`,
+		`SELECT * FROM "score_history" WHERE user_id = $1 AND event_id = $2 LIMIT 1 [7 ev-1]`,
+		`UPDATE "users" SET "score"=$1,"updated_at"=$2 WHERE "id" = $3 [120`,
+		`INSERT INTO "score_history" ("user_id","old_score","new_score","change","event_id","created_at") VALUES ($1,$2,$3,$4,$5,$6) RETURNING "id" [7 100 120 20 ev-1`,
+		"COMMIT",
+	)
+}
+
+func TestApplyScoreWithoutEventIDSkipsDuplicateCheck(t *testing.T) {
+	c := &scriptConn{user: []driver.Value{int64(7), int64(100)}}
+	if _, _, err := NewGormRepository(scriptDB(t, c)).ApplyScore(context.Background(), 7, 120, 20, ""); err != nil {
+		t.Fatal(err)
+	}
+	for _, q := range c.log {
+		if strings.HasPrefix(q, `SELECT * FROM "score_history"`) {
+			t.Fatalf("looked up history without an event id: %s", q)
+		}
+	}
+}
+
+func TestApplyScoreDuplicateEventID(t *testing.T) {
+	c := &scriptConn{
+		user:    []driver.Value{int64(7), int64(120)},
+		history: []driver.Value{int64(42), int64(7), int64(100), int64(120), int64(20), "ev-1"},
+	}
+	entry, duplicate, err := NewGormRepository(scriptDB(t, c)).ApplyScore(context.Background(), 7, 150, 30, "ev-1")
+	if err != nil || !duplicate {
+		t.Fatalf("got duplicate=%v, %v", duplicate, err)
+	}
+	if entry.ID != 42 || entry.NewScore != 120 {
+		t.Fatalf("entry %+v, want the recorded one", entry)
+	}
+	expectStatements(t, c.log, "BEGIN", `SELECT * FROM "users"`, `SELECT * FROM "score_history"`, "COMMIT")
+}
+
+func TestApplyScoreUnknownUser(t *testing.T) {
+	c := &scriptConn{}
+	_, _, err := NewGormRepository(scriptDB(t, c)).ApplyScore(context.Background(), 7, 120, 20, "ev-1")
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("got %v, want ErrRecordNotFound", err)
+	}
+	expectStatements(t, c.log, "BEGIN", `SELECT * FROM "users"`, "ROLLBACK")
+}
+
+// testDB connects to SCOREHUB_TEST_POSTGRES_DSN and applies the migrations, skipping the
+// test when it is unset.
+func testDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := os.Getenv("SCOREHUB_TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("SCOREHUB_TEST_POSTGRES_DSN is not set")
+	}
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: dsn, PreferSimpleProtocol: true}), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	m, err := migrate.New(sqlDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Up(context.Background(), 0); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func createTestUser(t *testing.T, db *gorm.DB, score int64) *models.User {
+	t.Helper()
+	u := &models.User{Name: "Anna", Email: fmt.Sprintf("anna-%d@example.com", time.Now().UnixNano()), Score: score}
+	if err := db.Create(u).Error; err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Delete(&models.User{}, u.ID) })
+	return u
+}
+
+func TestApplyScorePostgres(t *testing.T) {
+	db := testDB(t)
+	repo := NewGormRepository(db)
+	ctx := context.Background()
+	u := createTestUser(t, db, 100)
+
+	entry, duplicate, err := repo.ApplyScore(ctx, u.ID, 120, 20, "ev-1")
+	if err != nil || duplicate {
+		t.Fatalf("got duplicate=%v, %v", duplicate, err)
+	}
+	again, duplicate, err := repo.ApplyScore(ctx, u.ID, 150, 30, "ev-1")
+	if err != nil || !duplicate || again.ID != entry.ID {
+		t.Fatalf("replay: got %+v, duplicate=%v, %v", again, duplicate, err)
+	}
+	got, err := repo.GetByID(ctx, u.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Score != 120 {
+		t.Fatalf("score %d after the replay, want 120", got.Score)
+	}
+	history, err := repo.ListScoreHistory(ctx, u.ID, time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 1 || history[0].OldScore != 100 || history[0].NewScore != 120 {
+		t.Fatalf("history %+v", history)
+	}
+
+	if err := repo.Delete(ctx, u.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := repo.ApplyScore(ctx, u.ID, 130, 10, "ev-2"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("deleted user: got %v, want ErrRecordNotFound", err)
+	}
+}
+
+// TestApplyScoreSerializesConcurrentEvents applies events for one user concurrently: the
+// row lock makes each history row start from the previous row's score, and replays of the
+// same event_id record it once.
+func TestApplyScoreSerializesConcurrentEvents(t *testing.T) {
+	db := testDB(t)
+	repo := NewGormRepository(db)
+	ctx := context.Background()
+	u := createTestUser(t, db, 0)
+
+	const events = 10
+	var wg sync.WaitGroup
+	errs := make(chan error, 2*events)
+	for i := range events {
+		for range 2 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, _, err := repo.ApplyScore(ctx, u.ID, int64(i+1), 1, fmt.Sprintf("ev-%d", i))
+				errs <- err
+			}()
+		}
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	history, err := repo.ListScoreHistory(ctx, u.ID, time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != events {
+		t.Fatalf("recorded %d history rows, want %d", len(history), events)
+	}
+	// Newest first: each row's old score is the new score of the row applied before it.
+	for i := 0; i < len(history)-1; i++ {
+		if history[i].OldScore != history[i+1].NewScore {
+			t.Fatalf("row %d starts from %d, but the previous row set %d", history[i].ID, history[i].OldScore, history[i+1].NewScore)
+		}
+	}
+	if history[len(history)-1].OldScore != 0 {
+		t.Fatalf("first row starts from %d, want 0", history[len(history)-1].OldScore)
+	}
+}
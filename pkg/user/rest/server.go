@@ -2,104 +2,118 @@ package rest
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
-	"net/http"
 
-	"github.com/emorenkov/scorehub/pkg/common/middleware"
-	"github.com/emorenkov/scorehub/pkg/common/models"
+	"github.com/emorenkov/scorehub/pkg/common/apikeys"
+	"github.com/emorenkov/scorehub/pkg/common/auth"
+	"github.com/emorenkov/scorehub/pkg/common/dlq"
+	"github.com/emorenkov/scorehub/pkg/common/gateway"
+	"github.com/emorenkov/scorehub/pkg/common/health"
+	"github.com/emorenkov/scorehub/pkg/common/metrics"
+	"github.com/emorenkov/scorehub/pkg/common/ratelimit"
+	"github.com/emorenkov/scorehub/pkg/common/requestid"
+	"github.com/emorenkov/scorehub/pkg/common/tlsx"
+	"github.com/emorenkov/scorehub/pkg/common/tracing"
 	"github.com/emorenkov/scorehub/pkg/user/config"
-	"github.com/emorenkov/scorehub/pkg/user/service"
 	"github.com/labstack/echo/v4"
 	echoMiddleware "github.com/labstack/echo/v4/middleware"
-	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 )
 
 type Server struct {
 	cfg     *config.UserConfig
-	svc     service.User
+	gw      *gateway.Handler
+	dlq     *dlq.Handler
+	keys    *apikeys.Handler
+	authn   *auth.Authenticator
+	limiter *ratelimit.Limiter
+	checks  *health.Registry
+	tls     *tls.Config
 	log     *zap.Logger
 	e       *echo.Echo
-	redis   *redis.Client
-	limiter *middleware.RateLimiter
 }
 
-func NewServer(cfg *config.UserConfig, svc service.User, log *zap.Logger) (*Server, error) {
-	if cfg.APIKey == "" {
-		return nil, errors.New("API_KEY must be set")
+// Deps are the collaborators of the REST server. Only Authn is required: user data is
+// never served unauthenticated.
+type Deps struct {
+	Config *config.UserConfig
+	// Gateway serves the UserService RPCs, which the gRPC server authenticates and rate
+	// limits; the Echo routes are the ones without an RPC.
+	Gateway *gateway.Handler
+	// DeadLetters and Keys mount the dead-letter and API key admin routes.
+	DeadLetters *dlq.Handler
+	Keys        *apikeys.Handler
+	Authn       *auth.Authenticator
+	Limiter     *ratelimit.Limiter
+	// Checks backs /livez and /readyz.
+	Checks *health.Registry
+	// TLS enables HTTPS.
+	TLS *tls.Config
+	Log *zap.Logger
+}
+
+// NewServer builds the REST server from d.
+func NewServer(d Deps) (*Server, error) {
+	if !d.Authn.Enabled() {
+		return nil, errors.New("API_KEY or JWT verification must be configured")
 	}
 
 	e := echo.New()
 	e.HideBanner = true
 	e.HidePort = true
-
-	redisClient := newRedisClient(cfg.RedisConfig)
-	limiter := middleware.NewRateLimiter(redisClient, cfg.RateLimitBurst)
+	e.IPExtractor = echo.ExtractIPDirect()
 
 	s := &Server{
-		cfg:     cfg,
-		svc:     svc,
-		log:     log,
+		cfg:     d.Config,
+		gw:      d.Gateway,
+		dlq:     d.DeadLetters,
+		keys:    d.Keys,
+		authn:   d.Authn,
+		limiter: d.Limiter,
+		checks:  d.Checks,
+		tls:     d.TLS,
+		log:     d.Log,
 		e:       e,
-		redis:   redisClient,
-		limiter: limiter,
 	}
 
 	e.Use(echoMiddleware.Recover())
-	if limiter != nil {
-		e.Use(middleware.EchoRateLimit(limiter))
-	}
+	e.Use(requestid.EchoMiddleware())
+	e.Use(tracing.EchoMiddleware())
+	e.Use(metrics.EchoMiddleware())
 
 	s.registerRoutes()
 	return s, nil
 }
 
 func (s *Server) registerRoutes() {
-	s.e.GET("/_health", func(c echo.Context) error {
-		return c.NoContent(http.StatusOK)
-	})
+	metrics.Register(s.e)
+	if s.checks != nil {
+		s.checks.Register(s.e)
+	}
 
-	api := s.e.Group("/api/v1")
-	api.POST("/users", s.createUser)
+	if s.gw != nil {
+		s.gw.Register(s.e)
+	}
 
-	protected := api.Group("", s.keyAuthMiddleware)
-	protected.GET("/users/:id", s.getUser)
-	protected.GET("/users", s.listUsers)
-	protected.PUT("/users/:id", s.updateUser)
-	protected.DELETE("/users/:id", s.deleteUser)
+	admin := s.e.Group(gateway.Prefix,
+		s.limiter.EchoMiddleware(ratelimit.BeforeAuth), s.authn.EchoMiddleware(), s.limiter.EchoMiddleware(ratelimit.AfterAuth),
+		auth.Scopes(auth.ScopeAdmin))
+	if s.dlq != nil {
+		s.dlq.Register(admin)
+	}
+	if s.keys != nil {
+		s.keys.Register(admin)
+	}
 }
 
 func (s *Server) Serve() error {
 	addr := ":" + s.cfg.HTTPPort
-	s.log.Info("starting REST server", zap.String("addr", addr))
-	return s.e.Start(addr)
+	s.log.Info("starting REST server", zap.String("addr", addr), zap.Bool("tls", s.tls != nil))
+	return tlsx.StartEcho(s.e, addr, s.tls)
 }
 
 func (s *Server) Shutdown(ctx context.Context) error {
 	s.log.Info("shutting down REST server")
-	if s.redis != nil {
-		_ = s.redis.Close()
-	}
 	return s.e.Shutdown(ctx)
 }
-
-func (s *Server) keyAuthMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
-	return func(c echo.Context) error {
-		apiKey := c.Request().Header.Get("X-API-Key")
-		if apiKey == "" || apiKey != s.cfg.APIKey {
-			return c.NoContent(http.StatusUnauthorized)
-		}
-		return next(c)
-	}
-}
-
-func newRedisClient(cfg *models.RedisConfig) *redis.Client {
-	if cfg.RedisAddr == "" {
-		return nil
-	}
-	return redis.NewClient(&redis.Options{
-		Addr:     cfg.RedisAddr,
-		Password: cfg.RedisPassword,
-		DB:       cfg.RedisDB,
-	})
-}
@@ -0,0 +1,126 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/emorenkov/scorehub/pkg/common/auth"
+	apperrors "github.com/emorenkov/scorehub/pkg/common/errors"
+	"github.com/emorenkov/scorehub/pkg/common/gateway"
+	"github.com/emorenkov/scorehub/pkg/common/grpcx"
+	"github.com/emorenkov/scorehub/pkg/common/models"
+	"github.com/emorenkov/scorehub/pkg/user/config"
+	grpcserver "github.com/emorenkov/scorehub/pkg/user/grpc"
+	userpb "github.com/emorenkov/scorehub/pkg/user/models/proto"
+	"github.com/emorenkov/scorehub/pkg/user/service"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+const testAPIKey = "secret"
+
+// userService knows a single user, 7.
+type userService struct {
+	service.User
+}
+
+func (userService) Get(_ context.Context, id int64) (*models.User, error) {
+	if id != 7 {
+		return nil, apperrors.NewStatusError(http.StatusNotFound, "user not found")
+	}
+	return &models.User{ID: 7, Name: "Anna", Email: "anna@example.com"}, nil
+}
+
+// newTestServer serves the gateway against a real gRPC server with the production
+// interceptor chain and reports the metrics route of the last request through route.
+func newTestServer(t *testing.T) (*Server, *string) {
+	t.Helper()
+	authn := auth.NewAuthenticator(testAPIKey, nil, nil)
+	gs := grpcx.NewServer(grpcx.ServerConfig{Auth: grpcx.Auth(authn), Scopes: grpcserver.MethodScopes})
+	userpb.RegisterUserServiceServer(gs, grpcserver.NewServer(userService{}))
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	go func() { _ = gs.Serve(lis) }()
+	t.Cleanup(gs.Stop)
+
+	gw, err := gateway.New(gateway.Config{GRPCAddr: lis.Addr().String()}, userpb.RegisterUserServiceHandlerFromEndpoint)
+	if err != nil {
+		t.Fatalf("gateway: %v", err)
+	}
+	t.Cleanup(gw.Close)
+
+	srv, err := NewServer(Deps{Config: &config.UserConfig{}, Gateway: gw, Authn: authn, Log: zap.NewNop()})
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	route := new(string)
+	srv.e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			err := next(c)
+			*route = c.Path()
+			return err
+		}
+	})
+	return srv, route
+}
+
+func get(srv *Server, target, apiKey string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	if apiKey != "" {
+		req.Header.Set(auth.APIKeyHeader, apiKey)
+	}
+	rec := httptest.NewRecorder()
+	srv.e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestGatewayRequiresCredentials(t *testing.T) {
+	srv, _ := newTestServer(t)
+	for _, key := range []string{"", "wrong"} {
+		if rec := get(srv, "/api/v1/users/7", key); rec.Code != http.StatusUnauthorized {
+			t.Errorf("api key %q: status = %d, want 401 (%s)", key, rec.Code, rec.Body)
+		}
+	}
+}
+
+func TestGatewayServesRPCs(t *testing.T) {
+	srv, route := newTestServer(t)
+	rec := get(srv, "/api/v1/users/7", testAPIKey)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200 (%s)", rec.Code, rec.Body)
+	}
+	var resp struct {
+		User struct {
+			ID    string `json:"id"`
+			Email string `json:"email"`
+		} `json:"user"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode %s: %v", rec.Body, err)
+	}
+	if resp.User.ID != "7" || resp.User.Email != "anna@example.com" {
+		t.Fatalf("unexpected body %s", rec.Body)
+	}
+	if *route != "/api/v1/users/{id}" {
+		t.Fatalf("route = %q, want the RPC binding", *route)
+	}
+	if rec.Header().Get("X-Request-ID") == "" {
+		t.Fatal("response has no X-Request-ID")
+	}
+}
+
+func TestGatewayMapsServiceErrors(t *testing.T) {
+	srv, _ := newTestServer(t)
+	if rec := get(srv, "/api/v1/users/8", testAPIKey); rec.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want 404 (%s)", rec.Code, rec.Body)
+	}
+	if rec := get(srv, "/api/v1/nope", testAPIKey); rec.Code != http.StatusNotFound {
+		t.Fatalf("unknown route: status = %d, want 404 (%s)", rec.Code, rec.Body)
+	}
+}
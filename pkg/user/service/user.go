@@ -1,13 +1,20 @@
 package service
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
+	"math"
 	"net/http"
 	"strings"
+	"time"
 
+	"github.com/emorenkov/scorehub/pkg/common/auth"
 	apperrors "github.com/emorenkov/scorehub/pkg/common/errors"
 	"github.com/emorenkov/scorehub/pkg/common/models"
+	usermodels "github.com/emorenkov/scorehub/pkg/user/models"
 	"gorm.io/gorm"
 )
 
@@ -15,26 +22,65 @@ import (
 type User interface {
 	Create(ctx context.Context, name, email string) (*models.User, error)
 	Get(ctx context.Context, id int64) (*models.User, error)
-	List(ctx context.Context) ([]models.User, error)
-	Update(ctx context.Context, id int64, name, email string) (*models.User, error)
+	// GetMany returns the existing users among ids; unknown or deleted IDs are omitted.
+	GetMany(ctx context.Context, ids []int64) ([]models.User, error)
+	// List returns one page of users and the token for the next page (empty on the last page).
+	List(ctx context.Context, q *usermodels.ListUsersQuery) ([]models.User, string, error)
+	// Update changes the non-empty fields and, when prefs is not nil, the preferences.
+	Update(ctx context.Context, id int64, name, email string, prefs *models.UserPreferences) (*models.User, error)
 	Delete(ctx context.Context, id int64) error
+	ApplyScoreEvent(ctx context.Context, ev *usermodels.ScoreEvent) (*models.ScoreHistory, error)
+	GetScoreHistory(ctx context.Context, userID int64, from, to time.Time) ([]models.ScoreHistory, error)
+	GetLeaderboard(ctx context.Context, limit, offset int) (*usermodels.Leaderboard, error)
+	GetUserRank(ctx context.Context, userID int64) (*usermodels.UserRank, error)
 }
 
 type Repository interface {
 	Create(ctx context.Context, u *models.User) error
 	GetByID(ctx context.Context, id int64) (*models.User, error)
+	GetByIDs(ctx context.Context, ids []int64) ([]models.User, error)
 	GetByEmail(ctx context.Context, email string) (*models.User, error)
-	List(ctx context.Context) ([]models.User, error)
+	List(ctx context.Context, f usermodels.UserFilter) ([]models.User, error)
 	Update(ctx context.Context, u *models.User) error
 	Delete(ctx context.Context, id int64) error
+	ApplyScore(ctx context.Context, userID, newScore int64, change int32, eventID string) (*models.ScoreHistory, bool, error)
+	ListScoreHistory(ctx context.Context, userID int64, from, to time.Time) ([]models.ScoreHistory, error)
+	Leaderboard(ctx context.Context, limit, offset int) ([]usermodels.LeaderboardEntry, int64, error)
+	Rank(ctx context.Context, userID int64) (*usermodels.UserRank, int64, error)
 }
 
+// LeaderboardCache stores computed leaderboard pages and ranks. Implementations are
+// best-effort: misses fall back to the repository.
+//
+// On a miss the getters return the cache generation, read before the repository is
+// queried, and the setters store under that generation. A value computed while
+// Invalidate runs is then stored under the old generation and never served.
+type LeaderboardCache interface {
+	GetLeaderboard(ctx context.Context, limit, offset int) (*usermodels.Leaderboard, int64, bool)
+	SetLeaderboard(ctx context.Context, gen int64, limit, offset int, lb *usermodels.Leaderboard)
+	GetRank(ctx context.Context, userID int64) (*usermodels.UserRank, int64, bool)
+	SetRank(ctx context.Context, gen int64, rank *usermodels.UserRank)
+	Invalidate(ctx context.Context) error
+}
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+
+	defaultLeaderboardLimit = 10
+	maxLeaderboardLimit     = 100
+
+	maxBatchGetUsers = 1000
+)
+
 type user struct {
-	repo Repository
+	repo  Repository
+	cache LeaderboardCache
 }
 
-func NewService(repo Repository) User {
-	return &user{repo: repo}
+// NewService constructs the user service; cache may be nil to disable leaderboard caching.
+func NewService(repo Repository, cache LeaderboardCache) User {
+	return &user{repo: repo, cache: cache}
 }
 
 func (s *user) Create(ctx context.Context, name, email string) (*models.User, error) {
@@ -54,10 +100,14 @@ func (s *user) Create(ctx context.Context, name, email string) (*models.User, er
 	if err := s.repo.Create(ctx, u); err != nil {
 		return nil, apperrors.WrapStatus(err, http.StatusInternalServerError, "create user")
 	}
+	s.invalidateRanks(ctx)
 	return u, nil
 }
 
 func (s *user) Get(ctx context.Context, id int64) (*models.User, error) {
+	if err := auth.AuthorizeUser(ctx, id); err != nil {
+		return nil, err
+	}
 	u, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -68,15 +118,82 @@ func (s *user) Get(ctx context.Context, id int64) (*models.User, error) {
 	return u, nil
 }
 
-func (s *user) List(ctx context.Context) ([]models.User, error) {
-	users, err := s.repo.List(ctx)
+func (s *user) GetMany(ctx context.Context, ids []int64) ([]models.User, error) {
+	if err := auth.AuthorizeAllUsers(ctx); err != nil {
+		return nil, err
+	}
+	if len(ids) > maxBatchGetUsers {
+		return nil, apperrors.NewStatusError(http.StatusBadRequest, "too many ids")
+	}
+	if len(ids) == 0 {
+		return []models.User{}, nil
+	}
+	users, err := s.repo.GetByIDs(ctx, ids)
 	if err != nil {
-		return nil, apperrors.WrapStatus(err, http.StatusInternalServerError, "list users")
+		return nil, apperrors.WrapStatus(err, http.StatusInternalServerError, "get users")
 	}
 	return users, nil
 }
 
-func (s *user) Update(ctx context.Context, id int64, name, email string) (*models.User, error) {
+func (s *user) List(ctx context.Context, q *usermodels.ListUsersQuery) ([]models.User, string, error) {
+	if err := auth.AuthorizeAllUsers(ctx); err != nil {
+		return nil, "", err
+	}
+	if q == nil {
+		q = &usermodels.ListUsersQuery{}
+	}
+	sort, ok := usermodels.ParseUserSort(string(q.Sort))
+	if !ok {
+		return nil, "", apperrors.NewStatusError(http.StatusBadRequest, "invalid sort")
+	}
+	pageSize := q.PageSize
+	switch {
+	case pageSize < 0:
+		return nil, "", apperrors.NewStatusError(http.StatusBadRequest, "page_size must be non-negative")
+	case pageSize == 0:
+		pageSize = defaultPageSize
+	case pageSize > maxPageSize:
+		pageSize = maxPageSize
+	}
+	if q.MinScore != nil && q.MaxScore != nil && *q.MinScore > *q.MaxScore {
+		return nil, "", apperrors.NewStatusError(http.StatusBadRequest, "min_score must not exceed max_score")
+	}
+
+	filter := usermodels.UserFilter{
+		EmailContains: strings.TrimSpace(q.EmailContains),
+		NameContains:  strings.TrimSpace(q.NameContains),
+		MinScore:      q.MinScore,
+		MaxScore:      q.MaxScore,
+		CreatedAfter:  q.CreatedAfter,
+		Sort:          sort,
+		Limit:         pageSize + 1,
+	}
+	if q.PageToken != "" {
+		cursor, err := decodePageToken(q.PageToken)
+		if err != nil || cursor.Sort != sort {
+			return nil, "", apperrors.NewStatusError(http.StatusBadRequest, "invalid page_token")
+		}
+		filter.After = cursor
+	}
+
+	users, err := s.repo.List(ctx, filter)
+	if err != nil {
+		return nil, "", apperrors.WrapStatus(err, http.StatusInternalServerError, "list users")
+	}
+
+	var next string
+	if len(users) > pageSize {
+		users = users[:pageSize]
+		last := users[len(users)-1]
+		next = encodePageToken(&usermodels.UserCursor{ID: last.ID, CreatedAt: last.CreatedAt, Sort: sort})
+	}
+	return users, next, nil
+}
+
+func (s *user) Update(ctx context.Context, id int64, name, email string, prefs *models.UserPreferences) (*models.User, error) {
+	if err := auth.AuthorizeUser(ctx, id); err != nil {
+		return nil, err
+	}
 	u, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -84,7 +201,10 @@ func (s *user) Update(ctx context.Context, id int64, name, email string) (*model
 		}
 		return nil, apperrors.WrapStatus(err, http.StatusInternalServerError, "get user")
 	}
+	// Leaderboard pages show names, so a name change invalidates them like a score change.
+	profileChanged := false
 	if name = strings.TrimSpace(name); name != "" {
+		profileChanged = name != u.Name
 		u.Name = name
 	}
 	if email = strings.TrimSpace(strings.ToLower(email)); email != "" {
@@ -97,18 +217,28 @@ func (s *user) Update(ctx context.Context, id int64, name, email string) (*model
 				return nil, apperrors.WrapStatus(err, http.StatusInternalServerError, "check user email uniqueness")
 			}
 		}
+		profileChanged = profileChanged || email != u.Email
 		u.Email = email
 	}
+	if prefs != nil {
+		u.Preferences = *prefs
+	}
 	if err := s.repo.Update(ctx, u); err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, apperrors.NewStatusError(http.StatusNotFound, "user not found")
 		}
 		return nil, apperrors.WrapStatus(err, http.StatusInternalServerError, "update user")
 	}
+	if profileChanged {
+		s.invalidateRanks(ctx)
+	}
 	return u, nil
 }
 
 func (s *user) Delete(ctx context.Context, id int64) error {
+	if err := auth.AuthorizeUser(ctx, id); err != nil {
+		return err
+	}
 	err := s.repo.Delete(ctx, id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -116,5 +246,146 @@ func (s *user) Delete(ctx context.Context, id int64) error {
 		}
 		return apperrors.WrapStatus(err, http.StatusInternalServerError, "delete user")
 	}
+	s.invalidateRanks(ctx)
 	return nil
 }
+
+func (s *user) ApplyScoreEvent(ctx context.Context, ev *usermodels.ScoreEvent) (*models.ScoreHistory, error) {
+	if ev == nil {
+		return nil, apperrors.NewStatusError(http.StatusBadRequest, "event is required")
+	}
+	if ev.UserID <= 0 {
+		return nil, apperrors.NewStatusError(http.StatusBadRequest, "user_id must be positive")
+	}
+	if ev.NewScore < 0 {
+		return nil, apperrors.NewStatusError(http.StatusBadRequest, "new_score must be non-negative")
+	}
+	entry, duplicate, err := s.repo.ApplyScore(ctx, ev.UserID, ev.NewScore, ev.Change, ev.EventID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NewStatusError(http.StatusNotFound, "user not found")
+		}
+		return nil, apperrors.WrapStatus(err, http.StatusInternalServerError, "apply score event")
+	}
+	if duplicate {
+		return entry, nil
+	}
+	s.invalidateRanks(ctx)
+	return entry, nil
+}
+
+func (s *user) GetScoreHistory(ctx context.Context, userID int64, from, to time.Time) ([]models.ScoreHistory, error) {
+	if !from.IsZero() && !to.IsZero() && from.After(to) {
+		return nil, apperrors.NewStatusError(http.StatusBadRequest, "from must not be after to")
+	}
+	if _, err := s.Get(ctx, userID); err != nil {
+		return nil, err
+	}
+	history, err := s.repo.ListScoreHistory(ctx, userID, from, to)
+	if err != nil {
+		return nil, apperrors.WrapStatus(err, http.StatusInternalServerError, "list score history")
+	}
+	return history, nil
+}
+
+func (s *user) GetLeaderboard(ctx context.Context, limit, offset int) (*usermodels.Leaderboard, error) {
+	if limit < 0 || offset < 0 {
+		return nil, apperrors.NewStatusError(http.StatusBadRequest, "limit and offset must be non-negative")
+	}
+	if limit == 0 {
+		limit = defaultLeaderboardLimit
+	}
+	if limit > maxLeaderboardLimit {
+		limit = maxLeaderboardLimit
+	}
+
+	var gen int64
+	if s.cache != nil {
+		lb, g, ok := s.cache.GetLeaderboard(ctx, limit, offset)
+		if ok {
+			return lb, nil
+		}
+		gen = g
+	}
+
+	entries, total, err := s.repo.Leaderboard(ctx, limit, offset)
+	if err != nil {
+		return nil, apperrors.WrapStatus(err, http.StatusInternalServerError, "get leaderboard")
+	}
+	for i := range entries {
+		entries[i].Band = models.ScoreBand(entries[i].Score)
+	}
+	lb := &usermodels.Leaderboard{Entries: entries, TotalUsers: total}
+	if s.cache != nil {
+		s.cache.SetLeaderboard(ctx, gen, limit, offset, lb)
+	}
+	return lb, nil
+}
+
+func (s *user) GetUserRank(ctx context.Context, userID int64) (*usermodels.UserRank, error) {
+	if userID <= 0 {
+		return nil, apperrors.NewStatusError(http.StatusBadRequest, "invalid id")
+	}
+	if err := auth.AuthorizeUser(ctx, userID); err != nil {
+		return nil, err
+	}
+	var gen int64
+	if s.cache != nil {
+		rank, g, ok := s.cache.GetRank(ctx, userID)
+		if ok {
+			return rank, nil
+		}
+		gen = g
+	}
+
+	rank, atOrBelow, err := s.repo.Rank(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperrors.NewStatusError(http.StatusNotFound, "user not found")
+		}
+		return nil, apperrors.WrapStatus(err, http.StatusInternalServerError, "get user rank")
+	}
+	if rank.TotalUsers > 0 {
+		rank.Percentile = math.Round(float64(atOrBelow)/float64(rank.TotalUsers)*10000) / 100
+	}
+	rank.Band = models.ScoreBand(rank.Score)
+	if s.cache != nil {
+		s.cache.SetRank(ctx, gen, rank)
+	}
+	return rank, nil
+}
+
+// invalidateRanks drops cached leaderboard data after any change that can move ranks or
+// alter what the cached entries show.
+func (s *user) invalidateRanks(ctx context.Context) {
+	if s.cache == nil {
+		return
+	}
+	// Cached entries expire on their own if Redis is unavailable right now.
+	_ = s.cache.Invalidate(ctx)
+}
+
+// encodePageToken serialises a cursor into an opaque, URL-safe page token.
+func encodePageToken(c *usermodels.UserCursor) string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// decodePageToken parses a token from encodePageToken, rejecting tokens that
+// encodePageToken cannot have produced.
+func decodePageToken(token string) (*usermodels.UserCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	var c usermodels.UserCursor
+	if err := dec.Decode(&c); err != nil {
+		return nil, err
+	}
+	if c.ID <= 0 || c.CreatedAt.IsZero() || c.Sort == "" || dec.More() {
+		return nil, errors.New("malformed page token")
+	}
+	return &c, nil
+}
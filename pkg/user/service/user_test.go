@@ -0,0 +1,274 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	apperrors "github.com/emorenkov/scorehub/pkg/common/errors"
+	"github.com/emorenkov/scorehub/pkg/common/models"
+	usermodels "github.com/emorenkov/scorehub/pkg/user/models"
+	"gorm.io/gorm"
+)
+
+type repo struct {
+	Repository
+	users map[int64]*models.User
+	// onLeaderboard runs after the leaderboard is read, before it is returned.
+	onLeaderboard func()
+}
+
+func (r *repo) GetByID(_ context.Context, id int64) (*models.User, error) {
+	u, ok := r.users[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	cp := *u
+	return &cp, nil
+}
+
+func (r *repo) GetByEmail(_ context.Context, email string) (*models.User, error) {
+	for _, u := range r.users {
+		if u.Email == email {
+			cp := *u
+			return &cp, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *repo) Update(_ context.Context, u *models.User) error {
+	cp := *u
+	r.users[u.ID] = &cp
+	return nil
+}
+
+func (r *repo) Leaderboard(context.Context, int, int) ([]usermodels.LeaderboardEntry, int64, error) {
+	var entries []usermodels.LeaderboardEntry
+	for _, u := range r.users {
+		entries = append(entries, usermodels.LeaderboardEntry{Rank: 1, UserID: u.ID, Name: u.Name, Score: u.Score})
+	}
+	if r.onLeaderboard != nil {
+		r.onLeaderboard()
+	}
+	return entries, int64(len(entries)), nil
+}
+
+// List returns users in ID order after f.After, ignoring the other filters.
+func (r *repo) List(_ context.Context, f usermodels.UserFilter) ([]models.User, error) {
+	var out []models.User
+	for id := int64(1); id <= int64(len(r.users)); id++ {
+		if u, ok := r.users[id]; ok && (f.After == nil || id > f.After.ID) && len(out) < f.Limit {
+			out = append(out, *u)
+		}
+	}
+	return out, nil
+}
+
+// memoryCache mimics the generation-keyed Redis cache.
+type memoryCache struct {
+	gen     int64
+	entries map[string]*usermodels.Leaderboard
+}
+
+func (c *memoryCache) key(gen int64, limit, offset int) string {
+	return fmt.Sprintf("%d:%d:%d", gen, limit, offset)
+}
+
+func (c *memoryCache) GetLeaderboard(_ context.Context, limit, offset int) (*usermodels.Leaderboard, int64, bool) {
+	lb, ok := c.entries[c.key(c.gen, limit, offset)]
+	return lb, c.gen, ok
+}
+
+func (c *memoryCache) SetLeaderboard(_ context.Context, gen int64, limit, offset int, lb *usermodels.Leaderboard) {
+	c.entries[c.key(gen, limit, offset)] = lb
+}
+
+func (c *memoryCache) GetRank(context.Context, int64) (*usermodels.UserRank, int64, bool) {
+	return nil, c.gen, false
+}
+
+func (c *memoryCache) SetRank(context.Context, int64, *usermodels.UserRank) {}
+
+func (c *memoryCache) Invalidate(context.Context) error {
+	c.gen++
+	return nil
+}
+
+func newTestService() (*user, *repo) {
+	r := &repo{users: map[int64]*models.User{1: {ID: 1, Name: "Anna", Email: "anna@example.com", Score: 700}}}
+	return NewService(r, &memoryCache{entries: map[string]*usermodels.Leaderboard{}}).(*user), r
+}
+
+func TestUpdateInvalidatesLeaderboardOnRename(t *testing.T) {
+	svc, _ := newTestService()
+	ctx := context.Background()
+	if _, err := svc.GetLeaderboard(ctx, 10, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.Update(ctx, 1, "Anne", "", nil); err != nil {
+		t.Fatal(err)
+	}
+	lb, err := svc.GetLeaderboard(ctx, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := lb.Entries[0].Name; got != "Anne" {
+		t.Fatalf("leaderboard shows %q after the rename", got)
+	}
+}
+
+func TestLeaderboardComputedDuringInvalidationIsNotServed(t *testing.T) {
+	svc, r := newTestService()
+	ctx := context.Background()
+	// A rename lands after the page was read from the old row but before it is cached.
+	r.onLeaderboard = func() {
+		r.onLeaderboard = nil
+		r.users[1].Name = "Anne"
+		_ = svc.cache.Invalidate(ctx)
+	}
+	if _, err := svc.GetLeaderboard(ctx, 10, 0); err != nil {
+		t.Fatal(err)
+	}
+	lb, err := svc.GetLeaderboard(ctx, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := lb.Entries[0].Name; got != "Anne" {
+		t.Fatalf("served the page computed before the invalidation: %q", got)
+	}
+}
+
+func TestPageTokenRoundTrip(t *testing.T) {
+	want := &usermodels.UserCursor{ID: 9, CreatedAt: time.Date(2026, 3, 1, 12, 0, 0, 5, time.UTC), Sort: usermodels.SortCreatedAtDesc}
+	got, err := decodePageToken(encodePageToken(want))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != want.ID || !got.CreatedAt.Equal(want.CreatedAt) || got.Sort != want.Sort {
+		t.Fatalf("decoded %+v, want %+v", got, want)
+	}
+}
+
+func TestDecodePageTokenRejectsTampering(t *testing.T) {
+	encode := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+	for name, token := range map[string]string{
+		"not base64":    "%%%",
+		"not json":      encode("id=9"),
+		"unknown field": encode(`{"id":9,"created_at":"2026-03-01T12:00:00Z","sort":"id_asc","score":100}`),
+		"zero id":       encode(`{"id":0,"created_at":"2026-03-01T12:00:00Z","sort":"id_asc"}`),
+		"no created_at": encode(`{"id":9,"sort":"id_asc"}`),
+		"no sort":       encode(`{"id":9,"created_at":"2026-03-01T12:00:00Z"}`),
+		"trailing":      encode(`{"id":9,"created_at":"2026-03-01T12:00:00Z","sort":"id_asc"}[]`),
+	} {
+		if c, err := decodePageToken(token); err == nil {
+			t.Errorf("%s: decoded %+v", name, c)
+		}
+	}
+}
+
+func TestListPagesWithToken(t *testing.T) {
+	r := &repo{users: map[int64]*models.User{}}
+	for id := int64(1); id <= 5; id++ {
+		r.users[id] = &models.User{ID: id, CreatedAt: time.Now()}
+	}
+	svc := NewService(r, nil)
+	ctx := context.Background()
+
+	var ids []int64
+	token := ""
+	for range 3 {
+		page, next, err := svc.List(ctx, &usermodels.ListUsersQuery{PageSize: 2, PageToken: token})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, u := range page {
+			ids = append(ids, u.ID)
+		}
+		if token = next; token == "" {
+			break
+		}
+	}
+	if fmt.Sprint(ids) != "[1 2 3 4 5]" || token != "" {
+		t.Fatalf("paged through %v, final token %q", ids, token)
+	}
+
+	_, next, err := svc.List(ctx, &usermodels.ListUsersQuery{PageSize: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, q := range map[string]*usermodels.ListUsersQuery{
+		"other sort": {PageSize: 2, PageToken: next, Sort: usermodels.SortIDDesc},
+		"tampered":   {PageSize: 2, PageToken: base64.RawURLEncoding.EncodeToString([]byte(`{"id":-1}`))},
+	} {
+		_, _, err := svc.List(ctx, q)
+		if se, ok := apperrors.AsStatusError(err); !ok || se.Status != http.StatusBadRequest {
+			t.Errorf("%s: err = %v, want 400", name, err)
+		}
+	}
+}
+
+// scoreRepo records ApplyScore calls and answers them with a fixed result.
+type scoreRepo struct {
+	repo
+	calls     int
+	duplicate bool
+	err       error
+}
+
+func (r *scoreRepo) ApplyScore(_ context.Context, userID, newScore int64, change int32, eventID string) (*models.ScoreHistory, bool, error) {
+	r.calls++
+	if r.err != nil {
+		return nil, false, r.err
+	}
+	return &models.ScoreHistory{ID: 1, UserID: userID, NewScore: newScore, Change: change, EventID: eventID}, r.duplicate, nil
+}
+
+func TestApplyScoreEvent(t *testing.T) {
+	ev := &usermodels.ScoreEvent{UserID: 1, NewScore: 720, Change: 20, EventID: "ev-1"}
+	for name, tc := range map[string]struct {
+		repo        *scoreRepo
+		status      int
+		invalidated bool
+	}{
+		"applied":      {&scoreRepo{}, 0, true},
+		"duplicate":    {&scoreRepo{duplicate: true}, 0, false},
+		"unknown user": {&scoreRepo{err: gorm.ErrRecordNotFound}, http.StatusNotFound, false},
+		"db error":     {&scoreRepo{err: errors.New("connection reset")}, http.StatusInternalServerError, false},
+	} {
+		cache := &memoryCache{entries: map[string]*usermodels.Leaderboard{}}
+		entry, err := NewService(tc.repo, cache).ApplyScoreEvent(context.Background(), ev)
+		if tc.status != 0 {
+			if se, ok := apperrors.AsStatusError(err); !ok || se.Status != tc.status {
+				t.Errorf("%s: got %v, want status %d", name, err, tc.status)
+			}
+		} else if err != nil || entry.NewScore != 720 || entry.EventID != "ev-1" {
+			t.Errorf("%s: got %+v, %v", name, entry, err)
+		}
+		if invalidated := cache.gen > 0; invalidated != tc.invalidated {
+			t.Errorf("%s: leaderboard invalidated = %v, want %v", name, invalidated, tc.invalidated)
+		}
+	}
+}
+
+func TestApplyScoreEventValidates(t *testing.T) {
+	for name, ev := range map[string]*usermodels.ScoreEvent{
+		"nil":            nil,
+		"no user":        {NewScore: 10},
+		"negative user":  {UserID: -1, NewScore: 10},
+		"negative score": {UserID: 1, NewScore: -1},
+	} {
+		r := &scoreRepo{}
+		_, err := NewService(r, nil).ApplyScoreEvent(context.Background(), ev)
+		if se, ok := apperrors.AsStatusError(err); !ok || se.Status != http.StatusBadRequest {
+			t.Errorf("%s: got %v, want 400", name, err)
+		}
+		if r.calls != 0 {
+			t.Errorf("%s: applied the event", name)
+		}
+	}
+}